@@ -10,9 +10,11 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/Improwised/jovvix/api/config"
+	"github.com/Improwised/jovvix/api/constants"
 	"github.com/Improwised/jovvix/api/database"
 	"github.com/Improwised/jovvix/api/models"
 	pMetrics "github.com/Improwised/jovvix/api/pkg/prometheus"
+	"github.com/Improwised/jovvix/api/pkg/pubsub"
 	"github.com/Improwised/jovvix/api/routes"
 	fiber "github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -46,8 +48,13 @@ func GetAPICommandDef(cfg config.AppConfig, logger *zap.Logger) cobra.Command {
 				return err
 			}
 
+			pubSub, err := pubsub.New(cfg, logger)
+			if err != nil {
+				return err
+			}
+
 			// setup routes
-			err = routes.Setup(app, db, logger, cfg, promMetrics)
+			err = routes.Setup(app, db, logger, cfg, promMetrics, pubSub)
 			if err != nil {
 				logger.Error(err.Error())
 				return err
@@ -56,21 +63,33 @@ func GetAPICommandDef(cfg config.AppConfig, logger *zap.Logger) cobra.Command {
 			// Background sweeper: every ACTIVE_QUIZ_SWEEP_MINUTES, auto-terminate any quiz
 			activeQuizModel := models.InitActiveQuizModel(db, logger)
 
-			ttl := time.Duration(cfg.Quiz.ActiveQuizTTLHours) * time.Hour //
-			if ttl <= 0 {
-				ttl = 24 * time.Hour
-			}
+			ttl := cfg.Quiz.ActiveQuizTTL()
 			sweepInterval := time.Duration(cfg.Quiz.ActiveQuizSweepMinutes) * time.Minute
 			if sweepInterval <= 0 {
 				sweepInterval = 60 * time.Minute
 			}
 			go func() {
 				for {
+					// every replica runs this loop, only the one holding the sweeper lease sweeps
+					isLeader, err := pubsub.HoldLease(pubSub, constants.KeySweeperLease, 2*sweepInterval)
+					if err != nil {
+						logger.Error("active quiz sweeper election failed", zap.Error(err))
+					}
+					if !isLeader {
+						time.Sleep(sweepInterval)
+						continue
+					}
+
 					if count, err := activeQuizModel.DeactivateExpired(ttl); err != nil {
 						logger.Error("active quiz sweeper failed", zap.Error(err))
 					} else if count > 0 {
 						logger.Info("active quiz sweeper: deactivated expired sessions", zap.Int64("count", count))
 					}
+					if count, err := activeQuizModel.CloseExpiredAssignments(); err != nil {
+						logger.Error("active quiz sweeper failed to close assignments", zap.Error(err))
+					} else if count > 0 {
+						logger.Info("active quiz sweeper: closed assignments", zap.Int64("count", count))
+					}
 					time.Sleep(sweepInterval)
 				}
 			}()
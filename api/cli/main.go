@@ -11,8 +11,10 @@ func Init(cfg config.AppConfig, logger *zap.Logger) error {
 	migrationCmd := GetMigrationCommandDef(cfg)
 	apiCmd := GetAPICommandDef(cfg, logger)
 	deleteOrphanedKratosUserCmd := GetDeleteOrphanedCommand(cfg)
+	protocolSchemaCmd := GetProtocolSchemaCommandDef()
+	simulateCmd := GetSimulateCommandDef(cfg, logger)
 
 	rootCmd := &cobra.Command{Use: "jovvix"}
-	rootCmd.AddCommand(&migrationCmd, &apiCmd, &deleteOrphanedKratosUserCmd)
+	rootCmd.AddCommand(&migrationCmd, &apiCmd, &deleteOrphanedKratosUserCmd, &protocolSchemaCmd, &simulateCmd)
 	return rootCmd.Execute()
 }
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/spf13/cobra"
+)
+
+// GetProtocolSchemaCommandDef initialize command to generate the socket protocol schema
+func GetProtocolSchemaCommandDef() cobra.Command {
+	var output string
+	var version int
+
+	protocolCmd := cobra.Command{
+		Use:   "protocol-schema",
+		Short: "To generate the JSON Schema of the socket protocol",
+		Long:  `To generate the JSON Schema of the socket protocol from its message types, written to stdout unless an output file is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if version < protocol.V1 || version > protocol.Latest {
+				return fmt.Errorf("version must be between %d and %d", protocol.V1, protocol.Latest)
+			}
+
+			data, err := protocol.MarshalSchema(version)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+
+			return os.WriteFile(output, data, 0o644)
+		},
+	}
+
+	protocolCmd.Flags().StringVarP(&output, "output", "o", "", "file to write the schema to")
+	protocolCmd.Flags().IntVarP(&version, "version", "v", protocol.Latest, "protocol version to describe")
+
+	return protocolCmd
+}
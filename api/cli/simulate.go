@@ -0,0 +1,564 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/rand"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Improwised/jovvix/api/config"
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/database"
+	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/client"
+	"github.com/Improwised/jovvix/api/pkg/latency"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// metrics reported by the simulate command
+const (
+	metricQuestionDelivery = "question delivery"
+	metricAnswerAck        = "answer ack"
+	metricScoreboardFanOut = "scoreboard fan-out"
+	// metricSkipped counts the questions left unanswered, their type is unknown to the virtual players
+	metricSkipped = "skipped"
+)
+
+// GetSimulateCommandDef initialize command to play a session with virtual players
+func GetSimulateCommandDef(cfg config.AppConfig, logger *zap.Logger) cobra.Command {
+	var (
+		url             string
+		quizId          string
+		sessionId       string
+		kratosSession   string
+		players         int
+		joinConcurrency int
+		accuracy        float64
+		thinkMean       time.Duration
+		thinkStddev     time.Duration
+		skipWaits       bool
+		seed            int64
+		timeout         time.Duration
+	)
+
+	simulateCmd := cobra.Command{
+		Use:   "simulate",
+		Short: "To play a session with virtual players",
+		Long: `To play a session end to end against a running api: a demo session of the quiz is created and hosted, virtual
+guest players join it and answer every question, then the latencies of question delivery, answer acks and
+scoreboard fan-out are printed. The host is the registered owner of the quiz, given by their kratos session.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if kratosSession == "" {
+				return fmt.Errorf("the kratos session of the host is required")
+			}
+			if quizId == "" && sessionId == "" {
+				return fmt.Errorf("either a quiz or a session is required")
+			}
+			if players < 1 || joinConcurrency < 1 {
+				return fmt.Errorf("players and join concurrency must be at least 1")
+			}
+			if accuracy < 0 || accuracy > 1 {
+				return fmt.Errorf("accuracy must be between 0 and 1")
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			db, err := database.Connect(cfg.DB)
+			if err != nil {
+				return err
+			}
+
+			sim := &simulation{
+				url:             url,
+				players:         players,
+				joinConcurrency: joinConcurrency,
+				accuracy:        accuracy,
+				thinkMean:       thinkMean,
+				thinkStddev:     thinkStddev,
+				skipWaits:       skipWaits,
+				seed:            seed,
+				questionModel:   models.InitQuestionModel(db, logger),
+				activeQuizModel: models.InitActiveQuizModel(db, logger),
+				stats:           latency.New(),
+				scoreboards:     map[int][]scoreboardReceipt{},
+			}
+
+			host, err := client.New(url)
+			if err != nil {
+				return err
+			}
+			host.SetCookie(constants.KratosCookie, kratosSession)
+
+			session := uuid.Nil
+			if sessionId != "" {
+				session, err = uuid.Parse(sessionId)
+			} else {
+				var quiz uuid.UUID
+				quiz, err = uuid.Parse(quizId)
+				if err == nil {
+					session, err = host.DemoSession(ctx, quiz)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			sim.sessionId = session
+
+			err = sim.run(ctx, host)
+			sim.report(cmd.OutOrStdout())
+			return err
+		},
+	}
+
+	simulateCmd.Flags().StringVar(&url, "url", "http://localhost:"+cfg.Port, "address the api is served on")
+	simulateCmd.Flags().StringVar(&quizId, "quiz", "", "quiz to create a demo session of")
+	simulateCmd.Flags().StringVar(&sessionId, "session", "", "session to host instead of creating one")
+	simulateCmd.Flags().StringVar(&kratosSession, "kratos-session", os.Getenv("KRATOS_SESSION"), "ory_kratos_session cookie of the host, defaults to KRATOS_SESSION")
+	simulateCmd.Flags().IntVarP(&players, "players", "n", 20, "number of virtual players")
+	simulateCmd.Flags().IntVar(&joinConcurrency, "join-concurrency", 10, "players joining at the same time")
+	simulateCmd.Flags().Float64Var(&accuracy, "accuracy", 0.7, "share of the answers that are correct")
+	simulateCmd.Flags().DurationVar(&thinkMean, "think-mean", 3*time.Second, "mean time a player takes to answer")
+	simulateCmd.Flags().DurationVar(&thinkStddev, "think-stddev", time.Second, "standard deviation of the time a player takes to answer")
+	simulateCmd.Flags().BoolVar(&skipWaits, "skip-waits", true, "skip a question once everybody answered and the scoreboard timer")
+	simulateCmd.Flags().Int64Var(&seed, "seed", time.Now().UnixNano(), "seed of the answers and think times")
+	simulateCmd.Flags().DurationVar(&timeout, "timeout", 30*time.Minute, "time the whole simulation may take")
+
+	return simulateCmd
+}
+
+type scoreboardReceipt struct {
+	at       time.Time
+	isPlayer bool
+}
+
+type simulation struct {
+	url             string
+	sessionId       uuid.UUID
+	players         int
+	joinConcurrency int
+	accuracy        float64
+	thinkMean       time.Duration
+	thinkStddev     time.Duration
+	skipWaits       bool
+	seed            int64
+
+	questionModel   *models.QuestionModel
+	activeQuizModel *models.ActiveQuizModel
+
+	// answerKeys caches the answer key of every question, isShuffled is read once the quiz runs
+	answerKeys  sync.Map
+	shuffleOnce sync.Once
+	isShuffled  bool
+
+	stats       *latency.Recorder
+	mu          sync.Mutex
+	joined      int
+	scoreboards map[int][]scoreboardReceipt
+}
+
+func (s *simulation) run(ctx context.Context, host *client.Client) error {
+	hostConn, err := host.Host(ctx, s.sessionId)
+	if err != nil {
+		return fmt.Errorf("host the session: %w", err)
+	}
+	defer hostConn.Close()
+
+	code, err := s.invitationCode(ctx, hostConn)
+	if err != nil {
+		return err
+	}
+
+	players := s.join(ctx, code)
+	defer func() {
+		for _, player := range players {
+			player.Close()
+		}
+	}()
+	if len(players) == 0 {
+		return fmt.Errorf("no player could join the session")
+	}
+
+	if err := s.awaitLobby(ctx, hostConn, len(players)); err != nil {
+		return err
+	}
+
+	if err := hostConn.Start(); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i, player := range players {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.play(ctx, player, rand.New(rand.NewSource(s.seed+int64(i))))
+		}()
+	}
+
+	err = s.hostQuiz(ctx, hostConn)
+	wg.Wait()
+
+	return err
+}
+
+// invitationCode waits for the code the session was activated with
+func (s *simulation) invitationCode(ctx context.Context, host *client.Host) (int, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case event, ok := <-host.Events():
+			if !ok {
+				return 0, fmt.Errorf("host socket closed: %v", host.Err())
+			}
+			if event.Failed() {
+				return 0, fmt.Errorf("host the session: %s %s", event.Name, string(event.Data))
+			}
+			if code, ok := event.Payload.(protocol.InvitationCode); ok {
+				return code.Code, nil
+			}
+		}
+	}
+}
+
+// join creates the virtual players and joins them to the session, the ones that fail are left out
+func (s *simulation) join(ctx context.Context, code int) []*client.Player {
+	players := make([]*client.Player, s.players)
+	slots := make(chan struct{}, s.joinConcurrency)
+
+	var wg sync.WaitGroup
+	for i := range players {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			c, err := client.New(s.url)
+			if err == nil {
+				name := fmt.Sprintf("sim-%d", i+1)
+				_, err = c.CreateGuest(ctx, name, name)
+			}
+			if err == nil {
+				players[i], err = c.Join(ctx, strconv.Itoa(code))
+			}
+			if err != nil {
+				s.stats.Fail("join")
+			}
+		}()
+	}
+	wg.Wait()
+
+	return slices.DeleteFunc(players, func(player *client.Player) bool { return player == nil })
+}
+
+// awaitLobby waits until the host sees every player in the lobby
+func (s *simulation) awaitLobby(ctx context.Context, host *client.Host, count int) error {
+	lobbyCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	for {
+		select {
+		case <-lobbyCtx.Done():
+			return fmt.Errorf("%d of %d players reached the lobby", s.joined, count)
+		case event, ok := <-host.Events():
+			if !ok {
+				return fmt.Errorf("host socket closed: %v", host.Err())
+			}
+			roster, ok := event.Payload.([]protocol.Player)
+			if !ok {
+				continue
+			}
+
+			alive := 0
+			for _, player := range roster {
+				if player.IsAlive {
+					alive++
+				}
+			}
+			s.joined = alive
+			if alive >= count {
+				return nil
+			}
+		}
+	}
+}
+
+// hostQuiz moves the quiz on like a host would until it is terminated
+func (s *simulation) hostQuiz(ctx context.Context, host *client.Host) error {
+	answered := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-host.Events():
+			if !ok {
+				return fmt.Errorf("host socket closed: %v", host.Err())
+			}
+
+			var err error
+			switch {
+			case event.Is(constants.EventSendQuestion):
+				answered = 0
+			case event.Is(constants.EventAnswerSubmittedByUser):
+				answered++
+				if s.skipWaits && answered == s.joined {
+					err = host.Skip(false)
+				}
+			case event.Is(constants.EventShowScore):
+				if scoreboard, ok := event.Payload.(protocol.Scoreboard); ok {
+					s.scoreboardReceived(scoreboard.QuestionNo, time.Now(), false)
+				}
+				if s.skipWaits {
+					err = host.SkipTimer()
+				}
+			case event.Is(constants.EventNextQuestionAsked):
+				err = host.Next()
+			case event.Is(constants.EventTerminateQuiz):
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// play answers every question the player gets until the quiz is terminated
+func (s *simulation) play(ctx context.Context, player *client.Player, rng *rand.Rand) {
+	var sentAt sync.Map
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-player.Events():
+			if !ok {
+				return
+			}
+			received := time.Now()
+
+			switch payload := event.Payload.(type) {
+			case protocol.Question:
+				if serverTime, err := time.Parse(time.RFC3339Nano, payload.ServerTime); err == nil {
+					s.stats.Add(metricQuestionDelivery, received.Sub(serverTime))
+				}
+
+				answer, ok := s.answer(payload, player.Participation.UserPlayedQuiz, rng)
+				if !ok {
+					s.stats.Fail(metricSkipped)
+					continue
+				}
+				think := s.thinkTime(payload.Duration, rng)
+				time.AfterFunc(think, func() {
+					sentAt.Store(payload.ID, time.Now())
+					if err := player.Submit(answer); err != nil {
+						s.stats.Fail(metricAnswerAck)
+					}
+				})
+			case protocol.AnswerAck:
+				sent, ok := sentAt.Load(payload.ID)
+				if event.Failed() || !ok {
+					s.stats.Fail(metricAnswerAck)
+					continue
+				}
+				s.stats.Add(metricAnswerAck, received.Sub(sent.(time.Time)))
+			case protocol.Scoreboard:
+				s.scoreboardReceived(payload.QuestionNo, received, true)
+			}
+
+			if event.Is(constants.EventTerminateQuiz) {
+				return
+			}
+		}
+	}
+}
+
+// answer answers question the way its type takes, correctly for the share of the answers set by accuracy.
+// It is false for a question of a type the virtual players do not know.
+func (s *simulation) answer(question protocol.Question, userPlayedQuiz string, rng *rand.Rand) (protocol.SubmitAnswer, bool) {
+	answerKey := s.answerKey(question.ID)
+	correct := rng.Float64() < s.accuracy
+
+	options := make([]string, 0, len(question.Options))
+	for key := range question.Options {
+		options = append(options, key)
+	}
+	slices.Sort(options)
+
+	// the keys of the question are sent as the player sees them
+	mapping := map[string]string{}
+	if s.shuffled() {
+		mapping = quizUtilsHelper.OptionKeyMapping(userPlayedQuiz+question.ID.String(), options)
+	}
+
+	switch question.Type {
+	case constants.SingleAnswer, constants.Survey, constants.MultipleAnswer:
+		return protocol.SubmitAnswer{AnswerKeys: pickKeys(options, quizUtilsHelper.ToDisplayedKeys(answerKey.Answers, mapping), correct, rng)}, true
+	case constants.ShortAnswer:
+		rules := structs.ShortAnswerRules{}
+		_ = json.Unmarshal(answerKey.AnswerRules, &rules)
+		if correct && len(rules.Accepted) > 0 {
+			return protocol.SubmitAnswer{AnswerText: rules.Accepted[rng.Intn(len(rules.Accepted))]}, true
+		}
+		return protocol.SubmitAnswer{AnswerText: "no idea"}, true
+	case constants.Numeric:
+		rules := structs.NumericRules{}
+		_ = json.Unmarshal(answerKey.AnswerRules, &rules)
+		value := rules.Target
+		if !correct {
+			// off by more than the partial range, so the answer earns nothing
+			margin := max(rules.Tolerance, rules.PartialRange)
+			if rules.ToleranceType == constants.TolerancePercent {
+				margin = math.Abs(rules.Target) * margin / 100
+			}
+			value += 2*margin + 1
+		}
+		return protocol.SubmitAnswer{AnswerValue: &value}, true
+	case constants.Ordering:
+		order := slices.Clone(answerKey.Answers)
+		if !correct {
+			slices.Reverse(order)
+		}
+		return protocol.SubmitAnswer{AnswerKeys: quizUtilsHelper.ToDisplayedKeys(order, mapping)}, true
+	case constants.Matching:
+		rules := structs.MatchingRules{}
+		_ = json.Unmarshal(answerKey.AnswerRules, &rules)
+		return protocol.SubmitAnswer{AnswerPairs: quizUtilsHelper.ToDisplayedPairs(pickPairs(rules.Pairs, correct), mapping)}, true
+	case constants.WordCloud:
+		return protocol.SubmitAnswer{AnswerText: cloudWords[rng.Intn(len(cloudWords))]}, true
+	}
+
+	return protocol.SubmitAnswer{}, false
+}
+
+// cloudWords are the phrases the virtual players answer word cloud questions with
+var cloudWords = []string{"fast", "simple", "fun", "easy to use", "reliable", "fun and fast"}
+
+// pickKeys picks the correct keys, or one wrong option when the answer is to be wrong
+func pickKeys(options []string, correctKeys []int, correct bool, rng *rand.Rand) []int {
+	wrong := []int{}
+	for _, option := range options {
+		key, err := strconv.Atoi(option)
+		if err == nil && !slices.Contains(correctKeys, key) {
+			wrong = append(wrong, key)
+		}
+	}
+
+	if correct || len(wrong) == 0 {
+		return correctKeys
+	}
+	return []int{wrong[rng.Intn(len(wrong))]}
+}
+
+// pickPairs gives the right pairs, or every option paired with the definition of the next one when the
+// answer is to be wrong
+func pickPairs(rightPairs map[string]int, correct bool) map[string]int {
+	if correct || len(rightPairs) < 2 {
+		return rightPairs
+	}
+
+	keys := slices.Sorted(maps.Keys(rightPairs))
+	pairs := make(map[string]int, len(keys))
+	for index, key := range keys {
+		pairs[key] = rightPairs[keys[(index+1)%len(keys)]]
+	}
+	return pairs
+}
+
+func (s *simulation) answerKey(questionId uuid.UUID) models.AnswerKey {
+	if answerKey, ok := s.answerKeys.Load(questionId); ok {
+		return answerKey.(models.AnswerKey)
+	}
+
+	answerKey, err := s.questionModel.GetAnswerKey(questionId.String())
+	if err != nil {
+		return models.AnswerKey{Answers: []int{}}
+	}
+	s.answerKeys.Store(questionId, answerKey)
+
+	return answerKey
+}
+
+// shuffled reads whether the session shows the options of every player in their own order
+func (s *simulation) shuffled() bool {
+	s.shuffleOnce.Do(func() {
+		session, err := s.activeQuizModel.GetSession(s.sessionId.String())
+		s.isShuffled = err == nil && session.ShuffleOptions
+	})
+
+	return s.isShuffled
+}
+
+// thinkTime draws how long the player takes to answer, always within the duration of the question
+func (s *simulation) thinkTime(durationInSeconds int, rng *rand.Rand) time.Duration {
+	think := time.Duration(rng.NormFloat64()*float64(s.thinkStddev)) + s.thinkMean
+	limit := time.Duration(durationInSeconds)*time.Second - 500*time.Millisecond
+
+	return max(min(think, limit), 0)
+}
+
+func (s *simulation) scoreboardReceived(questionNo int, at time.Time, isPlayer bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scoreboards[questionNo] = append(s.scoreboards[questionNo], scoreboardReceipt{at: at, isPlayer: isPlayer})
+}
+
+// report prints the percentiles of every metric, the fan-out of a scoreboard is measured from its first receipt
+func (s *simulation) report(w io.Writer) {
+	s.mu.Lock()
+	for _, receipts := range s.scoreboards {
+		first := receipts[0].at
+		for _, receipt := range receipts {
+			if receipt.at.Before(first) {
+				first = receipt.at
+			}
+		}
+		for _, receipt := range receipts {
+			if receipt.isPlayer {
+				s.stats.Add(metricScoreboardFanOut, receipt.at.Sub(first))
+			}
+		}
+	}
+	questions := len(s.scoreboards)
+	s.mu.Unlock()
+
+	fmt.Fprintf(w, "session %s: %d of %d players joined, %d questions played\n\n", s.sessionId, s.joined, s.players, questions)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "metric\tcount\tfailed\tp50\tp90\tp95\tp99\tmax")
+	for _, metric := range []string{metricQuestionDelivery, metricAnswerAck, metricScoreboardFanOut} {
+		samples, failed := s.stats.Get(metric)
+		fmt.Fprintf(table, "%s\t%d\t%d\t%v\t%v\t%v\t%v\t%v\n", metric, len(samples), failed,
+			latency.Percentile(samples, 50), latency.Percentile(samples, 90), latency.Percentile(samples, 95), latency.Percentile(samples, 99), latency.Percentile(samples, 100))
+	}
+	if _, failed := s.stats.Get("join"); failed > 0 {
+		fmt.Fprintf(table, "join\t-\t%d\t\t\t\t\t\n", failed)
+	}
+	table.Flush()
+
+	if _, skipped := s.stats.Get(metricSkipped); skipped > 0 {
+		fmt.Fprintf(w, "\n%d answers skipped, the virtual players do not know the type of their question\n", skipped)
+	}
+}
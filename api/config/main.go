@@ -29,6 +29,7 @@ type AppConfig struct {
 	WebUrl        string `envconfig:"WEB_URL"`
 	JWTIssuer     string `envconfig:"ISSUER"`
 	BodyLimitMB   int    `envconfig:"BODY_LIMIT_MB"`
+	PubSubBackend string `envconfig:"PUBSUB_BACKEND"`
 	RedisClient   RedisClientConfig
 	DB            DBConfig
 	Kratos        KratosConfig
@@ -1,6 +1,9 @@
 package config
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type QuizConfig struct {
 	QuestionTimeLimit      string   `envconfig:"QUESTION_TIME_LIMIT"`
@@ -10,6 +13,75 @@ type QuizConfig struct {
 	PublicQuizAdminEmails  []string `envconfig:"PUBLIC_QUIZ_ADMIN_EMAILS"`
 	ActiveQuizTTLHours     int      `envconfig:"ACTIVE_QUIZ_TTL_HOURS"`
 	ActiveQuizSweepMinutes int      `envconfig:"ACTIVE_QUIZ_SWEEP_MINUTES"`
+	SessionLeaseSeconds    int      `envconfig:"SESSION_LEASE_SECONDS"`
+	ResponseToleranceMs    int      `envconfig:"RESPONSE_TIME_TOLERANCE_MS"`
+	ResponseAnomalyMs      int      `envconfig:"RESPONSE_TIME_ANOMALY_MS"`
+	HubSendBuffer          int      `envconfig:"HUB_SEND_BUFFER"`
+	HubSlowConsumer        string   `envconfig:"HUB_SLOW_CONSUMER"`
+	JoinLinkBaseUrl        string   `envconfig:"JOIN_LINK_BASE_URL"`
+	WordCloudMaskedWords   []string `envconfig:"WORD_CLOUD_MASKED_WORDS"`
+	WordCloudMask          string   `envconfig:"WORD_CLOUD_MASK"`
+}
+
+// ActiveQuizTTL is how long a session runs before the sweeper terminates it. Defaults to 24 hours.
+func (q QuizConfig) ActiveQuizTTL() time.Duration {
+	if q.ActiveQuizTTLHours <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(q.ActiveQuizTTLHours) * time.Hour
+}
+
+// JoinLinkBase is the public url the short join links of the api are served under, the token is
+// appended to it. Defaults to the api of a local setup.
+func (q QuizConfig) JoinLinkBase() string {
+	if q.JoinLinkBaseUrl == "" {
+		return "http://127.0.0.1:3000/api/v1/j"
+	}
+	return strings.TrimRight(q.JoinLinkBaseUrl, "/")
+}
+
+// SessionLease is how long a replica owns a running session without renewing it
+// before another replica takes the session over. Defaults to 15 seconds.
+func (q QuizConfig) SessionLease() time.Duration {
+	if q.SessionLeaseSeconds <= 0 {
+		return 15 * time.Second
+	}
+	return time.Duration(q.SessionLeaseSeconds) * time.Second
+}
+
+// ResponseTolerance is how much shorter than the time measured by the server the response time
+// reported by a player may be, it covers the network latency. Defaults to 1.5 seconds.
+func (q QuizConfig) ResponseTolerance() time.Duration {
+	if q.ResponseToleranceMs <= 0 {
+		return 1500 * time.Millisecond
+	}
+	return time.Duration(q.ResponseToleranceMs) * time.Millisecond
+}
+
+// ResponseAnomalyThreshold is how far the response time reported by a player may be from the time
+// measured by the server before the answer is flagged. Defaults to 3 seconds.
+func (q QuizConfig) ResponseAnomalyThreshold() time.Duration {
+	if q.ResponseAnomalyMs <= 0 {
+		return 3 * time.Second
+	}
+	return time.Duration(q.ResponseAnomalyMs) * time.Millisecond
+}
+
+// HubBuffer is how many messages a socket may be behind before the hub treats it as a slow consumer.
+// Defaults to 64.
+func (q QuizConfig) HubBuffer() int {
+	if q.HubSendBuffer <= 0 {
+		return 64
+	}
+	return q.HubSendBuffer
+}
+
+// CloudMask is what the letters of a masked word cloud word are replaced with. Defaults to *.
+func (q QuizConfig) CloudMask() string {
+	if q.WordCloudMask == "" {
+		return "*"
+	}
+	return q.WordCloudMask
 }
 
 // IsPublicQuizAdmin reports whether the given email is allowed to publish public quizzes.
@@ -87,6 +87,35 @@ const (
 	ErrCategoryAlreadyExists       = "a category with this name already exists"
 	ErrInvalidCoverImage           = "cover image must be an image"
 	ErrCoverImageTooLarge          = "cover image is too large"
+	ErrCreatingAssignment          = "error while creating assignment"
+	ErrAssignmentWindow            = "assignment must close after it opens and not in the past"
+	ErrAssignmentNotOpen           = "assignment is not open yet"         // use by web
+	ErrAssignmentClosed            = "assignment is closed"               // use by web
+	ErrAssignmentIsSelfPaced       = "assignment sessions are self-paced" // use by web
+	ErrAssignmentQuestionExpired   = "time for this question is over"
+	ErrConfigureTeams              = "error while configuring teams"
+	ErrGetTeams                    = "error while getting teams"
+	ErrTeamsLocked                 = "teams can not change once the quiz has started"
+	ErrTeamNotFound                = "team not found"
+	ErrTeamChoiceDisabled          = "players can not choose their team in this session"
+	ErrTeamBestN                   = "best_n is required to score teams by their best players"
+	ErrSetShuffle                  = "error while saving shuffle settings"
+	ErrPlayerNotFound              = "player not found in this session"
+	ErrPlayerKicked                = "you were removed from this session by the host" // use by web
+	ErrPlayerBanned                = "you are banned from this session"               // use by web
+	ErrModeratePlayer              = "error while moderating player"
+	ErrDisplayName                 = "display name must have 1 to 50 characters"
+	ErrLobbyFull                   = "this session is full"                               // use by web
+	ErrLobbyLocked                 = "the host locked this session"                       // use by web
+	ErrLateJoinClosed              = "this session does not take players once it started" // use by web
+	ErrSetJoinPolicy               = "error while saving join policy"
+	ErrLockLobby                   = "error while locking the lobby"
+	ErrPasscodeRequired            = "this session needs a passcode"                  // use by web
+	ErrPasscodeWrong               = "the passcode is wrong"                          // use by web
+	ErrNotOnAllowlist              = "this session is only open to invited accounts"  // use by web
+	ErrSpectateProtected           = "only the host can spectate a protected session" // use by web
+	ErrSessionAccess               = "a passcode session needs a passcode of 4 to 50 characters, an allowlist session needs valid emails or domains"
+	ErrSetSessionAccess            = "error while saving session access"
 )
 
 // Bad Request Message
@@ -127,6 +156,16 @@ const (
 	ErrRowsReachesToMaxCount    = "rows limit exceed"
 	ErrSurveyAnswerLength       = "in survey correct answer should contain all the options as correct"
 	ErrSingleAnswerLength       = "in single answer there should be only one correct answer"
+	ErrMultipleAnswerLength     = "in multiple answer there should be at least one correct answer and one wrong option"
+	ErrGrading                  = "grading must be one of: all_or_nothing, proportional, proportional_penalty"
+	ErrShortAnswerRules         = "short answer needs at least one accepted answer and a max distance from 0 to 5"
+	ErrAnswerPattern            = "answer pattern is not a valid regular expression"
+	ErrNumericRules             = "numeric answer needs a target, a tolerance of zero or more and a partial range wider than the tolerance"
+	ErrToleranceType            = "tolerance type must be one of: absolute, percent"
+	ErrUnitLength               = "unit should be at most 20 characters"
+	ErrNumericAnswer            = "numeric correct answer should be written as target|tolerance|partial range, e.g. 100|5%|20%"
+	ErrOrderingAnswer           = "in ordering the correct answer should list every option once, in the right order"
+	ErrMatchingRules            = "in matching every option should be paired with its own definition"
 	ErrQuestionType             = "please provide a proper question type"
 	ErrQuestionId               = "question type id not exists"
 	ErrEmptyFile                = "The uploaded file is empty. Please choose a file with content."
@@ -266,6 +305,8 @@ const (
 	ErrAnswerAlreadySubmitted = "answer already submitted"
 	ErrQuestionNotActive      = "question can not receive answers anymore"
 	ErrPublishAnswer          = "error while publishing answer in redis"
+	EventSubmitAnswer         = "submit_answer" // use by web
+	ActionSubmitAnswer        = "answer submitted over the socket"
 
 	// Event skip
 	EventSkipAsked  = "ask_skip" // use by web
@@ -292,6 +333,55 @@ const (
 	// Event 11. ping
 	EventPing = "ping"
 	EventPong = "pong"
+
+	// Event 12. player reconnect
+	RejoinTokenParam  = "rejoin_token"
+	EventRejoinToken  = "rejoin_token" // use by web
+	ActionRejoinToken = "token to present when the player reconnects"
+	EventPlayerState  = "player_state" // use by web
+	ActionPlayerState = "state of the player on reconnect"
+
+	// Event 13. host reconnect
+	EventSessionState  = "session_state" // use by web
+	ActionSessionState = "current state of the running session"
+	EventStopSession   = "stop_session"
+
+	// Event 14. teams
+	EventTeamRoster  = "team_roster" // use by web
+	ActionTeamRoster = "teams of the session with their members"
+
+	// Event 15. spectator
+	EventAnswerCount  = "answer_count" // use by web
+	ActionAnswerCount = "live count of the answers to the running question"
+
+	// Event 16. moderation
+	EventKickPlayer    = "kick_player"   // use by web
+	EventBanPlayer     = "ban_player"    // use by web
+	EventRenamePlayer  = "rename_player" // use by web
+	ActionKickPlayer   = "player removed from the session by the host"
+	ActionBanPlayer    = "player banned from the session by the host"
+	ActionRenamePlayer = "player renamed by the host"
+
+	// Event 17. protocol
+	EventHello          = "hello" // use by web
+	ActionHello         = "protocol version of the socket"
+	EventWebsocketClose = "websocket_close"
+	ProtocolVersion     = "protocolVersion"
+
+	// Event 18. lobby
+	EventLobbyChanged  = "lobby_changed" // use by web
+	ActionLobbyChanged = "a player joined or left the lobby"
+	LobbyJoined        = "joined"
+	LobbyLeft          = "left"
+	LobbyRenamed       = "renamed"
+
+	// Event 19. join policy
+	EventLockLobby  = "lock_lobby" // use by web
+	ActionLockLobby = "the lobby was locked or unlocked by the host"
+
+	// Event 20. word cloud
+	EventWordCloud  = "word_cloud" // use by web
+	ActionWordCloud = "live words of the answers to the running open question"
 )
 
 // final scoreboard cookie for user
@@ -312,11 +402,57 @@ const (
 
 // Question Types
 const (
-	SingleAnswerString = "single answer"
-	SurveyString       = "survey"
+	SingleAnswerString   = "single answer"
+	SurveyString         = "survey"
+	MultipleAnswerString = "multiple answer"
+	ShortAnswerString    = "short answer"
+	NumericString        = "numeric"
+	OrderingString       = "ordering"
+	MatchingString       = "matching"
+	WordCloudString      = "word cloud"
+
+	SingleAnswer   = 1
+	Survey         = 2
+	MultipleAnswer = 3
+	ShortAnswer    = 4
+	Numeric        = 5
+	Ordering       = 6
+	Matching       = 7
+	WordCloud      = 8
+)
+
+// Short answers
+const (
+	MaxAnswerTextLength = 200
+	MaxAnswerDistance   = 5
+)
+
+// Numeric answers
+const (
+	// ToleranceAbsolute is a tolerance in the unit of the answer
+	ToleranceAbsolute = "absolute"
+	// TolerancePercent is a tolerance in percent of the target
+	TolerancePercent = "percent"
+
+	MaxUnitLength = 20
+	// MaxValueBuckets is the most bars the histogram of the submitted values is drawn with
+	MaxValueBuckets = 10
+)
+
+// Word clouds
+const (
+	// MaxCloudWords is the most words a word cloud shows
+	MaxCloudWords = 50
+)
 
-	SingleAnswer = 1
-	Survey       = 2
+// Grading of multiple answer questions
+const (
+	// GradingAllOrNothing scores only the exact set of correct options
+	GradingAllOrNothing = "all_or_nothing"
+	// GradingProportional scores each correct option picked, wrong picks cost nothing
+	GradingProportional = "proportional"
+	// GradingPenalty scores each correct option picked less each wrong one
+	GradingPenalty = "proportional_penalty"
 )
 
 // Media Types
@@ -336,9 +472,86 @@ const (
 	DefaultPageSize      = 10
 )
 
+// Join links of a session
+const (
+	JoinLinkTokenParam = "token"
+	FormatQueryParam   = "format"
+	PasscodeQueryParam = "passcode"
+	JoinLinkQueryParam = "join_link"
+	ScaleQueryParam    = "scale"
+	QRFormatPNG        = "png"
+	QRFormatSVG        = "svg"
+	DefaultQRScale     = 8
+	MaxQRScale         = 32
+	ErrJoinLink        = "error while generating the join link"
+	ErrQRFormat        = "format must be png or svg"
+	ErrJoinLinkInvalid = "this join link is invalid" // use by web
+	ErrJoinLinkExpired = "this join link expired"    // use by web
+)
+
+// Session phases, persisted in active_quizzes.phase
+const (
+	SessionPhaseLobby        = "lobby"
+	SessionPhaseCounter      = "counter"
+	SessionPhaseQuestion     = "question"
+	SessionPhaseScoreboard   = "scoreboard"
+	SessionPhaseAwaitingNext = "awaiting_next"
+	SessionPhaseFinished     = "finished"
+)
+
+// Session modes, persisted in active_quizzes.mode
+const (
+	SessionModeLive       = "live"
+	SessionModeAssignment = "assignment"
+
+	// network slack on top of the question duration before a self-paced answer is refused
+	AssignmentAnswerGraceSeconds = 2
+)
+
+// Team settings, persisted in active_quizzes.team_mode and active_quizzes.team_scoring
+const (
+	TeamModeAuto   = "auto"
+	TeamModeChoice = "choice"
+
+	TeamScoringSum     = "sum"
+	TeamScoringAverage = "average"
+	TeamScoringBestN   = "best_n"
+)
+
+// Credit of a late joiner for the questions asked before they joined, persisted in active_quizzes.late_join_credit
+const (
+	LateJoinCreditZero    = "zero"
+	LateJoinCreditAverage = "average"
+)
+
+// Who may join a session with its code, persisted in active_quizzes.access_mode
+const (
+	SessionAccessOpen      = "open"
+	SessionAccessPasscode  = "passcode"
+	SessionAccessAllowlist = "allowlist"
+
+	SessionAllowlistTable = "session_allowlist"
+)
+
 // Channel name for redis pubsub
 const (
 	ChannelUserJoin       = "user_joined"
 	ChannelUserDisconnect = "user_disconnect"
 	ChannelSetAnswer      = "set_answer"
+	ChannelHostEvents     = "host_events"
+	ChannelHostCommands   = "host_commands"
+	ChannelTeamRoster     = "team_roster"
+	ChannelModeration     = "player_moderation"
+)
+
+// Redis keys used to coordinate a session across api replicas
+const (
+	KeySessionLease = "session_lease"
+	KeyHostReplay   = "host_replay"
+	KeyHostSeq      = "host_seq"
+	KeyHostCount    = "host_count"
+	KeyRejoinToken  = "rejoin_token"
+	KeyRoster       = "roster"
+	KeyLeaderboard  = "leaderboard"
+	KeySweeperLease = "active_quiz_sweeper"
 )
@@ -0,0 +1,243 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Improwised/jovvix/api/constants"
+	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/utils"
+	"github.com/doug-martin/goqu/v9"
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// AssignmentController serves the questions of self-paced sessions, one player at a time
+type AssignmentController struct {
+	questionModel         *models.QuestionModel
+	userPlayedQuizModel   *models.UserPlayedQuizModel
+	userQuizResponseModel *models.UserQuizResponseModel
+	logger                *zap.Logger
+}
+
+// NewAssignmentController returns an assignment controller
+func NewAssignmentController(goqu *goqu.Database, logger *zap.Logger) (*AssignmentController, error) {
+	return &AssignmentController{
+		questionModel:         models.InitQuestionModel(goqu, logger),
+		userPlayedQuizModel:   models.InitUserPlayedQuizModel(goqu),
+		userQuizResponseModel: models.InitUserQuizResponseModel(goqu),
+		logger:                logger,
+	}, nil
+}
+
+// getOpenAssignment returns the assignment of the participation in the path, when it is not open
+// the failure is already written to the response and false is returned
+func (ctrl *AssignmentController) getOpenAssignment(c *fiber.Ctx) (uuid.UUID, models.ActiveQuiz, bool, error) {
+	userId := quizUtilsHelper.GetString(c.Locals(constants.ContextUid))
+
+	userPlayedQuizId, err := uuid.Parse(c.Params(constants.UserPlayedQuizId))
+	if err != nil {
+		return userPlayedQuizId, models.ActiveQuiz{}, false, utils.JSONFail(c, http.StatusBadRequest, "invalid UUID")
+	}
+
+	session, err := ctrl.userPlayedQuizModel.GetPlayedSession(userPlayedQuizId, userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return userPlayedQuizId, session, false, utils.JSONFail(c, http.StatusBadRequest, constants.ErrQuizNotFound)
+		}
+		ctrl.logger.Error("error while getting played session", zap.Error(err))
+		return userPlayedQuizId, session, false, utils.JSONError(c, http.StatusInternalServerError, constants.UnknownError)
+	}
+
+	if !session.IsAssignment() {
+		return userPlayedQuizId, session, false, utils.JSONFail(c, http.StatusBadRequest, constants.ErrSessionNotFound)
+	}
+
+	if err := session.CheckAssignmentWindow(time.Now()); err != nil {
+		return userPlayedQuizId, session, false, utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	return userPlayedQuizId, session, true, nil
+}
+
+// GetNextQuestion to serve the next question of an assignment to the player.
+// swagger:route GET /v1/assignments/{user_played_quiz_id}/question Assignment RequestAssignmentQuestion
+//
+// Serve the next question of an assignment, its timer starts when it is served for the first time.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseAssignmentQuestion
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *AssignmentController) GetNextQuestion(c *fiber.Ctx) error {
+	userPlayedQuizId, session, ok, err := ctrl.getOpenAssignment(c)
+	if !ok {
+		return err
+	}
+
+	next, err := ctrl.userQuizResponseModel.GetNextAssignmentQuestion(userPlayedQuizId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.JSONSuccess(c, http.StatusOK, map[string]any{
+				"completed":        true,
+				"user_played_quiz": userPlayedQuizId,
+			})
+		}
+		ctrl.logger.Error("error while getting next assignment question", zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrInGettingQuestion)
+	}
+
+	deliveredAt, err := ctrl.userQuizResponseModel.MarkDelivered(userPlayedQuizId, next.QuestionID)
+	if err != nil {
+		ctrl.logger.Error("error while marking assignment question delivered", zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrInGettingQuestion)
+	}
+
+	question, err := ctrl.questionModel.GetCurrentQuestion(next.QuestionID)
+	if err != nil {
+		ctrl.logger.Error("error while getting assignment question", zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrInGettingQuestion)
+	}
+
+	totalQuestion, err := ctrl.questionModel.GetTotalQuestionCount(session.ID.String())
+	if err != nil {
+		ctrl.logger.Error("error while getting assignment question count", zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrInGettingTotalQuestionCount)
+	}
+
+	options := question.Options
+	if session.ShuffleOptions {
+		options = quizUtilsHelper.ShuffleOptions(options, optionMapping(userPlayedQuizId, question.ID, options))
+	}
+
+	return utils.JSONSuccess(c, http.StatusOK, map[string]any{
+		"completed":      false,
+		"id":             question.ID,
+		"no":             next.OrderNo,
+		"duration":       question.DurationInSeconds,
+		"start_time":     deliveredAt.Format(time.RFC3339),
+		"server_time":    time.Now().UTC().Format(time.RFC3339Nano),
+		"closes_at":      session.ActivatedTo.Time.Format(time.RFC3339),
+		"question":       question.Question,
+		"type":           question.Type,
+		"options":        options,
+		"totalQuestions": totalQuestion,
+		"question_media": question.QuestionMedia,
+		"options_media":  question.OptionsMedia,
+		"resource":       question.Resource.String,
+		"unit":           question.Unit.String,
+		"definitions":    question.Definitions,
+	})
+}
+
+// SubmitAnswer to record the answer of a player to the question they were served.
+// swagger:route POST /v1/assignments/{user_played_quiz_id}/answer Assignment RequestAssignmentAnswer
+//
+// Record the answer to an assignment question, the response time is measured by the server.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  202: ResponseAssignmentAnswer
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *AssignmentController) SubmitAnswer(c *fiber.Ctx) error {
+	userPlayedQuizId, session, ok, err := ctrl.getOpenAssignment(c)
+	if !ok {
+		return err
+	}
+
+	var answer structs.ReqAnswerSubmit
+
+	err = json.Unmarshal(c.Body(), &answer)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	validate := validator.New()
+	err = validate.Struct(answer)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, utils.ValidatorErrorString(err))
+	}
+
+	progress, err := ctrl.userQuizResponseModel.GetAssignmentQuestion(userPlayedQuizId, answer.QuestionId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrQuestionNotActive)
+		}
+		ctrl.logger.Error("error while getting assignment question progress", zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.UnknownError)
+	}
+
+	if progress.IsAnswered {
+		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrAnswerAlreadySubmitted)
+	}
+
+	if !progress.DeliveredAt.Valid {
+		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrQuestionNotActive)
+	}
+
+	// the timer runs on the server, the response time sent by the client is not trusted
+	elapsed := time.Since(progress.DeliveredAt.Time)
+	if elapsed > time.Duration(progress.DurationInSeconds+constants.AssignmentAnswerGraceSeconds)*time.Second {
+		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrAssignmentQuestionExpired)
+	}
+	timing := models.AnswerTiming{ClientResponseTime: sql.NullInt32{Int32: int32(answer.ResponseTime), Valid: true}}
+	answer.ResponseTime = min(int(elapsed.Milliseconds()), progress.DurationInSeconds*1000)
+
+	if session.ShuffleOptions {
+		question, err := ctrl.questionModel.GetCurrentQuestion(answer.QuestionId)
+		if err != nil {
+			ctrl.logger.Error("error while getting assignment question to map shuffled answer", zap.Error(err))
+			return utils.JSONError(c, http.StatusInternalServerError, constants.UnknownError)
+		}
+		canonicalKeys(&answer, optionMapping(userPlayedQuizId, answer.QuestionId, question.Options))
+	}
+
+	answerKey, err := ctrl.questionModel.GetAnswerKey(answer.QuestionId.String())
+	if err != nil {
+		ctrl.logger.Error("error while get answer, points, duration and type", zap.Error(err))
+		return utils.JSONFail(c, http.StatusBadRequest, "error while get answer, points, duration and type")
+	}
+
+	points, score := utils.ScoreAnswer(answer, answerKey)
+
+	streakCount, err := ctrl.userPlayedQuizModel.GetStreakCount(userPlayedQuizId, answer.QuestionId)
+	if err != nil {
+		ctrl.logger.Error(constants.ErrGetStreakCount, zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrGetStreakCount)
+	}
+
+	finalScore, newStreakCount := utils.CalculateStreakScore(streakCount, score)
+	if answerKey.Type == constants.WordCloud {
+		newStreakCount = streakCount
+	}
+
+	if err := ctrl.userQuizResponseModel.SubmitAnswer(userPlayedQuizId, answer, points, finalScore, newStreakCount, timing); err != nil {
+		if err == sql.ErrNoRows {
+			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrAnswerAlreadySubmitted)
+		}
+		ctrl.logger.Error("error during assignment answer submit", zap.Error(err))
+		return utils.JSONFail(c, http.StatusInternalServerError, constants.UnknownError)
+	}
+
+	return utils.JSONSuccess(c, http.StatusAccepted, map[string]any{
+		"score":         finalScore,
+		"streak":        newStreakCount,
+		"response_time": answer.ResponseTime,
+	})
+}
@@ -5,15 +5,19 @@ import (
 	"net/http"
 
 	"github.com/Improwised/jovvix/api/constants"
+	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
 	"github.com/Improwised/jovvix/api/models"
 	"github.com/Improwised/jovvix/api/utils"
 	"github.com/doug-martin/goqu/v9"
 	fiber "github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type FinalScoreBoardAdminController struct {
 	finalScoreBoardAdminModel *models.FinalScoreBoardAdminModel
+	sessionTeamModel          *models.SessionTeamModel
+	activeQuizModel           *models.ActiveQuizModel
 	logger                    *zap.Logger
 }
 
@@ -25,11 +29,38 @@ func NewFinalScoreBoardAdminController(goqu *goqu.Database, logger *zap.Logger)
 
 	return &FinalScoreBoardAdminController{
 		finalScoreBoardAdminModel: &finalScoreBoardAdminModel,
+		sessionTeamModel:          models.InitSessionTeamModel(goqu, logger),
+		activeQuizModel:           models.InitActiveQuizModel(goqu, logger),
 		logger:                    logger,
 	}, nil
 
 }
 
+// ownedSession returns the session in the query when the caller hosted it, otherwise the failure is
+// already written to the response and false is returned
+func (fc *FinalScoreBoardAdminController) ownedSession(ctx *fiber.Ctx) (uuid.UUID, bool, error) {
+	activeQuizId, err := uuid.Parse(ctx.Query(constants.ActiveQuizId))
+	if err != nil {
+		fc.logger.Debug("active quiz id is not a valid uuid")
+		return activeQuizId, false, utils.JSONFail(ctx, http.StatusBadRequest, errors.New("active quiz id should be valid string").Error())
+	}
+
+	session, err := fc.activeQuizModel.GetSession(activeQuizId.String())
+	if err != nil {
+		if err.Error() == constants.ErrSessionNotFound {
+			return activeQuizId, false, utils.JSONFail(ctx, http.StatusBadRequest, constants.ErrSessionNotFound)
+		}
+		fc.logger.Error("Error while getting session of final scoreboard for admin", zap.Error(err))
+		return activeQuizId, false, utils.JSONFail(ctx, http.StatusInternalServerError, errors.New("internal server error").Error())
+	}
+
+	if session.AdminID != quizUtilsHelper.GetString(ctx.Locals(constants.ContextUid)) {
+		return activeQuizId, false, utils.JSONError(ctx, http.StatusUnauthorized, constants.ErrUnauthorized)
+	}
+
+	return activeQuizId, true, nil
+}
+
 // GetScore to send final score after quiz over to admin
 // swagger:route GET /v1/final_score/admin FinalScore RequestFinalScoreForAdmin
 //
@@ -43,17 +74,15 @@ func NewFinalScoreBoardAdminController(goqu *goqu.Database, logger *zap.Logger)
 //		Responses:
 //		  200: ResponseFinalScoreForAdmin
 //	     400: GenericResFailNotFound
+//	     401: GenericResError
 //		  500: GenericResError
 func (fc *FinalScoreBoardAdminController) GetScoreForAdmin(ctx *fiber.Ctx) error {
-
-	var activeQuizId = ctx.Query(constants.ActiveQuizId)
-
-	if !(activeQuizId != "" && len(activeQuizId) == 36) {
-		fc.logger.Debug("active quiz id is not valid - either empty string or it is not 36 characters long")
-		return utils.JSONFail(ctx, http.StatusBadRequest, errors.New("user play quiz should be valid string").Error())
+	activeQuizId, ok, err := fc.ownedSession(ctx)
+	if !ok {
+		return err
 	}
 
-	finalScoreBoardData, err := fc.finalScoreBoardAdminModel.GetScoreForAdmin(activeQuizId)
+	finalScoreBoardData, err := fc.finalScoreBoardAdminModel.GetScoreForAdmin(activeQuizId.String())
 	if err != nil {
 		fc.logger.Error("Error while getting final scoreboard for admin", zap.Error(err))
 		return utils.JSONFail(ctx, http.StatusInternalServerError, errors.New("internal server error").Error())
@@ -61,3 +90,33 @@ func (fc *FinalScoreBoardAdminController) GetScoreForAdmin(ctx *fiber.Ctx) error
 
 	return utils.JSONSuccess(ctx, http.StatusOK, finalScoreBoardData)
 }
+
+// GetTeamScoreForAdmin to send the final team leaderboard after quiz over to admin
+// swagger:route GET /v1/final_score/admin/teams FinalScore RequestFinalTeamScoreForAdmin
+//
+// Get the final team leaderboard for admin.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseFinalTeamScore
+//	     400: GenericResFailNotFound
+//	     401: GenericResError
+//		  500: GenericResError
+func (fc *FinalScoreBoardAdminController) GetTeamScoreForAdmin(ctx *fiber.Ctx) error {
+	activeQuizId, ok, err := fc.ownedSession(ctx)
+	if !ok {
+		return err
+	}
+
+	teamRankBoard, err := fc.sessionTeamModel.GetTeamRank(activeQuizId)
+	if err != nil {
+		fc.logger.Error("Error while getting final team scoreboard for admin", zap.Error(err))
+		return utils.JSONFail(ctx, http.StatusInternalServerError, errors.New("internal server error").Error())
+	}
+
+	return utils.JSONSuccess(ctx, http.StatusOK, teamRankBoard)
+}
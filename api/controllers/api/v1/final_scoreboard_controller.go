@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"database/sql"
 	"errors"
 	"net/http"
 
@@ -9,11 +10,13 @@ import (
 	"github.com/Improwised/jovvix/api/utils"
 	"github.com/doug-martin/goqu/v9"
 	fiber "github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type FinalScoreBoardController struct {
 	finalScoreBoardModel *models.FinalScoreBoardModel
+	sessionTeamModel     *models.SessionTeamModel
 	logger               *zap.Logger
 }
 
@@ -25,6 +28,7 @@ func NewFinalScoreBoardController(goqu *goqu.Database, logger *zap.Logger) (*Fin
 
 	return &FinalScoreBoardController{
 		finalScoreBoardModel: &finalScoreBoardModel,
+		sessionTeamModel:     models.InitSessionTeamModel(goqu, logger),
 		logger:               logger,
 	}, nil
 
@@ -64,3 +68,36 @@ func (fc *FinalScoreBoardController) GetScore(ctx *fiber.Ctx) error {
 
 	return utils.JSONSuccess(ctx, http.StatusOK, finalScoreBoardData)
 }
+
+// GetTeamScore to send the final team leaderboard after quiz over
+// swagger:route GET /v1/final_score/user/teams FinalScore RequestFinalTeamScoreForUser
+//
+// Get the final team leaderboard for user.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseFinalTeamScore
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (fc *FinalScoreBoardController) GetTeamScore(ctx *fiber.Ctx) error {
+	userPlayedQuiz, err := uuid.Parse(ctx.Query(constants.UserPlayedQuiz))
+	if err != nil {
+		fc.logger.Debug("user played quiz id is not a valid uuid")
+		return utils.JSONFail(ctx, http.StatusBadRequest, errors.New("user play quiz should be valid string").Error())
+	}
+
+	teamRankBoard, err := fc.sessionTeamModel.GetTeamRankOfPlayedQuiz(userPlayedQuiz)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.JSONFail(ctx, http.StatusBadRequest, constants.ErrQuizNotFound)
+		}
+		fc.logger.Error("Error while getting final team scoreboard for user", zap.Error(err))
+		return utils.JSONFail(ctx, http.StatusInternalServerError, errors.New("internal server error").Error())
+	}
+
+	return utils.JSONSuccess(ctx, http.StatusOK, teamRankBoard)
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"os"
 	"strconv"
@@ -47,6 +48,82 @@ func InitQuestionController(db *goqu.Database, logger *zap.Logger, appConfig *co
 	}, nil
 }
 
+// checkAnswers checks the answers fit the type of the question and sets the rules to store with them
+func checkAnswers(question *models.Question, grading string, shortAnswer *structs.ShortAnswerRules, numeric *structs.NumericRules, matching *structs.MatchingRules) error {
+	if _, err := quizUtilsHelper.GetQuestionType(question.Type); err != nil {
+		return errors.New(constants.ErrQuestionType)
+	}
+
+	// the types not answered by picking options keep their answer key in the rules
+	switch question.Type {
+	case constants.ShortAnswer:
+		rules, err := quizUtilsHelper.CheckShortAnswer(shortAnswer)
+		if err != nil {
+			return err
+		}
+		return setAnswerRules(question, rules)
+	case constants.Numeric:
+		rules, err := quizUtilsHelper.CheckNumeric(numeric)
+		if err != nil {
+			return err
+		}
+		return setAnswerRules(question, rules)
+	case constants.Matching:
+		rules, err := quizUtilsHelper.CheckMatching(question.Options, matching)
+		if err != nil {
+			return err
+		}
+		question.AnswerRules, err = json.Marshal(quizUtilsHelper.ScrambleDefinitions(rules))
+		if err != nil {
+			return err
+		}
+		question.Answers = []int{}
+		question.Grading = constants.GradingProportional
+		return nil
+	case constants.WordCloud:
+		// players type anything, there is nothing to check them against
+		question.Options = map[string]string{}
+		question.Answers = []int{}
+		question.AnswerRules = nil
+		question.Grading = constants.GradingAllOrNothing
+		return nil
+	}
+
+	for _, answer := range question.Answers {
+		if _, ok := question.Options[strconv.Itoa(answer)]; !ok {
+			return errors.New(constants.ErrInvalidCorrectAnswer)
+		}
+	}
+
+	if err := quizUtilsHelper.CheckAnswerCount(question.Type, len(question.Options), question.Answers); err != nil {
+		return err
+	}
+
+	// each option in its right place earns a share of the points
+	if question.Type == constants.Ordering {
+		question.Options, question.Answers = quizUtilsHelper.ScrambleOrder(question.Options, question.Answers)
+		question.Grading = constants.GradingProportional
+		return nil
+	}
+
+	var err error
+	question.Grading, err = quizUtilsHelper.CheckGrading(grading)
+	return err
+}
+
+// setAnswerRules keeps the rules of a question answered without options in place of its options and answers
+func setAnswerRules(question *models.Question, rules any) error {
+	var err error
+	question.AnswerRules, err = json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	question.Options = map[string]string{}
+	question.Answers = []int{}
+	question.Grading = constants.GradingAllOrNothing
+	return nil
+}
+
 func (ctrl *QuestionController) getDefaultQuestionDuration() int {
 	parsedDuration, err := strconv.Atoi(ctrl.appConfig.Quiz.QuestionTimeLimit)
 	if err != nil || parsedDuration <= 0 {
@@ -165,19 +242,24 @@ func (ctrl *QuestionController) CreateQuestion(c *fiber.Ctx) error {
 		durationInSeconds = defaultDuration
 	}
 
-	questionIds, err := ctrl.quizSvc.AppendQuestionsToQuiz(quizId, []models.Question{
-		{
-			Question:          questionReq.Question,
-			Type:              questionReq.Type,
-			Options:           questionReq.Options,
-			Answers:           questionReq.Answers,
-			Points:            points,
-			DurationInSeconds: durationInSeconds,
-			QuestionMedia:     questionReq.QuestionMedia,
-			OptionsMedia:      questionReq.OptionsMedia,
-			Resource:          sql.NullString{String: questionReq.Resource, Valid: questionReq.Resource != ""},
-		},
-	})
+	question := models.Question{
+		Question:          questionReq.Question,
+		Type:              questionReq.Type,
+		Options:           questionReq.Options,
+		Answers:           questionReq.Answers,
+		Points:            points,
+		DurationInSeconds: durationInSeconds,
+		QuestionMedia:     questionReq.QuestionMedia,
+		OptionsMedia:      questionReq.OptionsMedia,
+		Resource:          sql.NullString{String: questionReq.Resource, Valid: questionReq.Resource != ""},
+	}
+
+	err = checkAnswers(&question, questionReq.Grading, questionReq.ShortAnswer, questionReq.Numeric, questionReq.Matching)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	questionIds, err := ctrl.quizSvc.AppendQuestionsToQuiz(quizId, []models.Question{question})
 	if err != nil {
 		ctrl.logger.Error("error occured while creating question by admin", zap.Error(err))
 		return utils.JSONError(c, http.StatusInternalServerError, err.Error())
@@ -292,7 +374,7 @@ func (ctrl *QuestionController) UpdateQuestionById(c *fiber.Ctx) error {
 		return utils.JSONFail(c, http.StatusBadRequest, utils.ValidatorErrorString(err))
 	}
 
-	_, err = ctrl.quizSvc.EditQuestionById(QuizId, QuestionId, models.Question{
+	question := models.Question{
 		Question:          questionReq.Question,
 		Type:              questionReq.Type,
 		Options:           questionReq.Options,
@@ -302,7 +384,14 @@ func (ctrl *QuestionController) UpdateQuestionById(c *fiber.Ctx) error {
 		QuestionMedia:     questionReq.QuestionMedia,
 		OptionsMedia:      questionReq.OptionsMedia,
 		Resource:          sql.NullString{String: questionReq.Resource, Valid: true},
-	})
+	}
+
+	err = checkAnswers(&question, questionReq.Grading, questionReq.ShortAnswer, questionReq.Numeric, questionReq.Matching)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	_, err = ctrl.quizSvc.EditQuestionById(QuizId, QuestionId, question)
 	if err != nil {
 		ctrl.logger.Error("error occured while update question by admin", zap.Error(err))
 		return utils.JSONError(c, http.StatusInternalServerError, err.Error())
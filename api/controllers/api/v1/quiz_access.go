@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Improwised/jovvix/api/constants"
+	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/utils"
+	fiber "github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// SetAccess to keep a session to the players who know its passcode or are on its allowlist.
+// swagger:route PUT /v1/quiz/sessions/{session_id}/access Quiz RequestSessionAccess
+//
+// Open the session to everybody with the code, or ask for a passcode, or only take registered users whose email or email domain is listed, only in the lobby.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseOkWithMessage
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *quizSocketController) SetAccess(c *fiber.Ctx) error {
+	session, ok, err := ctrl.getHostedLobbySession(c)
+	if !ok {
+		return err
+	}
+
+	var accessReq structs.ReqSessionAccess
+	err = json.Unmarshal(c.Body(), &accessReq)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	validate := validator.New()
+	err = validate.Struct(accessReq)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, utils.ValidatorErrorString(err))
+	}
+
+	passcodeHash := sql.NullString{}
+	entries := []string{}
+
+	switch accessReq.Mode {
+	case constants.SessionAccessPasscode:
+		if accessReq.Passcode == "" {
+			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrSessionAccess)
+		}
+
+		passcodeHash.String, err = models.HashPasscode(accessReq.Passcode)
+		if err != nil {
+			ctrl.logger.Error(constants.ErrSetSessionAccess, zap.Error(err))
+			return utils.JSONError(c, http.StatusInternalServerError, constants.ErrSetSessionAccess)
+		}
+		passcodeHash.Valid = true
+	case constants.SessionAccessAllowlist:
+		for _, raw := range accessReq.Allowlist {
+			entry, ok := quizUtilsHelper.AllowlistEntry(raw)
+			if !ok {
+				return utils.JSONFail(c, http.StatusBadRequest, constants.ErrSessionAccess)
+			}
+			entries = append(entries, entry)
+		}
+
+		if len(entries) == 0 {
+			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrSessionAccess)
+		}
+	}
+
+	err = ctrl.activeQuizModel.SetAccess(session.ID, accessReq.Mode, passcodeHash, entries)
+	if err != nil {
+		ctrl.logger.Error(constants.ErrSetSessionAccess, zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrSetSessionAccess)
+	}
+
+	return utils.JSONSuccess(c, http.StatusOK, "success")
+}
+
+// checkAccess tells why userId may not take part in a protected session, empty when they may. A join link
+// made past the passcode stands in for it. Only registered users have an email the allowlist can vouch for.
+func (ctrl *UserPlayedQuizeController) checkAccess(c *fiber.Ctx, session models.ActiveQuiz, userId string) (string, error) {
+	switch session.AccessMode {
+	case constants.SessionAccessPasscode:
+		request := structs.ReqPlayedQuizValidation{}
+		if len(c.Body()) > 0 {
+			if err := json.Unmarshal(c.Body(), &request); err != nil {
+				return constants.ErrPasscodeRequired, nil
+			}
+		}
+
+		if request.JoinLink != "" && joinLinkPassesPasscode(ctrl.appConfig, session, request.JoinLink) {
+			return "", nil
+		}
+		if request.Passcode == "" {
+			return constants.ErrPasscodeRequired, nil
+		}
+		if !session.CheckPasscode(request.Passcode) {
+			return constants.ErrPasscodeWrong, nil
+		}
+	case constants.SessionAccessAllowlist:
+		user, err := ctrl.userModel.GetById(userId)
+		if err != nil {
+			return "", err
+		}
+		if !user.KratosID.Valid {
+			return constants.ErrNotOnAllowlist, nil
+		}
+
+		entries, err := ctrl.activeQuizModel.GetAllowlist(session.ID)
+		if err != nil {
+			return "", err
+		}
+		if !quizUtilsHelper.IsAllowlisted(user.Email, entries) {
+			return constants.ErrNotOnAllowlist, nil
+		}
+	}
+
+	return "", nil
+}
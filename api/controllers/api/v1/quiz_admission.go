@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/hub"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/utils"
+	"github.com/gofiber/contrib/websocket"
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// admitPlayer lets a player into the session on the terms the host set, a player who is turned away
+// gets a fail event on the join socket and the caller closes it
+func (qc *quizSocketController) admitPlayer(client *hub.Client, session models.ActiveQuiz, userId string) bool {
+	credit, err := qc.userPlayedQuizModel.AdmitPlayer(userId, session.ID)
+	if err == nil {
+		qc.joinLeaderboard(session.ID.String(), userId, credit)
+		return true
+	}
+
+	response := QuizSendResponse{Component: constants.Waiting, Action: constants.ActionJoinQuiz}
+	switch {
+	case err.Error() == constants.ErrLobbyFull, err.Error() == constants.ErrLobbyLocked, err.Error() == constants.ErrLateJoinClosed:
+		response.Data = err.Error()
+		qc.logger.Info("player turned away from session", zap.String("session_id", session.ID.String()), zap.String("reason", err.Error()))
+	case err == sql.ErrNoRows:
+		response.Data = constants.ErrPlayerNotFound
+	default:
+		response.Data = constants.UnknownError
+		qc.logger.Error("error while admitting player", zap.Error(err))
+	}
+
+	wsErr := client.Fail(constants.EventJoinQuiz, response)
+	if wsErr != nil {
+		qc.logger.Error(fmt.Sprintf("socket error on join: %s event, %s action", constants.EventJoinQuiz, response.Action), zap.Error(wsErr))
+	}
+	return false
+}
+
+// lockLobby keeps the players who did not join yet out of the session, or lets them in again
+func (qc *quizSocketController) lockLobby(c *websocket.Conn, sessionId uuid.UUID, message QuizReceiveResponse, arrangeMu *sync.Mutex) {
+	send := func(frame func(*websocket.Conn, string, any) error, data any) {
+		err := func() error {
+			arrangeMu.Lock()
+			defer arrangeMu.Unlock()
+			return frame(c, constants.EventLockLobby, QuizSendResponse{Component: constants.Waiting, Action: constants.ActionLockLobby, Data: data})
+		}()
+		if err != nil {
+			qc.logger.Error(fmt.Sprintf("socket error send lobby lock: %s event", constants.EventLockLobby), zap.Error(err))
+		}
+	}
+
+	isLocked, ok := message.Data.(bool)
+	if !ok {
+		send(utils.JSONFailWs, constants.ErrLockLobby)
+		return
+	}
+
+	err := qc.activeQuizModel.SetLobbyLock(sessionId, isLocked)
+	if err != nil {
+		qc.logger.Error(constants.ErrLockLobby, zap.Error(err))
+		send(utils.JSONErrorWs, constants.ErrLockLobby)
+		return
+	}
+
+	send(utils.JSONSuccessWs, protocol.LobbyLock{Locked: isLocked})
+}
+
+// SetJoinPolicy to cap the players of a session and decide whether they may join once it started.
+// swagger:route PUT /v1/quiz/sessions/{session_id}/join_policy Quiz RequestSessionJoinPolicy
+//
+// Set the maximum players of the session and how players who join after the start are treated, only in the lobby.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseOkWithMessage
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *quizSocketController) SetJoinPolicy(c *fiber.Ctx) error {
+	session, ok, err := ctrl.getHostedLobbySession(c)
+	if !ok {
+		return err
+	}
+
+	var policyReq structs.ReqSessionJoinPolicy
+	err = json.Unmarshal(c.Body(), &policyReq)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	validate := validator.New()
+	err = validate.Struct(policyReq)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, utils.ValidatorErrorString(err))
+	}
+
+	// no maximum leaves the session open to everybody
+	maxPlayers := sql.NullInt32{Int32: int32(policyReq.MaxPlayers), Valid: policyReq.MaxPlayers > 0}
+
+	err = ctrl.activeQuizModel.SetJoinPolicy(session.ID, maxPlayers, *policyReq.AllowLateJoin, policyReq.LateJoinCredit)
+	if err != nil {
+		ctrl.logger.Error(constants.ErrSetJoinPolicy, zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrSetJoinPolicy)
+	}
+
+	return utils.JSONSuccess(c, http.StatusOK, "success")
+}
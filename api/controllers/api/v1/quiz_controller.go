@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/Improwised/jovvix/api/config"
 	"github.com/Improwised/jovvix/api/constants"
@@ -457,7 +458,7 @@ func (ctrl *QuizController) GenerateDemoSession(c *fiber.Ctx) error {
 		return utils.JSONError(c, http.StatusInternalServerError, err.Error())
 	}
 
-	sessionId, err := ctrl.activeQuizModel.CreateActiveQuiz(quiz.Title, quizId, userId, sql.NullTime{}, sql.NullTime{})
+	sessionId, err := ctrl.activeQuizModel.CreateActiveQuiz(quiz.Title, quizId, userId, sql.NullTime{}, sql.NullTime{}, constants.SessionModeLive)
 
 	if err != nil {
 		ctrl.logger.Error("error in creating demo session", zap.Error(err))
@@ -494,7 +495,7 @@ func (ctrl *QuizController) GeneratePublicSession(c *fiber.Ctx) error {
 		return utils.JSONFail(c, http.StatusForbidden, constants.ErrQuizNotPublic)
 	}
 
-	sessionId, err := ctrl.activeQuizModel.CreateActiveQuiz(quiz.Title, quizId, userId, sql.NullTime{}, sql.NullTime{})
+	sessionId, err := ctrl.activeQuizModel.CreateActiveQuiz(quiz.Title, quizId, userId, sql.NullTime{}, sql.NullTime{}, constants.SessionModeLive)
 	if err != nil {
 		ctrl.logger.Error("error in creating public session", zap.Error(err))
 		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrCreatingDemoQuiz)
@@ -509,6 +510,102 @@ func (ctrl *QuizController) GeneratePublicSession(c *fiber.Ctx) error {
 	return utils.JSONSuccess(c, http.StatusAccepted, sessionId)
 }
 
+// GenerateAssignmentSession to publish a quiz as a self-paced assignment.
+// swagger:route POST /v1/quizzes/{quiz_id}/assignment_session Quiz RequestGenerateAssignmentSession
+//
+// Publish a quiz as an assignment players can take at their own pace between opens_at and closes_at.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  202: ResponseGenerateAssignmentSession
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *QuizController) GenerateAssignmentSession(c *fiber.Ctx) error {
+	quizId := c.Params(constants.QuizId)
+	userId := quizUtilsHelper.GetString(c.Locals(constants.ContextUid))
+
+	var assignmentReq structs.ReqCreateAssignment
+	err := json.Unmarshal(c.Body(), &assignmentReq)
+	if err != nil {
+		ctrl.logger.Error("validate req error", zap.Error(err))
+		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	validate := validator.New()
+	err = validate.Struct(assignmentReq)
+	if err != nil {
+		ctrl.logger.Error("validate req error", zap.Any("assignmentReq", assignmentReq))
+		return utils.JSONFail(c, http.StatusBadRequest, utils.ValidatorErrorString(err))
+	}
+
+	quiz, err := ctrl.quizModel.GetQuizById(quizId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrQuizNotFound)
+		}
+		ctrl.logger.Error("error fetching quiz for assignment", zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	title := assignmentReq.Title
+	if title == "" {
+		title = quiz.Title
+	}
+
+	// an assignment without an opening time is open right away
+	opensAt := assignmentReq.OpensAt
+	if opensAt.IsZero() || opensAt.Before(time.Now()) {
+		opensAt = time.Now()
+	}
+
+	sessionId, err := ctrl.activeQuizModel.CreateActiveQuiz(title, quizId, userId,
+		sql.NullTime{Time: assignmentReq.ClosesAt.UTC(), Valid: true},
+		sql.NullTime{Time: opensAt.UTC(), Valid: true},
+		constants.SessionModeAssignment,
+	)
+	if err != nil {
+		if err.Error() == constants.ErrAssignmentWindow {
+			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrAssignmentWindow)
+		}
+		ctrl.logger.Error("error in creating assignment", zap.Error(err))
+		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrCreatingAssignment)
+	}
+
+	err = ctrl.activeQuizModel.GetQuestionsCopy(sessionId, quizId)
+	if err != nil {
+		ctrl.logger.Error("error in creating assignment questions", zap.Error(err))
+		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrCreatingAssignment)
+	}
+
+	if assignmentReq.ShuffleQuestions || assignmentReq.ShuffleOptions {
+		err = ctrl.activeQuizModel.SetShuffle(sessionId, assignmentReq.ShuffleQuestions, assignmentReq.ShuffleOptions)
+		if err != nil {
+			ctrl.logger.Error("error in saving assignment shuffle settings", zap.Error(err))
+			return utils.JSONError(c, http.StatusInternalServerError, constants.ErrCreatingAssignment)
+		}
+	}
+
+	session, err := ctrl.activeQuizModel.OpenAssignment(sessionId, userId)
+	if err != nil {
+		ctrl.logger.Error("error in opening assignment", zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrCreatingAssignment)
+	}
+
+	return utils.JSONSuccess(c, http.StatusAccepted, models.ActiveSessionSummary{
+		ID:             session.ID,
+		Title:          session.Title,
+		QuizID:         session.QuizID,
+		InvitationCode: &session.InvitationCode.Int32,
+		ActivatedFrom:  &session.ActivatedFrom.Time,
+		ActivatedTo:    &session.ActivatedTo.Time,
+		Mode:           session.Mode,
+	})
+}
+
 // DeleteQuizById to delete quiz that created by user (if no active quiz is present).
 // swagger:route DELETE /v1/quizzes/{quiz_id} Quiz DeleteQuizById
 //
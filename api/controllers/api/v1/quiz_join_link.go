@@ -0,0 +1,199 @@
+package v1
+
+import (
+	"crypto/hmac"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Improwised/jovvix/api/config"
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/joinlink"
+	"github.com/Improwised/jovvix/api/pkg/qrcode"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/utils"
+	fiber "github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// joinLinkExpiry is when the sweeper terminates the session at the latest. Codes are handed out again
+// once a session is over, the expiry tells the session a link was made for from a later one.
+func joinLinkExpiry(session models.ActiveQuiz, ttl time.Duration) time.Time {
+	if !session.ActivatedFrom.Valid {
+		return time.Now().Add(ttl).Truncate(time.Second)
+	}
+	return session.ActivatedFrom.Time.Add(ttl).Truncate(time.Second)
+}
+
+// joinLink returns the signed short link to the join page of the live session, it expires with it. A link
+// past the passcode carries a grant of the passcode set now, never the passcode.
+func (qc *quizSocketController) joinLink(session models.ActiveQuiz, pastPasscode bool) (string, time.Time) {
+	link := joinlink.Link{Code: int(session.InvitationCode.Int32), ExpiresAt: joinLinkExpiry(session, qc.appConfig.Quiz.ActiveQuizTTL())}
+	if pastPasscode {
+		link.Grant = joinlink.Grant(qc.appConfig.Secret, session.PasscodeHash.String)
+	}
+
+	return fmt.Sprintf("%s/%s", qc.appConfig.Quiz.JoinLinkBase(), joinlink.Sign(qc.appConfig.Secret, link)), link.ExpiresAt
+}
+
+// joinLinkPassesPasscode reports whether token is a live join link of session made past its passcode,
+// the grant is void once the host changed the passcode
+func joinLinkPassesPasscode(appConfig *config.AppConfig, session models.ActiveQuiz, token string) bool {
+	link, err := joinlink.Parse(appConfig.Secret, token, time.Now())
+	if err != nil || len(link.Grant) == 0 || !session.PasscodeHash.Valid || link.Code != int(session.InvitationCode.Int32) {
+		return false
+	}
+	// the code went to another session since the link was made
+	if session.ActivatedFrom.Valid && !joinLinkExpiry(session, appConfig.Quiz.ActiveQuizTTL()).Equal(link.ExpiresAt) {
+		return false
+	}
+
+	return hmac.Equal(link.Grant, joinlink.Grant(appConfig.Secret, session.PasscodeHash.String))
+}
+
+// getSharedJoinLink returns the join link of the session of the host, with the passcode they asked for
+func (ctrl *quizSocketController) getSharedJoinLink(c *fiber.Ctx) (structs.ResJoinLink, bool, error) {
+	session, ok, err := ctrl.getHostedSession(c)
+	if !ok {
+		return structs.ResJoinLink{}, false, err
+	}
+
+	if !session.InvitationCode.Valid {
+		return structs.ResJoinLink{}, false, utils.JSONFail(c, http.StatusBadRequest, constants.ErrInvitationCodeNotFound)
+	}
+
+	// a wrong passcode would only lead players to a refusal
+	passcode := c.Query(constants.PasscodeQueryParam)
+	if passcode != "" && (session.AccessMode != constants.SessionAccessPasscode || !session.CheckPasscode(passcode)) {
+		return structs.ResJoinLink{}, false, utils.JSONFail(c, http.StatusBadRequest, constants.ErrPasscodeWrong)
+	}
+
+	link := structs.ResJoinLink{InvitationCode: int(session.InvitationCode.Int32)}
+	link.URL, link.ExpiresAt = ctrl.joinLink(session, passcode != "")
+
+	return link, true, nil
+}
+
+// GetJoinLink to share a session through a short link instead of its code.
+// swagger:route GET /v1/quiz/sessions/{session_id}/join_link Quiz RequestJoinLink
+//
+// Get a signed short link taking players to the join page of the session, optionally past its passcode.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseJoinLink
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *quizSocketController) GetJoinLink(c *fiber.Ctx) error {
+	link, ok, err := ctrl.getSharedJoinLink(c)
+	if !ok {
+		return err
+	}
+
+	return utils.JSONSuccess(c, http.StatusOK, link)
+}
+
+// GetJoinLinkQR to show the join link of a session as a QR code.
+// swagger:route GET /v1/quiz/sessions/{session_id}/join_link/qr Quiz RequestJoinLinkQR
+//
+// Get the QR code of the join link of the session as png or svg.
+//
+//		Produces:
+//		- image/png
+//		- image/svg+xml
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: description:QR code image
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *quizSocketController) GetJoinLinkQR(c *fiber.Ctx) error {
+	format := c.Query(constants.FormatQueryParam, constants.QRFormatPNG)
+	if format != constants.QRFormatPNG && format != constants.QRFormatSVG {
+		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrQRFormat)
+	}
+
+	link, ok, err := ctrl.getSharedJoinLink(c)
+	if !ok {
+		return err
+	}
+
+	code, err := qrcode.Encode(link.URL)
+	if err != nil {
+		ctrl.logger.Error(constants.ErrJoinLink, zap.String("url", link.URL), zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrJoinLink)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if format == constants.QRFormatSVG {
+		c.Set(fiber.HeaderContentType, "image/svg+xml")
+		return c.Send(code.SVG())
+	}
+
+	scale, err := strconv.Atoi(c.Query(constants.ScaleQueryParam))
+	if err != nil || scale < 1 || scale > constants.MaxQRScale {
+		scale = constants.DefaultQRScale
+	}
+
+	image, err := code.PNG(scale)
+	if err != nil {
+		ctrl.logger.Error(constants.ErrJoinLink, zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrJoinLink)
+	}
+
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.Send(image)
+}
+
+// OpenJoinLink to follow a join link.
+// swagger:route GET /v1/j/{token} Quiz RequestOpenJoinLink
+//
+// Redirect to the join page of the session of the link with the code, and the link if it skips the passcode.
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  302: description:redirect to the join page, the passcode is never put in it
+func (ctrl *quizSocketController) OpenJoinLink(c *fiber.Ctx) error {
+	query := url.Values{}
+
+	link, err := joinlink.Parse(ctrl.appConfig.Secret, c.Params(constants.JoinLinkTokenParam), time.Now())
+	switch {
+	case err == joinlink.ErrExpired:
+		query.Set("error", constants.ErrJoinLinkExpired)
+	case err != nil:
+		query.Set("error", constants.ErrJoinLinkInvalid)
+	default:
+		session, err := ctrl.activeQuizModel.GetSessionByCode(strconv.Itoa(link.Code))
+		if err != nil {
+			if err != sql.ErrNoRows {
+				ctrl.logger.Error("error while getting session of join link", zap.Error(err))
+			}
+			query.Set("error", constants.ErrInvitationCodeNotFound)
+			break
+		}
+
+		// the code went to another session since the link was made
+		if session.ActivatedFrom.Valid && !joinLinkExpiry(session, ctrl.appConfig.Quiz.ActiveQuizTTL()).Equal(link.ExpiresAt) {
+			query.Set("error", constants.ErrInvitationCodeNotFound)
+			break
+		}
+
+		// the join page sends the link back in place of the passcode, the grant is checked there
+		query.Set("code", strconv.Itoa(link.Code))
+		if len(link.Grant) > 0 {
+			query.Set(constants.JoinLinkQueryParam, c.Params(constants.JoinLinkTokenParam))
+		}
+	}
+
+	return c.Redirect(fmt.Sprintf("%s/join?%s", ctrl.appConfig.WebUrl, query.Encode()), http.StatusFound)
+}
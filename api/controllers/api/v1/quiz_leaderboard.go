@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"cmp"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/pubsub"
+	"github.com/Improwised/jovvix/api/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// The leaderboard of a session keeps the running totals of its players as the answers come in, so the
+// scoreboard after a question does not sum every response of the session again. Players are put on it
+// as they are admitted, with the score a late joiner is credited with. Postgres stays the source of truth:
+// the leaderboard is rebuilt from it whenever it misses a player, which only happens once it was lost,
+// and checked against it at the end.
+
+func leaderboardKey(sessionId string) string {
+	return fmt.Sprintf("%s-%s", constants.KeyLeaderboard, sessionId)
+}
+
+// scoreOnLeaderboard adds a submitted answer to the totals of the player. The player is taken off the
+// leaderboard when that fails, the next scoreboard rebuilds it instead of showing totals without the answer.
+func (qc *quizSocketController) scoreOnLeaderboard(sessionId string, userId string, score int, points sql.NullInt16) {
+	err := qc.pubsub.ScoreOnBoard(leaderboardKey(sessionId), userId, int64(score), int64(points.Int16), time.Minute*100)
+	if err == nil {
+		return
+	}
+
+	qc.logger.Error("error while scoring on the leaderboard", zap.String("session_id", sessionId), zap.Error(err))
+	if err := qc.pubsub.LeaveBoard(leaderboardKey(sessionId), userId); err != nil {
+		qc.logger.Error("error while taking the player off the leaderboard", zap.String("session_id", sessionId), zap.String("user_id", userId), zap.Error(err))
+	}
+}
+
+// joinLeaderboard puts an admitted player on the leaderboard with the score credited for the questions
+// they missed, so a player without answers yet does not look like a lost leaderboard and a late joiner
+// starts from what Postgres has for them
+func (qc *quizSocketController) joinLeaderboard(sessionId string, userId string, credit int64) {
+	if err := qc.pubsub.JoinBoard(leaderboardKey(sessionId), userId, credit, 0, time.Minute*100); err != nil {
+		qc.logger.Error("error while putting the player on the leaderboard", zap.String("session_id", sessionId), zap.String("user_id", userId), zap.Error(err))
+		// without the credit the totals of the player are wrong, the next scoreboard rebuilds them instead
+		if err := qc.pubsub.LeaveBoard(leaderboardKey(sessionId), userId); err != nil {
+			qc.logger.Error("error while taking the player off the leaderboard", zap.String("session_id", sessionId), zap.String("user_id", userId), zap.Error(err))
+		}
+	}
+}
+
+// rankBoard ranks the players of the session after a question by their totals, with how many places
+// each of them moved with it
+func (qc *quizSocketController) rankBoard(sessionId uuid.UUID, questionId uuid.UUID) ([]models.UserRank, error) {
+	standings, err := qc.userPlayedQuizModel.GetQuestionStandings(sessionId, questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := qc.leaderboardTotals(sessionId, standings)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]int64, len(standings))
+	previousScores := make([]int64, len(standings))
+	hadScores := false
+	for i, standing := range standings {
+		scores[i] = totals[standing.UserID].Score
+		previousScores[i] = scores[i] - int64(standing.QuestionScore)
+		hadScores = hadScores || previousScores[i] != 0
+	}
+	ranks := utils.DenseRanks(scores)
+	previousRanks := utils.DenseRanks(previousScores)
+
+	board := make([]models.UserRank, 0, len(standings))
+	for i, standing := range standings {
+		rank := models.UserRank{
+			Rank:         ranks[i],
+			Points:       int(totals[standing.UserID].Points),
+			Score:        int(scores[i]),
+			ResponseTime: standing.ResponseTime,
+			UserName:     standing.UserName,
+			FirstName:    standing.FirstName,
+			ImageKey:     standing.ImageKey,
+			StreakCount:  standing.StreakCount,
+		}
+		// nobody moves on the first question that was scored
+		if hadScores {
+			rank.RankDelta = previousRanks[i] - ranks[i]
+		}
+		board = append(board, rank)
+	}
+	slices.SortStableFunc(board, func(a, b models.UserRank) int {
+		return cmp.Compare(a.Rank, b.Rank)
+	})
+
+	return board, nil
+}
+
+// leaderboardTotals returns the totals of the leaderboard by user id, rebuilt from the responses when a
+// player of standings is not on it. That is the case after the leaderboard expired or was lost with redis,
+// or after scoring an answer on it failed.
+func (qc *quizSocketController) leaderboardTotals(sessionId uuid.UUID, standings []models.QuestionStanding) (map[string]pubsub.BoardEntry, error) {
+	key := leaderboardKey(sessionId.String())
+
+	board, err := qc.pubsub.Board(key)
+	if err != nil {
+		qc.logger.Error("error while getting the leaderboard", zap.String("session_id", sessionId.String()), zap.Error(err))
+	}
+	totals := map[string]pubsub.BoardEntry{}
+	for _, entry := range board {
+		totals[entry.Member] = entry
+	}
+
+	isComplete := err == nil && !slices.ContainsFunc(standings, func(standing models.QuestionStanding) bool {
+		_, ok := totals[standing.UserID]
+		return !ok
+	})
+	if isComplete {
+		return totals, nil
+	}
+
+	sessionTotals, err := qc.userPlayedQuizModel.GetSessionTotals(sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	totals = map[string]pubsub.BoardEntry{}
+	entries := make([]pubsub.BoardEntry, 0, len(sessionTotals))
+	for _, total := range sessionTotals {
+		entry := pubsub.BoardEntry{Member: total.UserID, Score: total.Score, Points: total.Points}
+		totals[total.UserID] = entry
+		entries = append(entries, entry)
+	}
+	if err := qc.pubsub.ReplaceBoard(key, entries, time.Minute*100); err != nil {
+		qc.logger.Error("error while rebuilding the leaderboard", zap.String("session_id", sessionId.String()), zap.Error(err))
+	}
+
+	return totals, nil
+}
+
+// closeLeaderboard checks the leaderboard of a session that is over against the responses and drops it,
+// a mismatch means answers were scored on one side only
+func (qc *quizSocketController) closeLeaderboard(sessionId uuid.UUID) {
+	key := leaderboardKey(sessionId.String())
+	defer func() {
+		if err := qc.pubsub.DeleteBoard(key); err != nil {
+			qc.logger.Error("error while dropping the leaderboard", zap.String("session_id", sessionId.String()), zap.Error(err))
+		}
+	}()
+
+	board, err := qc.pubsub.Board(key)
+	if err != nil || len(board) == 0 {
+		return
+	}
+	sessionTotals, err := qc.userPlayedQuizModel.GetSessionTotals(sessionId)
+	if err != nil {
+		qc.logger.Error("error while getting the totals to reconcile the leaderboard", zap.String("session_id", sessionId.String()), zap.Error(err))
+		return
+	}
+
+	totals := map[string]pubsub.BoardEntry{}
+	for _, entry := range board {
+		totals[entry.Member] = entry
+	}
+	for _, total := range sessionTotals {
+		entry, ok := totals[total.UserID]
+		if !ok && total.Score == 0 && total.Points == 0 {
+			continue
+		}
+		if entry.Score != total.Score || entry.Points != total.Points {
+			qc.logger.Warn("leaderboard does not match the responses", zap.String("session_id", sessionId.String()), zap.String("user_id", total.UserID), zap.Int64("board_score", entry.Score), zap.Int64("score", total.Score), zap.Int64("board_points", entry.Points), zap.Int64("points", total.Points))
+		}
+	}
+}
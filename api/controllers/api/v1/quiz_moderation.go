@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/hub"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/utils"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+const maxDisplayNameLength = 50
+
+// moderationNotice tells the sockets of a player what the host did to them
+type moderationNotice struct {
+	Event  string `json:"event"`
+	UserId string `json:"user_id"`
+	Name   string `json:"name,omitempty"`
+}
+
+func isModerationEvent(event string) bool {
+	return event == constants.EventKickPlayer || event == constants.EventBanPlayer || event == constants.EventRenamePlayer
+}
+
+func moderationAction(event string) string {
+	switch event {
+	case constants.EventKickPlayer:
+		return constants.ActionKickPlayer
+	case constants.EventBanPlayer:
+		return constants.ActionBanPlayer
+	}
+
+	return constants.ActionRenamePlayer
+}
+
+// moderatePlayer applies a kick, ban or rename sent by the host, in the lobby or while the quiz runs
+func (qc *quizSocketController) moderatePlayer(c *websocket.Conn, sessionId uuid.UUID, message QuizReceiveResponse, arrangeMu *sync.Mutex) {
+	request := structs.ReqModeratePlayer{}
+
+	fail := func(status string, userId string) {
+		data := QuizSendResponse{Component: constants.Waiting, Action: moderationAction(message.Event), Data: protocol.Moderation{UserID: userId, Message: status}}
+
+		err := func() error {
+			arrangeMu.Lock()
+			defer arrangeMu.Unlock()
+			if status == constants.ErrModeratePlayer {
+				return utils.JSONErrorWs(c, message.Event, data)
+			}
+			return utils.JSONFailWs(c, message.Event, data)
+		}()
+		if err != nil {
+			qc.logger.Error(fmt.Sprintf("socket error send moderation failure: %s event", message.Event), zap.Error(err))
+		}
+	}
+
+	raw, err := json.Marshal(message.Data)
+	if err == nil {
+		err = json.Unmarshal(raw, &request)
+	}
+	if err == nil {
+		err = validator.New().Struct(request)
+	}
+	if err != nil {
+		fail(constants.ErrPlayerNotFound, request.UserId)
+		return
+	}
+
+	switch message.Event {
+	case constants.EventKickPlayer:
+		err = qc.userPlayedQuizModel.RemovePlayer(request.UserId, sessionId)
+	case constants.EventBanPlayer:
+		err = qc.userPlayedQuizModel.BanPlayer(request.UserId, sessionId)
+	case constants.EventRenamePlayer:
+		request.Name = strings.TrimSpace(request.Name)
+		if request.Name == "" || utf8.RuneCountInString(request.Name) > maxDisplayNameLength {
+			fail(constants.ErrDisplayName, request.UserId)
+			return
+		}
+		err = qc.userPlayedQuizModel.RenamePlayer(request.UserId, sessionId, request.Name)
+	}
+	if err != nil {
+		if err.Error() == constants.ErrPlayerNotFound {
+			fail(constants.ErrPlayerNotFound, request.UserId)
+			return
+		}
+		qc.logger.Error(constants.ErrModeratePlayer, zap.String("event", message.Event), zap.Error(err))
+		fail(constants.ErrModeratePlayer, request.UserId)
+		return
+	}
+
+	moderateRoster(qc, sessionId.String(), request.UserId, message.Event, request.Name)
+
+	// the answers of a kicked player are gone, their totals go with them in case they join again
+	if message.Event == constants.EventKickPlayer {
+		if err := qc.pubsub.LeaveBoard(leaderboardKey(sessionId.String()), request.UserId); err != nil {
+			qc.logger.Error("error while taking the player off the leaderboard", zap.String("session_id", sessionId.String()), zap.String("user_id", request.UserId), zap.Error(err))
+		}
+	}
+
+	notice, err := json.Marshal(moderationNotice{Event: message.Event, UserId: request.UserId, Name: request.Name})
+	if err != nil {
+		qc.logger.Error("error while marshaling moderation notice", zap.Error(err))
+	} else {
+		err = qc.pubsub.Publish(fmt.Sprintf("%s-%s", constants.ChannelModeration, sessionId), notice)
+		if err != nil {
+			qc.logger.Error(fmt.Sprintf("socket error publishing event: %s event", message.Event), zap.Error(err))
+		}
+	}
+
+	// the team roster shows the new name or loses the player
+	if session, err := qc.activeQuizModel.GetSession(sessionId.String()); err == nil && session.HasTeams() {
+		publishTeamRoster(qc, sessionId)
+	}
+
+	err = func() error {
+		arrangeMu.Lock()
+		defer arrangeMu.Unlock()
+		return utils.JSONSuccessWs(c, message.Event, QuizSendResponse{Component: constants.Waiting, Action: moderationAction(message.Event), Data: protocol.Moderation{UserID: request.UserId, Name: request.Name}})
+	}()
+	if err != nil {
+		qc.logger.Error(fmt.Sprintf("socket error send moderation ack: %s event", message.Event), zap.Error(err))
+	}
+}
+
+// moderateRoster renames a player in the lobby roster or drops them from it, and shares the change
+func moderateRoster(qc *quizSocketController, sessionId string, userId string, event string, name string) {
+	var err error
+	if event == constants.EventRenamePlayer {
+		err = qc.renameInRoster(sessionId, userId, name)
+	} else {
+		_, err = qc.leaveRoster(sessionId, userId)
+	}
+	if err != nil {
+		qc.logger.Error("error updating the lobby in moderateRoster", zap.Error(err))
+	}
+}
+
+// handleModerationNotice passes a notice meant for this player to their socket, it reports
+// false once the player was kicked or banned and the socket has to close
+func handleModerationNotice(client *hub.Client, qc *quizSocketController, userId string, payload string) bool {
+	notice := moderationNotice{}
+	if err := json.Unmarshal([]byte(payload), &notice); err != nil {
+		qc.logger.Error("error while unmarshaling moderation notice", zap.Error(err))
+		return true
+	}
+
+	if notice.UserId != userId {
+		return true
+	}
+
+	response := QuizSendResponse{Component: constants.Waiting, Action: moderationAction(notice.Event)}
+
+	err := func() error {
+		switch notice.Event {
+		case constants.EventRenamePlayer:
+			response.Data = protocol.Renamed{Name: notice.Name}
+			return client.Send(notice.Event, response)
+		case constants.EventBanPlayer:
+			response.Data = constants.ErrPlayerBanned
+		default:
+			response.Data = constants.ErrPlayerKicked
+		}
+		return client.Fail(notice.Event, response)
+	}()
+	if err != nil {
+		qc.logger.Error(fmt.Sprintf("socket error send moderation notice: %s event", notice.Event), zap.Error(err))
+	}
+
+	return notice.Event == constants.EventRenamePlayer
+}
@@ -0,0 +1,266 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/pkg/textmatch"
+	"github.com/Improwised/jovvix/api/pkg/wordcloud"
+	"github.com/Improwised/jovvix/api/utils"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// frameWriter writes success frames to a socket, one write at a time. Players write through their hub
+// client, the host and the spectators through lockedSocket.
+type frameWriter interface {
+	Send(event string, data any) error
+}
+
+// lockedSocket writes to a socket under the mutex its writers share
+type lockedSocket struct {
+	c  *websocket.Conn
+	mu *sync.Mutex
+}
+
+func (s lockedSocket) Send(event string, data any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return utils.JSONSuccessWs(s.c, event, data)
+}
+
+// sendHello greets a socket that negotiated v2 or later with its version and the events it may get
+func sendHello(c *websocket.Conn, qc *quizSocketController, socket string, w frameWriter) {
+	version, ok := c.Locals(constants.ProtocolVersion).(int)
+	if !ok || version < protocol.V2 {
+		return
+	}
+
+	versions := []int{}
+	for v := protocol.V1; v <= protocol.Latest; v++ {
+		versions = append(versions, v)
+	}
+
+	err := w.Send(constants.EventHello, QuizSendResponse{
+		Component: constants.Waiting,
+		Action:    constants.ActionHello,
+		Data:      protocol.Hello{Version: version, Versions: versions, Events: protocol.EventNames(version, socket)},
+	})
+	if err != nil {
+		qc.logger.Error(fmt.Sprintf("socket error send hello: %s event, %s socket", constants.EventHello, socket), zap.Error(err))
+	}
+}
+
+func protocolRanks(ranks []models.UserRank) []protocol.Rank {
+	converted := make([]protocol.Rank, 0, len(ranks))
+	for _, rank := range ranks {
+		converted = append(converted, protocol.Rank{
+			Rank:         rank.Rank,
+			Points:       rank.Points,
+			Score:        rank.Score,
+			ResponseTime: rank.ResponseTime,
+			UserName:     rank.UserName,
+			FirstName:    rank.FirstName,
+			ImageKey:     rank.ImageKey,
+			StreakCount:  rank.StreakCount,
+			RankDelta:    rank.RankDelta,
+		})
+	}
+
+	return converted
+}
+
+// shortAnswerRules returns the rules of a short answer question, nil for the other types
+func shortAnswerRules(questionType int, answerRules []byte) (*structs.ShortAnswerRules, error) {
+	if questionType != constants.ShortAnswer || len(answerRules) == 0 {
+		return nil, nil
+	}
+
+	rules := &structs.ShortAnswerRules{}
+	if err := json.Unmarshal(answerRules, rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// matchingRules returns the definitions and right pairs of a matching question, nil for the other types
+func matchingRules(questionType int, answerRules []byte) (*structs.MatchingRules, error) {
+	if questionType != constants.Matching || len(answerRules) == 0 {
+		return nil, nil
+	}
+
+	rules := &structs.MatchingRules{}
+	if err := json.Unmarshal(answerRules, rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// protocolNumericAnswer returns the expected value of a numeric question, nil for the other types
+func protocolNumericAnswer(questionType int, answerRules []byte) (*protocol.NumericAnswer, error) {
+	if questionType != constants.Numeric || len(answerRules) == 0 {
+		return nil, nil
+	}
+
+	rules := structs.NumericRules{}
+	if err := json.Unmarshal(answerRules, &rules); err != nil {
+		return nil, err
+	}
+
+	low, high := utils.NumericRange(rules)
+	return &protocol.NumericAnswer{Target: rules.Target, Min: low, Max: high, Unit: rules.Unit}, nil
+}
+
+// wordCloudOptions are the words masked in the clouds of this server and how many words a cloud shows
+func (qc *quizSocketController) wordCloudOptions() wordcloud.Options {
+	return wordcloud.Options{
+		MaskedWords: qc.appConfig.Quiz.WordCloudMaskedWords,
+		Mask:        qc.appConfig.Quiz.CloudMask(),
+		MaxWords:    constants.MaxCloudWords,
+	}
+}
+
+// protocolWordCloud counts the words of the phrases typed to a word cloud question
+func protocolWordCloud(questionID uuid.UUID, responses []models.UsersQustionResponse, options wordcloud.Options) *protocol.WordCloud {
+	texts := []string{}
+	for _, response := range responses {
+		if response.AnswerText.Valid && strings.TrimSpace(response.AnswerText.String) != "" {
+			texts = append(texts, response.AnswerText.String)
+		}
+	}
+
+	return &protocol.WordCloud{QuestionID: questionID, Responses: len(texts), Words: wordcloud.Count(texts, options)}
+}
+
+// protocolWrongAnswers groups the typed answers the rules turned down, so the host sees the common mistakes
+func protocolWrongAnswers(rules *structs.ShortAnswerRules, responses []models.UsersQustionResponse) []protocol.AnswerGroup {
+	wrong := []string{}
+	for _, response := range responses {
+		if !response.AnswerText.Valid {
+			continue
+		}
+		if matched, _ := textmatch.Match(response.AnswerText.String, rules.Accepted, rules.Rules); !matched {
+			wrong = append(wrong, response.AnswerText.String)
+		}
+	}
+
+	groups := textmatch.GroupAnswers(wrong, rules.Rules)
+	converted := make([]protocol.AnswerGroup, 0, len(groups))
+	for _, group := range groups {
+		converted = append(converted, protocol.AnswerGroup{Answer: group.Answer, Count: group.Count})
+	}
+
+	return converted
+}
+
+func protocolTeamRanks(ranks []models.TeamRank) []protocol.TeamRank {
+	converted := make([]protocol.TeamRank, 0, len(ranks))
+	for _, rank := range ranks {
+		converted = append(converted, protocol.TeamRank{
+			Rank:    rank.Rank,
+			TeamID:  rank.TeamID,
+			Name:    rank.Name,
+			Members: rank.Members,
+			Score:   rank.Score,
+		})
+	}
+
+	return converted
+}
+
+func protocolTeams(rosters []models.TeamRoster) []protocol.Team {
+	converted := make([]protocol.Team, 0, len(rosters))
+	for _, roster := range rosters {
+		members := make([]protocol.TeamMember, 0, len(roster.Members))
+		for _, member := range roster.Members {
+			members = append(members, protocol.TeamMember{UserID: member.UserID, FirstName: member.FirstName, ImageKey: member.ImageKey})
+		}
+		converted = append(converted, protocol.Team{ID: roster.ID, Name: roster.Name, Members: members})
+	}
+
+	return converted
+}
+
+func protocolResponses(responses []models.UsersQustionResponse) []protocol.PlayerResponse {
+	converted := make([]protocol.PlayerResponse, 0, len(responses))
+	for _, response := range responses {
+		converted = append(converted, protocol.PlayerResponse{
+			UserID:  response.UserId,
+			Answers: protocol.NullString{String: response.Answers.String, Valid: response.Answers.Valid},
+			Text:    response.AnswerText.String,
+			Value:   nullFloat(response.AnswerValue),
+			Pairs:   answerPairs(response.AnswerPairs),
+		})
+	}
+
+	return converted
+}
+
+// nullFloat leaves a missing value out of the payload
+func nullFloat(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+// answerPairs decodes the pairs stored for a matching answer, nil for the other types
+func answerPairs(value sql.NullString) map[string]int {
+	if !value.Valid {
+		return nil
+	}
+
+	pairs := map[string]int{}
+	if err := json.Unmarshal([]byte(value.String), &pairs); err != nil {
+		return nil
+	}
+	return pairs
+}
+
+// playerMessage is a frame published to the players of a session
+type playerMessage struct {
+	Event    string           `json:"event"`
+	Response QuizSendResponse `json:"response"`
+}
+
+// UnmarshalJSON types the questions and scoreboards, the payloads a player rewrites before sending them
+func (m *playerMessage) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Event    string `json:"event"`
+		Response struct {
+			Component string          `json:"component"`
+			Action    string          `json:"action"`
+			Data      json.RawMessage `json:"data"`
+		} `json:"response"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	m.Event = raw.Event
+	m.Response = QuizSendResponse{Component: raw.Response.Component, Action: raw.Response.Action}
+	if len(raw.Response.Data) == 0 {
+		return nil
+	}
+
+	switch raw.Event {
+	case constants.EventSendQuestion:
+		question := &protocol.Question{}
+		m.Response.Data = question
+		return json.Unmarshal(raw.Response.Data, question)
+	case constants.EventShowScore:
+		scoreboard := &protocol.Scoreboard{}
+		m.Response.Data = scoreboard
+		return json.Unmarshal(raw.Response.Data, scoreboard)
+	}
+
+	return json.Unmarshal(raw.Response.Data, &m.Response.Data)
+}
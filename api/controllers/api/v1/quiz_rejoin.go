@@ -0,0 +1,305 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Improwised/jovvix/api/constants"
+	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// rejoinClaim is stored in redis against a rejoin token
+type rejoinClaim struct {
+	SessionId string `json:"session_id"`
+	UserId    string `json:"user_id"`
+}
+
+func rejoinTokenKey(token string) string {
+	return fmt.Sprintf("%s-%s", constants.KeyRejoinToken, token)
+}
+
+// isValidRejoinToken reports whether token was issued to userId for the session
+func (qc *quizSocketController) isValidRejoinToken(token string, sessionId string, userId string) bool {
+	if token == "" {
+		return false
+	}
+
+	data, err := qc.pubsub.Get(rejoinTokenKey(token))
+	if err != nil {
+		return false
+	}
+
+	claim := rejoinClaim{}
+	if err := json.Unmarshal([]byte(data), &claim); err != nil {
+		qc.logger.Error("error while unmarshaling rejoin token", zap.Error(err))
+		return false
+	}
+
+	return claim.SessionId == sessionId && claim.UserId == userId
+}
+
+// issueRejoinToken stores a new rejoin token for userId, it lives as long as the session roster
+func (qc *quizSocketController) issueRejoinToken(sessionId string, userId string) (string, error) {
+	token := quizUtilsHelper.GenerateRandomString(32)
+
+	data, err := json.Marshal(rejoinClaim{SessionId: sessionId, UserId: userId})
+	if err != nil {
+		return "", err
+	}
+
+	err = qc.pubsub.Set(rejoinTokenKey(token), data, time.Minute*100)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// currentQuestionPayload builds the send_question data of the running question, it reports false once the time is up
+func currentQuestionPayload(qc *quizSocketController, session models.ActiveQuiz) (*protocol.Question, bool, error) {
+	totalQuestion, err := qc.questionModel.GetTotalQuestionCount(session.ID.String())
+	if err != nil {
+		return nil, false, err
+	}
+
+	questionID, err := uuid.Parse(session.CurrentQuestion.String)
+	if err != nil {
+		return nil, false, err
+	}
+
+	currentQuestion, err := qc.questionModel.GetCurrentQuestion(questionID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	remainingSeconds := currentQuestion.DurationInSeconds - int(time.Since(session.QuestionDeliveryTime.Time).Seconds())
+	if remainingSeconds < 0 {
+		return nil, false, nil
+	}
+
+	return &protocol.Question{
+		ID:             currentQuestion.ID,
+		No:             currentQuestion.OrderNumber,
+		Duration:       currentQuestion.DurationInSeconds,
+		StartTime:      session.QuestionDeliveryTime.Time.Format(time.RFC3339),
+		ServerTime:     time.Now().UTC().Format(time.RFC3339Nano),
+		Question:       currentQuestion.Question,
+		Type:           currentQuestion.Type,
+		Options:        currentQuestion.Options,
+		TotalQuestions: totalQuestion,
+		QuestionMedia:  currentQuestion.QuestionMedia,
+		OptionsMedia:   currentQuestion.OptionsMedia,
+		Resource:       currentQuestion.Resource.String,
+		Unit:           currentQuestion.Unit.String,
+		Definitions:    currentQuestion.Definitions,
+	}, true, nil
+}
+
+// scoreboardPayload builds the show_score data of the last finished question for players
+func scoreboardPayload(qc *quizSocketController, session models.ActiveQuiz, questionID uuid.UUID) (*protocol.Scoreboard, []models.UserRank, error) {
+	totalQuestion, err := qc.questionModel.GetTotalQuestionCount(session.ID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	question, err := qc.questionModel.GetCurrentQuestion(questionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	answerKey, err := qc.questionModel.GetAnswerKey(questionID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rules, err := shortAnswerRules(answerKey.Type, answerKey.AnswerRules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userRankBoard, err := qc.rankBoard(session.ID, questionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	teamRankBoard, err := qc.sessionTeamModel.GetTeamRank(session.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	duration := 0
+	state := session.SessionState()
+	if state.IsPaused {
+		duration = int(state.PausedRemaining.Seconds())
+	} else if state.Deadline.Valid {
+		duration = max(int(time.Until(state.Deadline.Time).Seconds()), 0)
+	}
+
+	scoreboard := &protocol.Scoreboard{
+		QuestionID:     &questionID,
+		QuestionNo:     question.OrderNumber,
+		RankList:       protocolRanks(userRankBoard),
+		TeamRankList:   protocolTeamRanks(teamRankBoard),
+		Question:       question.Question,
+		Answers:        answerKey.Answers,
+		Options:        question.Options,
+		QuestionMedia:  question.QuestionMedia,
+		OptionsMedia:   question.OptionsMedia,
+		Resource:       question.Resource.String,
+		Duration:       duration,
+		TotalQuestions: totalQuestion,
+	}
+	if rules != nil {
+		scoreboard.AcceptedAnswers = rules.Accepted
+	}
+	scoreboard.NumericAnswer, err = protocolNumericAnswer(answerKey.Type, answerKey.AnswerRules)
+	if err != nil {
+		return nil, nil, err
+	}
+	matching, err := matchingRules(answerKey.Type, answerKey.AnswerRules)
+	if err != nil {
+		return nil, nil, err
+	}
+	if matching != nil {
+		scoreboard.Definitions = matching.Definitions
+		scoreboard.Pairs = matching.Pairs
+	}
+	if answerKey.Type == constants.WordCloud {
+		responses, err := qc.userQuizResponseModel.GetUsersResponses(session.ID, questionID)
+		if err != nil {
+			return nil, nil, err
+		}
+		scoreboard.WordCloud = protocolWordCloud(questionID, responses, qc.wordCloudOptions())
+		scoreboard.RankList = []protocol.Rank{}
+		scoreboard.TeamRankList = []protocol.TeamRank{}
+	}
+
+	return scoreboard, userRankBoard, nil
+}
+
+// sendPlayerState restores the screen of a returning player: the phase of the session, the
+// running question or the last scoreboard, their submitted answer, score and streak.
+func sendPlayerState(w frameWriter, qc *quizSocketController, session models.ActiveQuiz, user models.User) {
+	state := session.SessionState()
+
+	data := protocol.PlayerState{
+		Phase:      state.Phase,
+		IsPaused:   state.IsPaused,
+		ServerTime: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if state.Deadline.Valid {
+		deadline := state.Deadline.Time.UTC().Format(time.RFC3339Nano)
+		data.Deadline = &deadline
+	}
+
+	var questionID uuid.UUID
+	if session.CurrentQuestion.Valid {
+		parsed, err := uuid.Parse(session.CurrentQuestion.String)
+		if err != nil {
+			qc.logger.Error("error while parsing current question for player state", zap.Error(err))
+		}
+		questionID = parsed
+	}
+
+	userPlayedQuizId, err := qc.userPlayedQuizModel.GetUserPlayedQuizId(user.ID, session.ID)
+	if err != nil && err != sql.ErrNoRows {
+		qc.logger.Error("error while getting user played quiz for player state", zap.Error(err))
+	}
+
+	shuffle := newPlayerShuffle(qc, session.ID, user.ID)
+
+	if err == nil {
+		progress, err := qc.userQuizResponseModel.GetPlayerProgress(userPlayedQuizId, questionID)
+		if err != nil {
+			qc.logger.Error("error while getting player progress", zap.Error(err))
+		} else {
+			data.Score = progress.TotalScore
+			data.Streak = progress.StreakCount
+
+			if progress.IsAnswerPresent {
+				keys := []int{}
+				if err := json.Unmarshal([]byte(progress.Answers.String), &keys); err != nil {
+					qc.logger.Error("error while unmarshaling submitted answer", zap.Error(err))
+				}
+				pairs := answerPairs(progress.AnswerPairs)
+				if question, err := qc.questionModel.GetCurrentQuestion(questionID); err == nil {
+					if mapping := shuffle.mapping(questionID, question.Options); mapping != nil {
+						keys = quizUtilsHelper.ToDisplayedKeys(keys, mapping)
+						pairs = quizUtilsHelper.ToDisplayedPairs(pairs, mapping)
+					}
+				}
+				data.Answer = &protocol.SubmittedAnswer{
+					ID:     questionID,
+					Keys:   keys,
+					Text:   progress.AnswerText.String,
+					Value:  nullFloat(progress.AnswerValue),
+					Pairs:  pairs,
+					Points: progress.QuestionPoints.Int32,
+					Score:  progress.QuestionScore.Int32,
+				}
+			}
+		}
+	}
+
+	// the event that renders the phase with the existing player screens
+	var event string
+	response := QuizSendResponse{Component: constants.Waiting, Action: constants.QuizQuestionStatus, Data: constants.QuizStartsSoon}
+
+	switch state.Phase {
+	case constants.SessionPhaseQuestion:
+		question, isRunning, err := currentQuestionPayload(qc, session)
+		if err != nil {
+			qc.logger.Error("error while getting current question for player state", zap.Error(err))
+		}
+		if isRunning {
+			shuffle.apply(question)
+			data.Question = question
+			event = constants.EventSendQuestion
+			response = QuizSendResponse{Component: constants.Question, Action: constants.ActionSendQuestion, Data: question}
+		} else {
+			event = constants.EventJoinQuiz
+			response.Data = constants.NextQuestionWillServeSoon
+		}
+	case constants.SessionPhaseScoreboard, constants.SessionPhaseAwaitingNext:
+		scoreboard, rankList, err := scoreboardPayload(qc, session, questionID)
+		if err != nil {
+			qc.logger.Error("error while getting scoreboard for player state", zap.Error(err))
+			event = constants.EventJoinQuiz
+			response.Data = constants.NextQuestionWillServeSoon
+			break
+		}
+		shuffle.apply(scoreboard)
+		data.Scoreboard = scoreboard
+		for _, rank := range rankList {
+			if rank.UserName == user.Username {
+				data.Rank = &rank.Rank
+				break
+			}
+		}
+		event = constants.EventShowScore
+		response = QuizSendResponse{Component: constants.Score, Action: constants.ActionShowScore, Data: scoreboard}
+	case constants.SessionPhaseCounter:
+		event = constants.EventJoinQuiz
+		response.Data = constants.NextQuestionWillServeSoon
+	default:
+		event = constants.EventJoinQuiz
+	}
+
+	err = func() error {
+		err := w.Send(constants.EventPlayerState, QuizSendResponse{Component: response.Component, Action: constants.ActionPlayerState, Data: data})
+		if err != nil {
+			return err
+		}
+		return w.Send(event, response)
+	}()
+
+	if err != nil {
+		qc.logger.Error(fmt.Sprintf("socket error send player state: %s event, %s action", constants.EventPlayerState, constants.ActionPlayerState), zap.Error(err))
+	}
+}
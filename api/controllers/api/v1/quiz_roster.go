@@ -0,0 +1,229 @@
+package v1
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/Improwised/jovvix/api/utils"
+	"github.com/gofiber/contrib/websocket"
+	"go.uber.org/zap"
+)
+
+// rosterEntry is a player of the lobby as it is kept in the roster hash of the session, by user id.
+// Joins and leaves only touch the field of their player, so concurrent ones never overwrite each other.
+type rosterEntry struct {
+	UserInfo
+	// JoinedAt orders the lobby, in unix milliseconds
+	JoinedAt int64 `json:"joined_at"`
+}
+
+func rosterKey(sessionId string) string {
+	return fmt.Sprintf("%s-%s", constants.KeyRoster, sessionId)
+}
+
+// joinRoster adds a player to the lobby and publishes the change, it reports false when the player is in the lobby already
+func (qc *quizSocketController) joinRoster(sessionId string, player UserInfo) (bool, error) {
+	player.IsAlive = true
+	data, err := json.Marshal(rosterEntry{UserInfo: player, JoinedAt: time.Now().UnixMilli()})
+	if err != nil {
+		return false, err
+	}
+
+	isAdded, count, err := qc.pubsub.HSetNX(rosterKey(sessionId), player.UserId, data, time.Minute*100)
+	if err != nil || !isAdded {
+		return false, err
+	}
+
+	return true, qc.publishLobbyChange(sessionId, constants.ChannelUserJoin, protocol.LobbyChange{Change: constants.LobbyJoined, Player: player, Count: count})
+}
+
+// leaveRoster removes a player from the lobby and publishes the change, it reports false when the player was not in the lobby
+func (qc *quizSocketController) leaveRoster(sessionId string, userId string) (bool, error) {
+	removed, count, err := qc.pubsub.HDel(rosterKey(sessionId), userId)
+	if err != nil || removed == 0 {
+		return false, err
+	}
+
+	return true, qc.publishLobbyChange(sessionId, constants.ChannelUserDisconnect, protocol.LobbyChange{Change: constants.LobbyLeft, Player: UserInfo{UserId: userId}, Count: count})
+}
+
+// renameInRoster changes the name a player of the lobby is shown under and publishes the change
+func (qc *quizSocketController) renameInRoster(sessionId string, userId string, name string) error {
+	data, err := qc.pubsub.HGet(rosterKey(sessionId), userId)
+	if err != nil {
+		// not in the lobby, the roster rebuilt from user_played_quizzes has the new name
+		return nil
+	}
+
+	entry := rosterEntry{}
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		return err
+	}
+	entry.UserName = name
+
+	renamed, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// the player may have left meanwhile
+	isSet, err := qc.pubsub.HSetXX(rosterKey(sessionId), userId, renamed)
+	if err != nil || !isSet {
+		return err
+	}
+
+	count, err := qc.pubsub.HLen(rosterKey(sessionId))
+	if err != nil {
+		return err
+	}
+
+	return qc.publishLobbyChange(sessionId, constants.ChannelUserJoin, protocol.LobbyChange{Change: constants.LobbyRenamed, Player: entry.UserInfo, Count: count})
+}
+
+func (qc *quizSocketController) publishLobbyChange(sessionId string, channel string, change protocol.LobbyChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	return qc.pubsub.Publish(fmt.Sprintf("%s-%s", channel, sessionId), data)
+}
+
+// lobbyRoster returns the players in the lobby in the order they joined. The roster is rebuilt from
+// user_played_quizzes when it is gone, after a restart of redis for instance.
+func (qc *quizSocketController) lobbyRoster(sessionId string) []UserInfo {
+	hash, err := qc.pubsub.HGetAll(rosterKey(sessionId))
+	if err != nil {
+		qc.logger.Error("error while getting the roster", zap.Error(err))
+	}
+	if len(hash) == 0 {
+		return qc.rebuildRosterFromDB(sessionId)
+	}
+
+	entries := make([]rosterEntry, 0, len(hash))
+	for _, data := range hash {
+		entry := rosterEntry{}
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			qc.logger.Error("error while unmarshaling roster entry", zap.Error(err))
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b rosterEntry) int {
+		return cmp.Compare(a.JoinedAt, b.JoinedAt)
+	})
+
+	roster := make([]UserInfo, 0, len(entries))
+	for _, entry := range entries {
+		roster = append(roster, entry.UserInfo)
+	}
+	return roster
+}
+
+func (qc *quizSocketController) rebuildRosterFromDB(sessionId string) []UserInfo {
+	joined, err := qc.userPlayedQuizModel.GetJoinedUsers(sessionId)
+	if err != nil {
+		qc.logger.Error("error while rebuilding roster from db", zap.Error(err))
+		return nil
+	}
+
+	if len(joined) == 0 {
+		return nil
+	}
+
+	roster := make([]UserInfo, 0, len(joined))
+	for i, u := range joined {
+		player := UserInfo{
+			UserId:   u.UserID,
+			UserName: u.FirstName,
+			Avatar:   u.ImageKey,
+			IsAlive:  true,
+		}
+		roster = append(roster, player)
+
+		// they joined before anybody who joins from now on, in this order
+		data, err := json.Marshal(rosterEntry{UserInfo: player, JoinedAt: int64(i)})
+		if err != nil {
+			qc.logger.Error("error while marshaling rebuilt roster", zap.Error(err))
+			continue
+		}
+		if _, _, err := qc.pubsub.HSetNX(rosterKey(sessionId), player.UserId, data, time.Minute*100); err != nil {
+			qc.logger.Error("error while reseeding roster", zap.Error(err))
+		}
+	}
+
+	return roster
+}
+
+// lobbyView is the lobby as a host or spectator socket shows it. A socket speaking v3 or later
+// gets the changes as they come, the older ones get the whole roster after each change.
+type lobbyView struct {
+	c       *websocket.Conn
+	mu      *sync.Mutex
+	players []UserInfo
+	changes bool
+}
+
+func newLobbyView(c *websocket.Conn, mu *sync.Mutex) *lobbyView {
+	version, _ := c.Locals(constants.ProtocolVersion).(int)
+	return &lobbyView{c: c, mu: mu, changes: version >= protocol.V3}
+}
+
+// reset shows players as the lobby
+func (v *lobbyView) reset(players []UserInfo) error {
+	v.players = slices.Clone(players)
+	if v.players == nil {
+		v.players = []UserInfo{}
+	}
+	return v.write(constants.EventSendInvitationCode, v.players)
+}
+
+// apply shows a change published for the lobby
+func (v *lobbyView) apply(payload string) error {
+	change := protocol.LobbyChange{}
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(v.players, func(player UserInfo) bool {
+		return player.UserId == change.Player.UserId
+	})
+	switch change.Change {
+	case constants.LobbyJoined:
+		// the change may already be part of the roster the view was reset with
+		if index >= 0 {
+			return nil
+		}
+		v.players = append(v.players, change.Player)
+	case constants.LobbyLeft:
+		if index < 0 {
+			return nil
+		}
+		v.players = slices.Delete(v.players, index, index+1)
+	case constants.LobbyRenamed:
+		if index >= 0 {
+			v.players[index].UserName = change.Player.UserName
+		}
+	}
+
+	if v.changes {
+		return v.write(constants.EventLobbyChanged, change)
+	}
+	return v.write(constants.EventSendInvitationCode, v.players)
+}
+
+func (v *lobbyView) write(event string, data any) error {
+	action := constants.ActionSendUserData
+	if event == constants.EventLobbyChanged {
+		action = constants.ActionLobbyChanged
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return utils.JSONSuccessWs(v.c, event, QuizSendResponse{Component: constants.Waiting, Action: action, Data: data})
+}
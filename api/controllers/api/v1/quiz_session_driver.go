@@ -0,0 +1,525 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/Improwised/jovvix/api/pkg/pubsub"
+	"github.com/Improwised/jovvix/api/utils"
+	"github.com/gofiber/contrib/websocket"
+	"go.uber.org/zap"
+)
+
+// hostMessage is an event for the host as it travels through pubsub
+type hostMessage struct {
+	Seq      int64  `json:"seq,omitempty"`
+	IsFail   bool   `json:"is_fail,omitempty"`
+	Event    string `json:"event"`
+	Response any    `json:"response"`
+}
+
+// hostLink publishes the events meant for the host of a session. Every Arrange socket
+// of the session relays them, on whichever replica it is connected, and the events of
+// the current phase are kept in pubsub for a host that reattaches later.
+type hostLink struct {
+	pubsub    pubsub.PubSub
+	sessionId string
+}
+
+func newHostLink(pubsub pubsub.PubSub, sessionId string) *hostLink {
+	return &hostLink{pubsub: pubsub, sessionId: sessionId}
+}
+
+func (h *hostLink) key(name string) string {
+	return fmt.Sprintf("%s-%s", name, h.sessionId)
+}
+
+// send publishes a phase event to the host and keeps it for replay
+func (h *hostLink) send(event string, response QuizSendResponse) error {
+	seq, err := h.pubsub.Incr(h.key(constants.KeyHostSeq), time.Minute*100)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(hostMessage{Seq: seq, Event: event, Response: response})
+	if err != nil {
+		return err
+	}
+
+	return h.pubsub.PushPublish(h.key(constants.KeyHostReplay), h.key(constants.ChannelHostEvents), data, time.Minute*100)
+}
+
+// write publishes an event that is not worth replaying, like the joined users count
+func (h *hostLink) write(event string, data any) error {
+	return h.publish(hostMessage{Event: event, Response: data})
+}
+
+func (h *hostLink) fail(event string, data any) error {
+	return h.publish(hostMessage{IsFail: true, Event: event, Response: data})
+}
+
+func (h *hostLink) publish(message hostMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return h.pubsub.Publish(h.key(constants.ChannelHostEvents), data)
+}
+
+func (h *hostLink) resetReplay() error {
+	return h.pubsub.Del(h.key(constants.KeyHostReplay))
+}
+
+// replay returns the events sent to the host since the current phase started
+func (h *hostLink) replay() ([]hostMessage, error) {
+	items, err := h.pubsub.Range(h.key(constants.KeyHostReplay))
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]hostMessage, 0, len(items))
+	for _, item := range items {
+		message := hostMessage{}
+		if err := json.Unmarshal([]byte(item), &message); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// writeHostMessage writes a relayed event to the host's socket
+func writeHostMessage(c *websocket.Conn, arrangeMu *sync.Mutex, message hostMessage) error {
+	arrangeMu.Lock()
+	defer arrangeMu.Unlock()
+
+	if message.IsFail {
+		return utils.JSONFailWs(c, message.Event, message.Response)
+	}
+	return utils.JSONSuccessWs(c, message.Event, message.Response)
+}
+
+// sessionSnapshot describes the persisted state for a host that (re)attaches to the session
+func sessionSnapshot(session models.ActiveQuiz) QuizSendResponse {
+	state := session.SessionState()
+
+	data := protocol.SessionState{
+		Phase:           state.Phase,
+		Code:            int(session.InvitationCode.Int32),
+		IsPaused:        state.IsPaused,
+		PausedRemaining: int(state.PausedRemaining.Seconds()),
+		ServerTime:      time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if state.Deadline.Valid {
+		deadline := state.Deadline.Time.UTC().Format(time.RFC3339Nano)
+		data.Deadline = &deadline
+	}
+
+	component := constants.Question
+	if state.Phase == constants.SessionPhaseScoreboard || state.Phase == constants.SessionPhaseAwaitingNext {
+		component = constants.Score
+	}
+
+	return QuizSendResponse{
+		Component: component,
+		Action:    constants.ActionSessionState,
+		Data:      data,
+	}
+}
+
+// serveHost relays the host events of a running session to c and publishes the host's
+// commands to the replica driving the session, until the socket is closed.
+func (qc *quizSocketController) serveHost(c *websocket.Conn, session models.ActiveQuiz, arrangeMu *sync.Mutex) {
+	sessionId := session.ID.String()
+	host := newHostLink(qc.pubsub, sessionId)
+	hostChannel := host.key(constants.ChannelHostEvents)
+
+	// subscribed before the replay below, so nothing published after it is missed
+	subscription, err := qc.pubsub.Subscribe(hostChannel)
+	if err != nil {
+		qc.logger.Error("error while subscribing to host events", zap.Error(err))
+		return
+	}
+	defer subscription.Close()
+
+	hostCountKey := host.key(constants.KeyHostCount)
+	if _, err := qc.pubsub.Incr(hostCountKey, time.Minute*100); err != nil {
+		qc.logger.Error("error while counting host sockets", zap.Error(err))
+	}
+	defer qc.releaseHost(session, hostCountKey)
+
+	// catch a reattaching host up with the current phase
+	var lastSeq int64
+	if session.IsStarted() {
+		current, err := qc.activeQuizModel.GetSession(sessionId)
+		if err != nil {
+			qc.logger.Error("error while getting session state for host", zap.Error(err))
+			current = session
+		}
+
+		err = writeHostMessage(c, arrangeMu, hostMessage{Event: constants.EventSessionState, Response: sessionSnapshot(current)})
+		if err != nil {
+			qc.logger.Error("error while sending session state to host", zap.Error(err))
+		}
+
+		replay, err := host.replay()
+		if err != nil {
+			qc.logger.Error("error while getting host replay", zap.Error(err))
+		}
+		for _, message := range replay {
+			lastSeq = message.Seq
+			if err := writeHostMessage(c, arrangeMu, message); err != nil {
+				qc.logger.Error("error while replaying host event", zap.String("event", message.Event), zap.Error(err))
+			}
+		}
+	}
+
+	go func() {
+		for msg := range subscription.Channel() {
+			message := hostMessage{}
+			if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+				qc.logger.Error("error while unmarshaling host event", zap.Error(err))
+				continue
+			}
+
+			// already replayed
+			if message.Seq != 0 && message.Seq <= lastSeq {
+				continue
+			}
+
+			if err := writeHostMessage(c, arrangeMu, message); err != nil {
+				qc.logger.Error(fmt.Sprintf("socket error sending event: %s event", message.Event), zap.Error(err))
+			}
+
+			if message.Event == constants.EventTerminateQuiz {
+				c.Close()
+				return
+			}
+		}
+	}()
+
+	listenAllEvents(c, qc, arrangeMu, session.ID)
+}
+
+// releaseHost notifies the players once the last socket of their host is gone, the quiz itself keeps running
+func (qc *quizSocketController) releaseHost(session models.ActiveQuiz, hostCountKey string) {
+	count, err := qc.pubsub.Decr(hostCountKey)
+	if err != nil {
+		qc.logger.Error("error while releasing host socket", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	current, err := qc.activeQuizModel.GetSession(session.ID.String())
+	if err != nil || !current.IsActive {
+		return
+	}
+
+	response := QuizSendResponse{Component: constants.Loading, Data: constants.AdminDisconnected}
+	shareEvenWithUser(newHostLink(qc.pubsub, session.ID.String()), qc, &response, constants.AdminDisconnected, session.ID.String(), int(session.InvitationCode.Int32), constants.ToUser)
+}
+
+// sessionDriver runs the question loop of a session independently of the host's socket.
+// Exactly one replica drives a session at a time, guarded by a lease in redis.
+type sessionDriver struct {
+	qc       *quizSocketController
+	session  models.ActiveQuiz
+	host     *hostLink
+	leaseKey string
+
+	mu    sync.Mutex
+	state models.SessionState
+
+	chanNextEvent chan bool
+	chanSkipEvent chan bool
+	chanSkipTimer chan bool
+	chanPauseQuiz chan bool
+	done          chan struct{}
+	stopOnce      sync.Once
+}
+
+func newSessionDriver(qc *quizSocketController, session models.ActiveQuiz) *sessionDriver {
+	return &sessionDriver{
+		qc:            qc,
+		session:       session,
+		host:          newHostLink(qc.pubsub, session.ID.String()),
+		leaseKey:      fmt.Sprintf("%s-%s", constants.KeySessionLease, session.ID.String()),
+		state:         session.SessionState(),
+		chanNextEvent: make(chan bool, 1),
+		chanSkipEvent: make(chan bool, 1),
+		chanSkipTimer: make(chan bool, 1),
+		chanPauseQuiz: make(chan bool, 1),
+		done:          make(chan struct{}),
+	}
+}
+
+// ensureDriver makes sure some replica drives the session, starting the driver here
+// when no replica holds its lease. A session that was already running (e.g. on a
+// replica that died) resumes from the state persisted in active_quizzes.
+func (qc *quizSocketController) ensureDriver(session models.ActiveQuiz) {
+	sessionId := session.ID.String()
+
+	qc.driversMu.Lock()
+	defer qc.driversMu.Unlock()
+
+	if _, ok := qc.drivers[sessionId]; ok {
+		return
+	}
+
+	d := newSessionDriver(qc, session)
+	acquired, err := qc.pubsub.AcquireLease(d.leaseKey, qc.appConfig.Quiz.SessionLease())
+	if err != nil {
+		qc.logger.Error("error while acquiring session lease", zap.String("session_id", sessionId), zap.Error(err))
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	qc.drivers[sessionId] = d
+	if session.IsStarted() {
+		qc.logger.Info("taking over running session", zap.String("session_id", sessionId), zap.String("node", qc.pubsub.NodeID()))
+	}
+
+	go d.run(session.IsStarted())
+}
+
+// WatchSessions periodically takes over running sessions whose driver lease expired,
+// which happens when the replica driving them dies.
+func (qc *quizSocketController) WatchSessions() {
+	ticker := time.NewTicker(qc.appConfig.Quiz.SessionLease())
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sessions, err := qc.activeQuizModel.GetRunningSessions()
+		if err != nil {
+			qc.logger.Error("error while getting running sessions", zap.Error(err))
+			continue
+		}
+
+		for _, session := range sessions {
+			owner, err := qc.pubsub.LeaseOwner(fmt.Sprintf("%s-%s", constants.KeySessionLease, session.ID.String()))
+			if err != nil {
+				qc.logger.Error("error while getting session lease owner", zap.Error(err))
+				continue
+			}
+			if owner == "" {
+				qc.ensureDriver(session)
+			}
+		}
+	}
+}
+
+// stopDriver ends the question loop of a session on whichever replica drives it
+func (qc *quizSocketController) stopDriver(sessionId string) {
+	publishHostCommand(qc, sessionId, QuizReceiveResponse{Event: constants.EventStopSession})
+}
+
+func publishHostCommand(qc *quizSocketController, sessionId string, message QuizReceiveResponse) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		qc.logger.Error("error while marshaling host command", zap.Error(err))
+		return
+	}
+
+	err = qc.pubsub.Publish(fmt.Sprintf("%s-%s", constants.ChannelHostCommands, sessionId), data)
+	if err != nil {
+		qc.logger.Error("error while publishing host command", zap.String("event", message.Event), zap.Error(err))
+	}
+}
+
+// run drives the session while this replica holds its lease
+func (d *sessionDriver) run(isResumed bool) {
+	qc := d.qc
+	sessionId := d.session.ID.String()
+
+	defer func() {
+		d.stop()
+		if err := qc.pubsub.ReleaseLease(d.leaseKey); err != nil {
+			qc.logger.Error("error while releasing session lease", zap.Error(err))
+		}
+
+		qc.driversMu.Lock()
+		delete(qc.drivers, sessionId)
+		qc.driversMu.Unlock()
+	}()
+
+	go d.keepLease()
+	go d.listenHostCommands()
+	go d.handleRunningQuizUserJoin()
+
+	d.questionAndScoreHandler(isResumed)
+}
+
+// keepLease renews the lease of the session, the driver stops once another replica took it
+func (d *sessionDriver) keepLease() {
+	ttl := d.qc.appConfig.Quiz.SessionLease()
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			renewed, err := d.qc.pubsub.RenewLease(d.leaseKey, ttl)
+			if err != nil {
+				d.qc.logger.Error("error while renewing session lease", zap.Error(err))
+				continue
+			}
+			if !renewed {
+				d.qc.logger.Warn("session lease lost, stopping driver", zap.String("session_id", d.session.ID.String()))
+				d.stop()
+				return
+			}
+		}
+	}
+}
+
+// listenHostCommands receives the commands of the host's sockets from every replica
+func (d *sessionDriver) listenHostCommands() {
+	qc := d.qc
+	channel := fmt.Sprintf("%s-%s", constants.ChannelHostCommands, d.session.ID.String())
+
+	subscription, err := qc.pubsub.Subscribe(channel)
+	if err != nil {
+		qc.logger.Error("subscribe failed", zap.Error(err))
+		return
+	}
+	defer subscription.Close()
+
+	ch := subscription.Channel()
+	for {
+		select {
+		case <-d.done:
+			return
+		case msg := <-ch:
+			message := QuizReceiveResponse{}
+			if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+				qc.logger.Error("error while unmarshaling host command", zap.Error(err))
+				continue
+			}
+			d.dispatch(message)
+		}
+	}
+}
+
+func (d *sessionDriver) stop() {
+	d.stopOnce.Do(func() { close(d.done) })
+}
+
+func (d *sessionDriver) isStopped() bool {
+	select {
+	case <-d.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// setPhase moves the session to the next phase and persists it
+func (d *sessionDriver) setPhase(phase string, deadline time.Time) {
+	d.mu.Lock()
+	d.state = models.SessionState{
+		Phase:    phase,
+		Deadline: sql.NullTime{Time: deadline, Valid: !deadline.IsZero()},
+	}
+	state := d.state
+	d.mu.Unlock()
+
+	if err := d.host.resetReplay(); err != nil {
+		d.qc.logger.Error("error while resetting host replay", zap.Error(err))
+	}
+	d.saveState(state)
+}
+
+// setPaused pauses or resumes the timer of the current phase
+func (d *sessionDriver) setPaused(isPaused bool, remaining time.Duration) {
+	d.mu.Lock()
+	d.state.IsPaused = isPaused
+	d.state.PausedRemaining = 0
+	d.state.Deadline = sql.NullTime{}
+	if isPaused {
+		d.state.PausedRemaining = remaining
+	} else {
+		d.state.Deadline = sql.NullTime{Time: time.Now().Add(remaining), Valid: true}
+	}
+	state := d.state
+	d.mu.Unlock()
+
+	d.saveState(state)
+}
+
+func (d *sessionDriver) saveState(state models.SessionState) {
+	err := d.qc.activeQuizModel.SaveSessionState(d.session.ID, state)
+	if err != nil {
+		d.qc.logger.Error("error while saving session state", zap.String("session_id", d.session.ID.String()), zap.String("phase", state.Phase), zap.Error(err))
+	}
+}
+
+// dispatch forwards a host command to the running phase. Commands the current
+// phase does not wait for are dropped instead of piling up.
+func (d *sessionDriver) dispatch(message QuizReceiveResponse) {
+	var ch chan bool
+	var value bool
+
+	switch message.Event {
+	case constants.EventSkipAsked:
+		ch, value = d.chanSkipEvent, false
+	case constants.EventForceSkip:
+		ch, value = d.chanSkipEvent, true
+	case constants.EventNextQuestionAsked:
+		ch, value = d.chanNextEvent, true
+	case constants.EventSkipTimer:
+		ch, value = d.chanSkipTimer, true
+	case constants.EventPauseQuiz:
+		isPauseQuiz, ok := message.Data.(bool)
+		if !ok {
+			return
+		}
+		ch, value = d.chanPauseQuiz, isPauseQuiz
+	case constants.EventStopSession:
+		d.stop()
+		return
+	default:
+		return
+	}
+
+	select {
+	case ch <- value:
+	default:
+	}
+}
+
+// awaitNext blocks until the host asks for the next question. It gives up when the
+// session is terminated or deactivated while nobody is hosting it.
+func (d *sessionDriver) awaitNext() bool {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.chanNextEvent:
+			return true
+		case <-d.done:
+			return false
+		case <-ticker.C:
+			session, err := d.qc.activeQuizModel.GetSession(d.session.ID.String())
+			if err != nil {
+				d.qc.logger.Error("error while checking session during awaiting next question", zap.Error(err))
+				continue
+			}
+			if !session.IsActive {
+				return false
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Improwised/jovvix/api/constants"
+	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/utils"
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// optionMapping is the order a player sees the options of a question in, keyed by the key they see. The
+// participation and the question seed it, so it is computed again when the answer is scored.
+func optionMapping(userPlayedQuizId uuid.UUID, questionId uuid.UUID, options map[string]string) map[string]string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+
+	return quizUtilsHelper.OptionKeyMapping(userPlayedQuizId.String()+questionId.String(), keys)
+}
+
+// playerShuffle rewrites the questions sent to one player into the option order of that player, the
+// setting of the session is read once the first question arrives as it is locked from then on
+type playerShuffle struct {
+	qc               *quizSocketController
+	sessionId        uuid.UUID
+	userId           string
+	isLoaded         bool
+	isEnabled        bool
+	userPlayedQuizId uuid.UUID
+}
+
+func newPlayerShuffle(qc *quizSocketController, sessionId uuid.UUID, userId string) *playerShuffle {
+	return &playerShuffle{qc: qc, sessionId: sessionId, userId: userId}
+}
+
+func (s *playerShuffle) load() bool {
+	if s.isLoaded {
+		return s.isEnabled
+	}
+	s.isLoaded = true
+
+	session, err := s.qc.activeQuizModel.GetSession(s.sessionId.String())
+	if err != nil {
+		s.qc.logger.Error("error while getting shuffle setting of the session", zap.Error(err))
+		return false
+	}
+	if !session.ShuffleOptions {
+		return false
+	}
+
+	s.userPlayedQuizId, err = s.qc.userPlayedQuizModel.GetUserPlayedQuizId(s.userId, s.sessionId)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.qc.logger.Error("error while getting user played quiz to shuffle options", zap.Error(err))
+		}
+		return false
+	}
+
+	s.isEnabled = true
+	return true
+}
+
+// mapping returns the option order of questionId for the player, nil when options are not shuffled
+func (s *playerShuffle) mapping(questionId uuid.UUID, options map[string]string) map[string]string {
+	if s == nil || !s.load() {
+		return nil
+	}
+
+	return optionMapping(s.userPlayedQuizId, questionId, options)
+}
+
+// apply rewrites the options and the correct answers of a question or a scoreboard
+func (s *playerShuffle) apply(data any) {
+	switch payload := data.(type) {
+	case *protocol.Question:
+		if mapping := s.mapping(payload.ID, payload.Options); mapping != nil {
+			payload.Options = quizUtilsHelper.ShuffleOptions(payload.Options, mapping)
+		}
+	case *protocol.Scoreboard:
+		if payload.QuestionID == nil {
+			return
+		}
+		if mapping := s.mapping(*payload.QuestionID, payload.Options); mapping != nil {
+			payload.Options = quizUtilsHelper.ShuffleOptions(payload.Options, mapping)
+			payload.Answers = quizUtilsHelper.ToDisplayedKeys(payload.Answers, mapping)
+			payload.Pairs = quizUtilsHelper.ToDisplayedPairs(payload.Pairs, mapping)
+		}
+	}
+}
+
+// canonicalAnswer translates the keys picked by a player back to the keys of the question before scoring
+func (qc *quizSocketController) canonicalAnswer(userPlayedQuizId uuid.UUID, answer *structs.ReqAnswerSubmit) error {
+	question, err := qc.questionModel.GetCurrentQuestion(answer.QuestionId)
+	if err != nil {
+		return err
+	}
+
+	canonicalKeys(answer, optionMapping(userPlayedQuizId, answer.QuestionId, question.Options))
+	return nil
+}
+
+// canonicalKeys translates the option keys of an answer, picked or paired, back to the keys of the question
+func canonicalKeys(answer *structs.ReqAnswerSubmit, mapping map[string]string) {
+	answer.AnswerKeys = quizUtilsHelper.ToCanonicalKeys(answer.AnswerKeys, mapping)
+	answer.AnswerPairs = quizUtilsHelper.ToCanonicalPairs(answer.AnswerPairs, mapping)
+}
+
+// SetShuffle to show the questions and options of a session in a different order to each player.
+// swagger:route PUT /v1/quiz/sessions/{session_id}/shuffle Quiz RequestSessionShuffle
+//
+// Shuffle the options for each player and the order of the questions for the session, only in the lobby.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseOkWithMessage
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *quizSocketController) SetShuffle(c *fiber.Ctx) error {
+	session, ok, err := ctrl.getHostedLobbySession(c)
+	if !ok {
+		return err
+	}
+
+	var shuffleReq structs.ReqSessionShuffle
+	err = json.Unmarshal(c.Body(), &shuffleReq)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	validate := validator.New()
+	err = validate.Struct(shuffleReq)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, utils.ValidatorErrorString(err))
+	}
+
+	err = ctrl.activeQuizModel.SetShuffle(session.ID, shuffleReq.ShuffleQuestions, shuffleReq.ShuffleOptions)
+	if err != nil {
+		ctrl.logger.Error(constants.ErrSetShuffle, zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrSetShuffle)
+	}
+
+	return utils.JSONSuccess(c, http.StatusOK, "success")
+}
+
+// shuffleOnStart puts the questions of a live session in random order when the host asked for it
+func shuffleOnStart(qc *quizSocketController, session models.ActiveQuiz) {
+	current, err := qc.activeQuizModel.GetSession(session.ID.String())
+	if err != nil {
+		qc.logger.Error("error while getting shuffle setting on start", zap.Error(err))
+		return
+	}
+
+	if !current.ShuffleQuestions {
+		return
+	}
+
+	if err := qc.activeQuizModel.ShuffleQuestionOrder(session.ID); err != nil {
+		qc.logger.Error("error while shuffling questions on start", zap.Error(err))
+	}
+}
@@ -13,7 +13,9 @@ import (
 	"github.com/Improwised/jovvix/api/constants"
 	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
 	"github.com/Improwised/jovvix/api/models"
-	"github.com/Improwised/jovvix/api/pkg/redis"
+	"github.com/Improwised/jovvix/api/pkg/hub"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/Improwised/jovvix/api/pkg/pubsub"
 	"github.com/Improwised/jovvix/api/pkg/structs"
 	"github.com/Improwised/jovvix/api/utils"
 	"github.com/doug-martin/goqu/v9"
@@ -24,24 +26,12 @@ import (
 	validator "gopkg.in/go-playground/validator.v9"
 )
 
-type QuizSendResponse struct {
-	Component string `json:"component"` // simulates a page
-	Action    string `json:"action"`    // action is a description of an event
-	Data      any    `json:"data"`      // optional data
-}
+// the frames of the sockets are defined by the protocol package, which documents them
+type QuizSendResponse = protocol.Frame
 
-type QuizReceiveResponse struct {
-	Component string `json:"component"` // simulates a page
-	Event     string `json:"event"`     // event
-	Data      any    `json:"data"`      // optional data
-}
+type QuizReceiveResponse = protocol.Request
 
-type UserInfo struct {
-	UserId   string
-	UserName string
-	Avatar   string
-	IsAlive  bool
-}
+type UserInfo = protocol.Player
 
 type quizSocketController struct {
 	activeQuizModel       *models.ActiveQuizModel
@@ -49,18 +39,25 @@ type quizSocketController struct {
 	userPlayedQuizModel   *models.UserPlayedQuizModel
 	questionModel         *models.QuestionModel
 	userQuizResponseModel *models.UserQuizResponseModel
+	sessionTeamModel      *models.SessionTeamModel
 	appConfig             *config.AppConfig
 	logger                *zap.Logger
-	redis                 *redis.RedisPubSub
+	pubsub                pubsub.PubSub
+	hub                   *hub.Hub
+
+	// question loops of the sessions hosted from this node, by session id
+	drivers   map[string]*sessionDriver
+	driversMu sync.Mutex
 }
 
-func InitQuizConfig(db *goqu.Database, appConfig *config.AppConfig, logger *zap.Logger, redis *redis.RedisPubSub) (*quizSocketController, error) {
+func InitQuizConfig(db *goqu.Database, appConfig *config.AppConfig, logger *zap.Logger, pubsub pubsub.PubSub, hub *hub.Hub) (*quizSocketController, error) {
 
 	activeQuizModel := models.InitActiveQuizModel(db, logger)
 	quizModel := models.InitQuizModel(db)
 	userPlayedQuizModel := models.InitUserPlayedQuizModel(db)
 	questionModel := models.InitQuestionModel(db, logger)
 	userQuizResponseModel := models.InitUserQuizResponseModel(db)
+	sessionTeamModel := models.InitSessionTeamModel(db, logger)
 
 	return &quizSocketController{
 		activeQuizModel:       activeQuizModel,
@@ -68,15 +65,24 @@ func InitQuizConfig(db *goqu.Database, appConfig *config.AppConfig, logger *zap.
 		userPlayedQuizModel:   userPlayedQuizModel,
 		questionModel:         questionModel,
 		userQuizResponseModel: userQuizResponseModel,
+		sessionTeamModel:      sessionTeamModel,
 		appConfig:             appConfig,
 		logger:                logger,
-		redis:                 redis,
+		pubsub:                pubsub,
+		hub:                   hub,
+		drivers:               map[string]*sessionDriver{},
 	}, nil
 }
 
 // for user Join
 func (qc *quizSocketController) Join(c *websocket.Conn) {
-	var JoinMu sync.Mutex
+	// the frames of a player go out through its hub client, what it queued is written before the socket closes
+	client := qc.hub.Attach(c)
+	defer func() {
+		client.Close()
+		c.Close()
+	}()
+	sendHello(c, qc, protocol.SocketJoin, client)
 
 	response := QuizSendResponse{
 		Component: constants.Waiting,
@@ -108,26 +114,56 @@ func (qc *quizSocketController) Join(c *websocket.Conn) {
 			qc.logger.Error("error in invitation code", zap.Error(err))
 		}
 
-		wsErr := func() error {
-			JoinMu.Lock()
-			defer JoinMu.Unlock()
-			return utils.JSONFailWs(c, constants.EventJoinQuiz, response)
-		}()
+		wsErr := client.Fail(constants.EventJoinQuiz, response)
 		if wsErr != nil {
 			qc.logger.Error(fmt.Sprintf("socket error on join: %s event, %s action", constants.EventJoinQuiz, response.Action), zap.Error(wsErr))
 		}
+		return
+	}
 
-		c.Close()
+	// players of an assignment are served over http at their own pace
+	if session.IsAssignment() {
+		response.Action = constants.ActionJoinQuiz
+		response.Data = constants.ErrAssignmentIsSelfPaced
+
+		wsErr := client.Fail(constants.EventJoinQuiz, response)
+		if wsErr != nil {
+			qc.logger.Error(fmt.Sprintf("socket error on join: %s event, %s action", constants.EventJoinQuiz, response.Action), zap.Error(wsErr))
+		}
 		return
 	}
 
 	userId := quizUtilsHelper.GetString(c.Locals(constants.ContextUid))
-	isUserConnected := make(chan bool)
 
-	defer func() {
-		c.Close()
-		qc.logger.Info("connection closed by user")
-	}()
+	// a banned player stays out, a renamed one keeps the name the host gave them
+	displayName := user.FirstName
+	participant, err := qc.userPlayedQuizModel.GetParticipant(userId, session.ID)
+	if err != nil && err != sql.ErrNoRows {
+		qc.logger.Error("error while getting participant on join", zap.Error(err))
+	}
+	if err == nil && participant.IsBanned {
+		response.Action = constants.ActionJoinQuiz
+		response.Data = constants.ErrPlayerBanned
+
+		wsErr := client.Fail(constants.EventJoinQuiz, response)
+		if wsErr != nil {
+			qc.logger.Error(fmt.Sprintf("socket error on join: %s event, %s action", constants.EventJoinQuiz, response.Action), zap.Error(wsErr))
+		}
+		return
+	}
+	if err == nil && participant.DisplayName.Valid {
+		displayName = participant.DisplayName.String
+	}
+
+	// the host is redirected below, everybody else takes a seat on the terms of the session
+	if userId != session.AdminID && !qc.admitPlayer(client, session, userId) {
+		return
+	}
+
+	// buffered, handleQuestion may be gone already when the socket is closed for being slow
+	isUserConnected := make(chan bool, 1)
+
+	defer qc.logger.Info("connection closed by user")
 
 	// check user web socket connection is close or not
 	go func() {
@@ -138,30 +174,32 @@ func (qc *quizSocketController) Join(c *websocket.Conn) {
 			if err != nil {
 				// if error occurs, change the connection alive status to false
 				qc.logger.Error("error while reading data from websocket", zap.Error(err))
-				updateUserData(qc, userId, session.ID.String(), false)
+				removeUserData(qc, userId, session.ID.String())
 				isUserConnected <- false
 				break
 			}
+			quizResponse = QuizReceiveResponse{}
 			err = json.Unmarshal([]byte(p), &quizResponse)
 			if err != nil {
 				qc.logger.Error("error while unmarshaling data from websocket", zap.Error(err))
-				updateUserData(qc, userId, session.ID.String(), false)
+				removeUserData(qc, userId, session.ID.String())
 				break
 			}
 
-			if quizResponse.Event == "websocket_close" {
-				updateUserData(qc, userId, session.ID.String(), false)
+			if quizResponse.Event == constants.EventWebsocketClose {
+				removeUserData(qc, userId, session.ID.String())
 				qc.logger.Info("connection close request is send by the user - " + user.Username)
 				break
 			}
 
+			if quizResponse.Event == constants.EventSubmitAnswer {
+				handleSocketAnswer(client, qc, user, session, quizResponse.Data)
+				continue
+			}
+
 			if quizResponse.Event == constants.EventPing {
 
-				err := func() error {
-					JoinMu.Lock()
-					defer JoinMu.Unlock()
-					return utils.JSONSuccessWs(c, "pong", "")
-				}()
+				err := client.Send("pong", "")
 
 				if err != nil {
 					qc.logger.Error("error while sending pong message", zap.Error(err))
@@ -173,13 +211,9 @@ func (qc *quizSocketController) Join(c *websocket.Conn) {
 	// is user is a host of current quiz
 	if userId == session.AdminID {
 		response.Action = constants.ActionCurrentUserIsAdmin
-		response.Data = map[string]string{"sessionId": session.ID.String()}
+		response.Data = protocol.RedirectToAdmin{SessionID: session.ID.String()}
 
-		err := func() error {
-			JoinMu.Lock()
-			defer JoinMu.Unlock()
-			return utils.JSONSuccessWs(c, constants.EventRedirectToAdmin, response)
-		}()
+		err := client.Send(constants.EventRedirectToAdmin, response)
 
 		if err != nil {
 			qc.logger.Error(fmt.Sprintf("socket redirect current user is admin: %s event, %s action, %s code", constants.EventRedirectToAdmin, response.Action, invitationCode), zap.Error(err))
@@ -187,168 +221,127 @@ func (qc *quizSocketController) Join(c *websocket.Conn) {
 		return
 	}
 
-	// when user join at that time publish userName to admin
-	publishUserOnJoin(qc, response, user.FirstName, userId, user.ImageKey, session.ID.String())
-	response.Action = constants.QuizQuestionStatus
-	onConnectHandleUser(c, qc, &response, session, &JoinMu)
-	// userPlayedQuizId := quizUtilsHelper.GetString(c.Locals(constants.CurrentUserQuiz))
-	handleQuestion(c, qc, session, response, isUserConnected, &JoinMu)
-}
-
-func publishUserOnJoin(qc *quizSocketController, quizResponse QuizSendResponse, userName string, userId string, avatar string, sessionId string) {
-	// store data to redis in form of slice
-	var usersData []UserInfo
-	var jsonData []byte
-
-	response := quizResponse
-
-	// check weather current session id has an any user to show if not then set. if present then get and add new user to it
-	exists, err := qc.redis.PubSubModel.Client.Exists(qc.redis.PubSubModel.Ctx, sessionId).Result()
-	if err != nil {
-		qc.logger.Error("error while checking if there is any user in redis for the session in publishUserOnJoin", zap.Error(err))
-	}
-	if exists == 0 {
-		newUser := UserInfo{UserId: userId, UserName: userName, Avatar: avatar, IsAlive: true}
-		usersData = append(usersData, newUser)
-		// Serialize slice to JSON
-		jsonData, err = json.Marshal(usersData)
+	// a returning player presents the token handed to their previous socket
+	rejoinToken := c.Query(constants.RejoinTokenParam)
+	isRejoin := qc.isValidRejoinToken(rejoinToken, session.ID.String(), userId)
+	if !isRejoin {
+		rejoinToken, err = qc.issueRejoinToken(session.ID.String(), userId)
 		if err != nil {
-			qc.logger.Error("error while marshalling data into json in publishUserOnJoin when there is no data in redis", zap.Error(err))
+			qc.logger.Error("error while issuing rejoin token", zap.Error(err))
 		}
+	}
 
-	} else {
-		// get data from redis
-		users, err := qc.redis.PubSubModel.Client.Get(qc.redis.PubSubModel.Ctx, sessionId).Result()
+	if rejoinToken != "" {
+		err = client.Send(constants.EventRejoinToken, QuizSendResponse{Component: constants.Waiting, Action: constants.ActionRejoinToken, Data: protocol.RejoinToken{Token: rejoinToken}})
 		if err != nil {
-			qc.logger.Error("error while fetching data from redis in publishUserOnJoin", zap.Error(err))
+			qc.logger.Error(fmt.Sprintf("socket error send rejoin token: %s event", constants.EventRejoinToken), zap.Error(err))
 		}
-		err = json.Unmarshal([]byte(users), &usersData)
+	}
+
+	// when user join at that time publish userName to admin
+	publishUserOnJoin(qc, displayName, userId, user.ImageKey, session.ID.String())
+
+	// players can only pick their team in the lobby, everybody else is placed in the smallest team
+	if session.HasTeams() && (session.TeamMode.String == constants.TeamModeAuto || session.IsStarted()) {
+		isAssigned, err := qc.sessionTeamModel.AssignToSmallestTeam(session.ID, userId)
 		if err != nil {
-			qc.logger.Error("error while unmarshaling redis in publishUserOnJoin", zap.Error(err))
-		}
-		for _, data := range usersData {
-			if userId == data.UserId {
-				qc.logger.Error(fmt.Sprintf("User %s already exist in redis", userName))
-				return
-			}
+			qc.logger.Error("error while assigning player to a team", zap.Error(err))
+		} else if isAssigned {
+			publishTeamRoster(qc, session.ID)
 		}
-		newUser := UserInfo{UserId: userId, UserName: userName, Avatar: avatar, IsAlive: true}
-		usersData = append(usersData, newUser)
-		jsonData, err = json.Marshal(usersData)
-		if err != nil {
-			qc.logger.Error("error while marshaling data into json in publishUserOnJoin", zap.Error(err))
+	}
 
-		}
+	shuffle := newPlayerShuffle(qc, session.ID, userId)
 
+	response.Action = constants.QuizQuestionStatus
+	if isRejoin {
+		sendPlayerState(client, qc, session, user)
+	} else {
+		onConnectHandleUser(client, qc, &response, session, shuffle)
 	}
-
-	// if quiz is still not start then publish join user data to admin and refresh the page
-	err = qc.redis.PubSubModel.Client.Set(qc.redis.PubSubModel.Ctx, sessionId, jsonData, time.Minute*100).Err()
-	if err != nil {
-		qc.logger.Error(fmt.Sprintf("socket error publishing event: %s event, %s action", constants.EventUserJoined, response.Action), zap.Error(err))
+	if session.HasTeams() {
+		sendTeamRoster(client, qc, session.ID)
 	}
+	// userPlayedQuizId := quizUtilsHelper.GetString(c.Locals(constants.CurrentUserQuiz))
+	handleQuestion(client, qc, session, userId, response, isUserConnected, shuffle)
+}
 
-	// remove data with isAlive false before publishing
-	publishData := filterPublishUsers(qc, usersData, "publishUserOnJoin")
-
-	err = qc.redis.PubSubModel.Client.Publish(qc.redis.PubSubModel.Ctx, fmt.Sprintf("%s-%s", constants.ChannelUserJoin, sessionId), publishData).Err()
-
+func publishUserOnJoin(qc *quizSocketController, userName string, userId string, avatar string, sessionId string) {
+	isAdded, err := qc.joinRoster(sessionId, UserInfo{UserId: userId, UserName: userName, Avatar: avatar})
 	if err != nil {
-		qc.logger.Error(fmt.Sprintf("socket error publishing event: %s event, %s action", constants.EventUserJoined, response.Action), zap.Error(err))
+		qc.logger.Error(fmt.Sprintf("socket error publishing event: %s event", constants.EventUserJoined), zap.Error(err))
+		return
+	}
+	if !isAdded {
+		qc.logger.Debug(fmt.Sprintf("User %s already in the lobby", userName))
 	}
 }
 
-func handleQuestion(c *websocket.Conn, qc *quizSocketController, session models.ActiveQuiz, response QuizSendResponse, isUserConnected chan bool, joinMu *sync.Mutex) {
-	pubsub := qc.redis.PubSubModel.Client.Subscribe(qc.redis.PubSubModel.Ctx, session.ID.String())
-	defer func() {
-		if pubsub != nil {
-			err := pubsub.Unsubscribe(qc.redis.PubSubModel.Ctx, session.ID.String())
-			if err != nil {
-				qc.logger.Error("unsubscribe failed", zap.Error(err))
-			}
-			pubsub.Close()
-		}
-	}()
+func handleQuestion(client *hub.Client, qc *quizSocketController, session models.ActiveQuiz, userId string, response QuizSendResponse, isUserConnected chan bool, shuffle *playerShuffle) {
+	// the channels of the session are subscribed to once per node, however many players are on it
+	moderationChannel := fmt.Sprintf("%s-%s", constants.ChannelModeration, session.ID)
+	if err := qc.hub.Subscribe(client, session.ID.String(), moderationChannel); err != nil {
+		qc.logger.Error("subscribe failed", zap.Error(err))
+		return
+	}
 
-	ch := pubsub.Channel()
 	for {
 		select {
 		case isConnected := <-isUserConnected:
 			if !isConnected {
 				return
 			}
-		case msg := <-ch:
-			message := map[string]any{}
+		case <-client.Done():
+			// the socket fell too far behind, the player rejoins and gets the state of the session back
+			return
+		case msg := <-client.Messages():
+			if msg.Channel == moderationChannel {
+				if !handleModerationNotice(client, qc, userId, msg.Payload) {
+					return
+				}
+				continue
+			}
+
+			message := playerMessage{}
 			err := json.Unmarshal([]byte(msg.Payload), &message)
 
 			if err != nil {
 				qc.logger.Error(fmt.Sprintf("socket error send waiting message: %s event, %s action", constants.EventJoinQuiz, response.Action), zap.Error(err))
 			}
 
-			event := quizUtilsHelper.GetString(message["event"])
+			shuffle.apply(message.Response.Data)
 
-			err = func() error {
-				joinMu.Lock()
-				defer joinMu.Unlock()
-				return utils.JSONSuccessWs(c, event, message["response"])
-			}()
+			err = client.Send(message.Event, message.Response)
 
 			if err != nil {
-				qc.logger.Error(fmt.Sprintf("socket error send waiting message: %s event, %s action", event, response.Action), zap.Error(err))
+				qc.logger.Error(fmt.Sprintf("socket error send waiting message: %s event, %s action", message.Event, response.Action), zap.Error(err))
 			}
 
-			if message["event"] == constants.EventTerminateQuiz {
+			if message.Event == constants.EventTerminateQuiz {
 				return
 			}
 		}
 	}
 }
 
-func onConnectHandleUser(c *websocket.Conn, qc *quizSocketController, response *QuizSendResponse, session models.ActiveQuiz, joinMu *sync.Mutex) {
+func onConnectHandleUser(client *hub.Client, qc *quizSocketController, response *QuizSendResponse, session models.ActiveQuiz, shuffle *playerShuffle) {
 	if session.CurrentQuestion.Valid {
 
-		totalQuestion, err := qc.questionModel.GetTotalQuestionCount(session.ID.String())
-		if err != nil {
-			qc.logger.Error(constants.ErrInGettingTotalQuestionCount, zap.Error(err))
-			return
-		}
-
-		questionID, err := uuid.Parse(session.CurrentQuestion.String)
-		if err != nil {
-			qc.logger.Error(fmt.Sprintf("\nquestionID is not being parsed from the current question id of this session and that current question id is - %v\n", session.CurrentQuestion), zap.Error(err))
-		}
-
-		currentQuestion, err := qc.questionModel.GetCurrentQuestion(questionID)
+		responseData, isRunning, err := currentQuestionPayload(qc, session)
 		if err != nil {
 			qc.logger.Error("unable to get the current question and the question id was "+session.CurrentQuestion.String, zap.Error(err))
+			return
 		}
 
 		response.Action = constants.ActionSendQuestion
-		remainingSeconds := currentQuestion.DurationInSeconds - int(time.Since(session.QuestionDeliveryTime.Time).Seconds())
-		if remainingSeconds < 0 {
+		if !isRunning {
 			return
 		}
-		responseData := map[string]any{
-			"id":             currentQuestion.ID,
-			"no":             currentQuestion.OrderNumber,
-			"duration":       currentQuestion.DurationInSeconds,
-			"start_time":     session.QuestionDeliveryTime.Time.Format(time.RFC3339),
-			"server_time":    time.Now().UTC().Format(time.RFC3339Nano),
-			"question":       currentQuestion.Question,
-			"options":        currentQuestion.Options,
-			"totalQuestions": totalQuestion,
-			"question_media": currentQuestion.QuestionMedia,
-			"options_media":  currentQuestion.OptionsMedia,
-			"resource":       currentQuestion.Resource.String,
-		}
+		shuffle.apply(responseData)
 		response.Data = responseData
 		response.Component = constants.Question
 
-		err = func() error {
-			joinMu.Lock()
-			defer joinMu.Unlock()
-			return utils.JSONSuccessWs(c, constants.EventSendQuestion, response)
-		}()
+		err = client.Send(constants.EventSendQuestion, response)
 
 		if err != nil {
 			qc.logger.Error(fmt.Sprintf("socket error send current question on connect: %s event, %s action", constants.EventSendQuestion, response.Action), zap.Error(err))
@@ -356,11 +349,7 @@ func onConnectHandleUser(c *websocket.Conn, qc *quizSocketController, response *
 	} else {
 		response.Data = constants.QuizStartsSoon
 
-		err := func() error {
-			joinMu.Lock()
-			defer joinMu.Unlock()
-			return utils.JSONSuccessWs(c, constants.EventJoinQuiz, response)
-		}()
+		err := client.Send(constants.EventJoinQuiz, response)
 
 		if err != nil {
 			qc.logger.Error(fmt.Sprintf("socket error send waiting message: %s event, %s action", constants.EventJoinQuiz, response.Action), zap.Error(err))
@@ -368,82 +357,23 @@ func onConnectHandleUser(c *websocket.Conn, qc *quizSocketController, response *
 	}
 }
 
-// function to update user IsAlive status
-func updateUserData(qc *quizSocketController, userId string, sessionId string, isAlive bool) {
-	// Fetch data from Redis
-	users, err := qc.redis.PubSubModel.Client.Get(qc.redis.PubSubModel.Ctx, sessionId).Result()
+// removeUserData takes a player who left out of the lobby
+func removeUserData(qc *quizSocketController, userId string, sessionId string) {
+	isRemoved, err := qc.leaveRoster(sessionId, userId)
 	if err != nil {
-		qc.logger.Error("error fetching data from redis in updateUserData", zap.Error(err))
-		return
-	}
-
-	var usersData []UserInfo
-	if err := json.Unmarshal([]byte(users), &usersData); err != nil {
-		qc.logger.Error("error unmarshaling redis data in updateUserData", zap.Error(err))
+		qc.logger.Error("error while removing user from the lobby", zap.Error(err))
 		return
 	}
-
-	var update bool
-	var updatedUserData []UserInfo
-	for _, data := range usersData {
-		if data.UserId == userId {
-			if data.IsAlive == isAlive {
-				return
-			}
-			data.IsAlive = isAlive
-			update = true
-		}
-		if data.IsAlive {
-			updatedUserData = append(updatedUserData, data)
-		}
-	}
-
-	if update {
-		jsonData, err := json.Marshal(updatedUserData)
-		if err != nil {
-			qc.logger.Error("error marshaling updated data in updateUserData", zap.Error(err))
-			return
-		}
-
-		// Update Redis
-		if err := qc.redis.PubSubModel.Client.Set(qc.redis.PubSubModel.Ctx, sessionId, jsonData, time.Minute*100).Err(); err != nil {
-			qc.logger.Error("error updating data in redis in updateUserData", zap.Error(err))
-			return
-		}
-
-		qc.logger.Debug(fmt.Sprintf("IsAlive status updated for user %s (%s)", userId, userId))
-
-		// Publish updated user data
-		publishData := filterPublishUsers(qc, updatedUserData, "updateUserData")
-		if err := qc.redis.PubSubModel.Client.Publish(qc.redis.PubSubModel.Ctx, fmt.Sprintf("%s-%s", constants.ChannelUserDisconnect, sessionId), publishData).Err(); err != nil {
-			qc.logger.Error("error publishing data in updateUserData", zap.Error(err))
-		}
-	}
-}
-
-// filter user data and publish only alive user to the channel
-func filterPublishUsers(qc *quizSocketController, usersData []UserInfo, functionName string) (publishData []byte) {
-
-	// Filter out elements where IsAlive is false
-	var filteredData []UserInfo
-	for _, data := range usersData {
-		if data.IsAlive {
-			filteredData = append(filteredData, data)
-		}
-	}
-
-	// store new data into publishData
-	publishData, err := json.Marshal(filteredData)
-	if err != nil {
-		qc.logger.Error(fmt.Sprintf("error while marshaling data into filterPublishUsers, called from %s", functionName), zap.Error(err))
+	if isRemoved {
+		qc.logger.Debug(fmt.Sprintf("user %s left the lobby", userId))
 	}
-
-	return publishData
 }
 
 // for admin join
 func (qc *quizSocketController) Arrange(c *websocket.Conn) {
-	var arrangeMu sync.Mutex
+	var mu sync.Mutex
+	arrangeMu := &mu
+	sendHello(c, qc, protocol.SocketArrange, lockedSocket{c, arrangeMu})
 
 	isConnected := true
 	adminDisconnected := make(chan bool, 1)
@@ -474,7 +404,7 @@ func (qc *quizSocketController) Arrange(c *websocket.Conn) {
 	}
 
 	// activate session
-	session, err := ActivateAndGetSession(c, qc.activeQuizModel, qc.logger, sessionId, user.ID, &arrangeMu)
+	session, err := ActivateAndGetSession(c, qc.activeQuizModel, qc.logger, sessionId, user.ID, arrangeMu)
 
 	if err != nil {
 		qc.logger.Error("get active session", zap.Error(err))
@@ -499,52 +429,50 @@ func (qc *quizSocketController) Arrange(c *websocket.Conn) {
 		qc.logger.Info("connection closed by admin")
 	}()
 
-	// handle code sharing with admin
-	handleCodeGeneration(c, qc, session, &isConnected, &response, adminDisconnected, &arrangeMu)
+	// a running session is not restarted, the host reattaches to its driver
+	if !session.IsStarted() {
+		// handle code sharing with admin
+		handleCodeGeneration(c, qc, session, &isConnected, &response, adminDisconnected, arrangeMu)
 
-	// if connection lost during waiting of start event
-	if !(isConnected) {
-		response.Component = constants.Loading
-		response.Data = constants.AdminDisconnected
-		shareEvenWithUser(c, qc, &response, constants.AdminDisconnected, sessionId, int(session.InvitationCode.Int32), constants.ToUser, &arrangeMu)
+		// if connection lost during waiting of start event
+		if !(isConnected) {
+			response.Component = constants.Loading
+			response.Data = constants.AdminDisconnected
+			shareEvenWithUser(newHostLink(qc.pubsub, sessionId), qc, &response, constants.AdminDisconnected, sessionId, int(session.InvitationCode.Int32), constants.ToUser)
 
-		qc.logger.Error("admin disconnected")
-		return
+			qc.logger.Error("admin disconnected")
+			return
+		}
 	}
 
-	// handle when user join during running quiz
-	go handleRunningQuizUserJoin(c, qc, adminDisconnected, session.ID.String(), &arrangeMu)
-
-	// question and score handler
-	questionAndScoreHandler(c, qc, &response, session, &isConnected, &arrangeMu)
+	// the session is driven by whichever replica holds its lease, this socket only relays
+	qc.ensureDriver(session)
+	qc.serveHost(c, session, arrangeMu)
 }
 
-// handleRunningQuizUserJoin listens for users joining a running quiz and sends the updated count of users to the client.
-// It also listens for admin disconnection and gracefully terminates if the admin is disconnected.
-func handleRunningQuizUserJoin(c *websocket.Conn, qc *quizSocketController, adminDisconnected chan bool, sessionId string, arrangeMu *sync.Mutex) {
+// handleRunningQuizUserJoin listens for users joining a running quiz and sends the updated count of users to the host.
+// It stops once the session driver is done.
+func (d *sessionDriver) handleRunningQuizUserJoin() {
+	qc := d.qc
+	sessionId := d.session.ID.String()
+
 	response := QuizSendResponse{}
 	response.Action = constants.JoinUserOnRunningQuiz
 	response.Component = constants.Running
 
-	pubsub := qc.redis.PubSubModel.Client.Subscribe(qc.redis.PubSubModel.Ctx, fmt.Sprintf("%s-%s", constants.ChannelUserJoin, sessionId))
-	defer func() {
-		if pubsub != nil {
-			err := pubsub.Unsubscribe(qc.redis.PubSubModel.Ctx, fmt.Sprintf("%s-%s", constants.ChannelUserJoin, sessionId))
-			if err != nil {
-				qc.logger.Error("unsubscribe failed", zap.Error(err))
-			}
-			pubsub.Close()
-		}
-	}()
+	subscription, err := qc.pubsub.Subscribe(fmt.Sprintf("%s-%s", constants.ChannelUserJoin, sessionId))
+	if err != nil {
+		qc.logger.Error("subscribe failed", zap.Error(err))
+		return
+	}
+	defer subscription.Close()
 
-	ch := pubsub.Channel()
+	ch := subscription.Channel()
 
 	for {
 		select {
-		case isDisconnected := <-adminDisconnected:
-			if isDisconnected {
-				return
-			}
+		case <-d.done:
+			return
 		case msg := <-ch:
 			response.Data = msg.Payload
 
@@ -556,12 +484,7 @@ func handleRunningQuizUserJoin(c *websocket.Conn, qc *quizSocketController, admi
 			}
 			response.Data = totalUserJoin
 
-			err = func() error {
-				arrangeMu.Lock()
-				defer arrangeMu.Unlock()
-				return utils.JSONSuccessWs(c, constants.JoinUserOnRunningQuiz, response)
-			}()
-
+			err = d.host.write(constants.JoinUserOnRunningQuiz, response)
 			if err != nil {
 				qc.logger.Error("error while sending user data ", zap.Error(err))
 			}
@@ -608,6 +531,20 @@ func ActivateAndGetSession(c *websocket.Conn, activeQuizModel *models.ActiveQuiz
 				logger.Error(fmt.Sprintf("socket error authentication host: %s event, %s action", constants.EventAuthorization, response.Action), zap.Error(err))
 			}
 			return session, err
+		} else if err.Error() == constants.ErrAssignmentIsSelfPaced {
+			response.Action = constants.ActionSessionActivation
+			response.Data = constants.ErrAssignmentIsSelfPaced
+
+			wsErr := func() error {
+				arrangeMu.Lock()
+				defer arrangeMu.Unlock()
+				return utils.JSONFailWs(c, constants.EventAuthorization, response)
+			}()
+
+			if wsErr != nil {
+				logger.Error(fmt.Sprintf("socket error authentication host: %s event, %s action", constants.EventAuthorization, response.Action), zap.Error(wsErr))
+			}
+			return session, err
 		}
 
 		response.Action = constants.ActionSessionActivation
@@ -646,7 +583,9 @@ func handleCodeGeneration(c *websocket.Conn, qc *quizSocketController, session m
 			// if code not sent then sent it
 			if !isInvitationCodeSent {
 				// send code to client
-				handleInvitationCodeSend(c, response, qc.logger, session.InvitationCode.Int32, arrangeMu)
+				// the link never carries the passcode, the host shares that on its own
+				joinURL, _ := qc.joinLink(session, false)
+				handleInvitationCodeSend(c, response, qc.logger, session.InvitationCode.Int32, joinURL, arrangeMu)
 				isInvitationCodeSent = true
 				go handleConnectedUser(c, qc, session.ID.String(), adminDisconnected, arrangeMu)
 
@@ -654,9 +593,13 @@ func handleCodeGeneration(c *websocket.Conn, qc *quizSocketController, session m
 
 			// once code sent receive start signal
 			if isInvitationCodeSent {
-				isBreak := handleStartQuiz(c, qc.logger, isConnected, response.Action)
+				isBreak, message := handleStartQuiz(c, qc.logger, isConnected, response.Action)
 
-				if isBreak == constants.EventPing {
+				if isModerationEvent(isBreak) {
+					qc.moderatePlayer(c, session.ID, message, arrangeMu)
+				} else if isBreak == constants.EventLockLobby {
+					qc.lockLobby(c, session.ID, message, arrangeMu)
+				} else if isBreak == constants.EventPing {
 
 					err := func() error {
 						arrangeMu.Lock()
@@ -668,38 +611,42 @@ func handleCodeGeneration(c *websocket.Conn, qc *quizSocketController, session m
 						qc.logger.Error("error while sending pong message", zap.Error(err))
 					}
 				} else {
-					users, err := qc.redis.PubSubModel.Client.Get(qc.redis.PubSubModel.Ctx, session.ID.String()).Result()
-					if err != nil {
-						qc.logger.Error("error while fetching data from redis inside updateUserData", zap.Error(err))
-					}
-
-					var usersData []UserInfo
-					err = json.Unmarshal([]byte(users), &usersData)
+					lobbySize, err := qc.pubsub.HLen(rosterKey(session.ID.String()))
 					if err != nil {
-						qc.logger.Error("error while unmarshaling redis inside updateUserData", zap.Error(err))
+						qc.logger.Error("error while getting the lobby size", zap.Error(err))
 					}
 
 					// A public-quiz host can also be a player. Such a host is recorded in
-					// user_played_quizzes but never in the redis join list (that is only
+					// user_played_quizzes but never in the lobby roster (that is only
 					// populated by the join socket), so fall back to the DB participant
 					// count to allow starting with the host playing solo.
 					participantCount, countErr := qc.userPlayedQuizModel.GetCountOfTotalJoinUsers(session.ID.String())
 					if countErr != nil {
 						qc.logger.Error(constants.ErrGetTotalJoinUser, zap.Error(countErr))
 					}
-					hasParticipants := len(usersData) != 0 || participantCount > 0
+					hasParticipants := lobbySize != 0 || participantCount > 0
 
 					if hasParticipants && isBreak == constants.EventStartQuiz {
 
+						// players who did not pick a team are placed before the first question
+						placed, balanceErr := qc.sessionTeamModel.BalanceUnassigned(session.ID)
+						if balanceErr != nil {
+							qc.logger.Error("error while balancing teams on start", zap.Error(balanceErr))
+						} else if placed > 0 {
+							publishTeamRoster(qc, session.ID)
+						}
+
+						shuffleOnStart(qc, session)
+
 						// quiz is start publish for admin to stop looking for user
-						err := qc.redis.PubSubModel.Client.Publish(qc.redis.PubSubModel.Ctx, constants.EventStartQuizByAdmin, constants.EventStartQuizByAdmin).Err()
+						err := qc.pubsub.Publish(constants.EventStartQuizByAdmin, constants.EventStartQuizByAdmin)
 						if err != nil {
 							qc.logger.Error("error while start quiz", zap.Error(err))
 						}
 						break
 					} else {
 						// quiz is start publish for admin to stop looking for user becuse no player found
-						err := qc.redis.PubSubModel.Client.Publish(qc.redis.PubSubModel.Ctx, constants.StartQuizByAdminNoPlayerFound, constants.StartQuizByAdminNoPlayerFound).Err()
+						err := qc.pubsub.Publish(constants.StartQuizByAdminNoPlayerFound, constants.StartQuizByAdminNoPlayerFound)
 						if err != nil {
 							qc.logger.Error("errro while start quiz but no player found", zap.Error(err))
 						}
@@ -723,11 +670,11 @@ func handleCodeGeneration(c *websocket.Conn, qc *quizSocketController, session m
 }
 
 // handle waiting page
-func handleInvitationCodeSend(c *websocket.Conn, response *QuizSendResponse, logger *zap.Logger, invitationCode int32, arrangeMu *sync.Mutex) bool {
+func handleInvitationCodeSend(c *websocket.Conn, response *QuizSendResponse, logger *zap.Logger, invitationCode int32, joinURL string, arrangeMu *sync.Mutex) bool {
 
 	// send code to client
 	response.Action = constants.ActionSessionActivation
-	response.Data = map[string]int{"code": int(invitationCode)}
+	response.Data = protocol.InvitationCode{Code: int(invitationCode), JoinURL: joinURL}
 
 	err := func() error {
 		arrangeMu.Lock()
@@ -742,94 +689,30 @@ func handleInvitationCodeSend(c *websocket.Conn, response *QuizSendResponse, log
 	return true
 }
 
-func (qc *quizSocketController) rebuildRosterFromDB(sessionId string) []UserInfo {
-	joined, err := qc.userPlayedQuizModel.GetJoinedUsers(sessionId)
-	if err != nil {
-		qc.logger.Error("error while rebuilding roster from db", zap.Error(err))
-		return nil
-	}
-
-	if len(joined) == 0 {
-		return nil
-	}
-
-	roster := make([]UserInfo, 0, len(joined))
-	for _, u := range joined {
-		roster = append(roster, UserInfo{
-			UserId:   u.UserID,
-			UserName: u.FirstName,
-			Avatar:   u.ImageKey,
-			IsAlive:  true,
-		})
-	}
-
-	jsonData, err := json.Marshal(roster)
-	if err != nil {
-		qc.logger.Error("error while marshaling rebuilt roster", zap.Error(err))
-		return roster
-	}
-
-	if err := qc.redis.PubSubModel.Client.Set(qc.redis.PubSubModel.Ctx, sessionId, jsonData, time.Minute*100).Err(); err != nil {
-		qc.logger.Error("error while reseeding roster into redis", zap.Error(err))
-	}
-
-	return roster
-}
-
 // when user connect at that time send data to admin
 func handleConnectedUser(c *websocket.Conn, qc *quizSocketController, sessionId string, adminDisconnected chan bool, arrangeMu *sync.Mutex) {
-	response := QuizSendResponse{}
-	response.Action = constants.ActionSendUserData
-	response.Component = constants.Waiting
-
-	users, err := qc.redis.PubSubModel.Client.Get(
-		qc.redis.PubSubModel.Ctx,
-		sessionId,
-	).Result()
-
-	var initialRoster []UserInfo
-	if err == nil && users != "" {
-		if err := json.Unmarshal([]byte(users), &initialRoster); err != nil {
-			qc.logger.Error("error while unmarshaling roster from redis", zap.Error(err))
-			initialRoster = nil
-		}
-	}
+	userJoinChannel := fmt.Sprintf("%s-%s", constants.ChannelUserJoin, sessionId)
+	userDisconnectChannel := fmt.Sprintf("%s-%s", constants.ChannelUserDisconnect, sessionId)
+	teamRosterChannel := fmt.Sprintf("%s-%s", constants.ChannelTeamRoster, sessionId)
 
-	if initialRoster == nil {
-		initialRoster = qc.rebuildRosterFromDB(sessionId)
+	// subscribed before the roster is read, so no change is missed in between
+	subscription, err := qc.pubsub.Subscribe(userJoinChannel, userDisconnectChannel, teamRosterChannel, constants.EventTerminateQuiz, constants.EventStartQuizByAdmin, constants.StartQuizByAdminNoPlayerFound)
+	if err != nil {
+		qc.logger.Error("subscribe failed", zap.Error(err))
+		return
 	}
+	defer subscription.Close()
 
-	if initialRoster != nil {
-		response := QuizSendResponse{
-			Component: constants.Waiting,
-			Action:    constants.ActionSendUserData,
-			Data:      initialRoster,
-		}
-
-		err = func() error {
-			arrangeMu.Lock()
-			defer arrangeMu.Unlock()
-			return utils.JSONSuccessWs(c, constants.EventSendInvitationCode, response)
-		}()
-		if err != nil {
-			qc.logger.Error("error while sending initial user data to admin", zap.Error(err))
-		}
+	lobby := newLobbyView(c, arrangeMu)
+	if err := lobby.reset(qc.lobbyRoster(sessionId)); err != nil {
+		qc.logger.Error("error while sending initial user data to admin", zap.Error(err))
 	}
 
-	pubsub := qc.redis.PubSubModel.Client.Subscribe(qc.redis.PubSubModel.Ctx, fmt.Sprintf("%s-%s", constants.ChannelUserJoin, sessionId), fmt.Sprintf("%s-%s", constants.ChannelUserDisconnect, sessionId), constants.EventTerminateQuiz, constants.EventStartQuizByAdmin, constants.StartQuizByAdminNoPlayerFound)
-	defer func() {
-		if pubsub != nil {
-			err := pubsub.Unsubscribe(qc.redis.PubSubModel.Ctx, fmt.Sprintf("%s-%s", constants.ChannelUserJoin, sessionId), fmt.Sprintf("%s-%s", constants.ChannelUserDisconnect, sessionId), constants.EventTerminateQuiz, constants.EventStartQuizByAdmin, constants.StartQuizByAdminNoPlayerFound)
-			if err != nil {
-				qc.logger.Error("unsubscribe failed", zap.Error(err))
-			}
-			pubsub.Close()
-		}
-	}()
-
-	ch := pubsub.Channel()
-	usersData := []UserInfo{}
+	if parsedSessionId, err := uuid.Parse(sessionId); err == nil {
+		sendTeamRoster(lockedSocket{c, arrangeMu}, qc, parsedSessionId)
+	}
 
+	ch := subscription.Channel()
 	for {
 		select {
 		case isDisconnected := <-adminDisconnected:
@@ -837,69 +720,54 @@ func handleConnectedUser(c *websocket.Conn, qc *quizSocketController, sessionId
 				return
 			}
 		case msg := <-ch:
-			response.Data = msg.Payload
-
-			if response.Data == constants.StartQuizByAdminNoPlayerFound {
-				continue
-			}
-
-			if response.Data == constants.EventStartQuizByAdmin || response.Data == constants.EventTerminateQuiz {
+			switch msg.Channel {
+			case teamRosterChannel:
+				err := func() error {
+					arrangeMu.Lock()
+					defer arrangeMu.Unlock()
+					return utils.JSONSuccessWs(c, constants.EventTeamRoster, QuizSendResponse{Component: constants.Waiting, Action: constants.ActionTeamRoster, Data: json.RawMessage(msg.Payload)})
+				}()
+				if err != nil {
+					qc.logger.Error("error while sending team roster to admin", zap.Error(err))
+				}
+			case userJoinChannel, userDisconnectChannel:
+				// sending the user data to the admin
+				if err := lobby.apply(msg.Payload); err != nil {
+					qc.logger.Error("error while sending user data ", zap.Error(err))
+				}
+			case constants.EventStartQuizByAdmin, constants.EventTerminateQuiz:
 				return
 			}
-
-			err := json.Unmarshal([]byte(msg.Payload), &usersData)
-			if err != nil {
-				qc.logger.Error("error while unmarshaling data inside handleconnectedUser ", zap.Error(err))
-
-				break
-			}
-
-			response.Data = usersData
-
-			err = func() error {
-				arrangeMu.Lock()
-				defer arrangeMu.Unlock()
-				return utils.JSONSuccessWs(c, constants.EventSendInvitationCode, response)
-			}()
-			// sending the user data to the admin
-			if err != nil {
-				qc.logger.Error("error while sending user data ", zap.Error(err))
-			}
 		}
 	}
 }
 
 // start quiz by message event from admin
-func handleStartQuiz(c *websocket.Conn, logger *zap.Logger, isConnected *bool, action string) string {
+func handleStartQuiz(c *websocket.Conn, logger *zap.Logger, isConnected *bool, action string) (string, QuizReceiveResponse) {
 	message := QuizReceiveResponse{}
 	err := c.ReadJSON(&message)
 	if err != nil {
 		logger.Error(fmt.Sprintf("socket error start event handling: %s event, %s action", constants.EventStartQuiz, action), zap.Error(err))
 		*isConnected = false
-		return constants.UnknownError
-	}
-
-	if message.Event == constants.EventStartQuiz {
-		return constants.EventStartQuiz
+		return constants.UnknownError, message
 	}
 
-	if message.Event == constants.EventPing {
-		return constants.EventPing
+	if message.Event == constants.EventStartQuiz || message.Event == constants.EventPing || message.Event == constants.EventLockLobby || isModerationEvent(message.Event) {
+		return message.Event, message
 	}
 
-	return constants.UnknownError
+	return constants.UnknownError, message
 }
 
-func shareEvenWithUser(c *websocket.Conn, qc *quizSocketController, response *QuizSendResponse, event string, sessionId string, invitationCode int, sentToWhom int, arrangeMu *sync.Mutex) {
-	payload := map[string]any{"event": event, "response": response}
-	data, err := json.Marshal(payload)
+func shareEvenWithUser(host *hostLink, qc *quizSocketController, response *QuizSendResponse, event string, sessionId string, invitationCode int, sentToWhom int) {
+	data, err := json.Marshal(playerMessage{Event: event, Response: *response})
 	if err != nil {
 		qc.logger.Error(fmt.Sprintf("socket error marshal redis payload: %s event, %s action %v code", constants.EventSendQuestion, response.Action, invitationCode), zap.Error(err))
 	}
 
 	if sentToWhom == constants.ToUser || sentToWhom == constants.ToAll {
 		// send event to user
-		err = qc.redis.PubSubModel.Client.Publish(qc.redis.PubSubModel.Ctx, sessionId, data).Err()
+		err = qc.pubsub.Publish(sessionId, data)
 
 		if err != nil {
 			qc.logger.Error(fmt.Sprintf("socket error publishing event: %s event, %s action %v code", constants.EventPublishQuestion, response.Action, invitationCode), zap.Error(err))
@@ -908,12 +776,7 @@ func shareEvenWithUser(c *websocket.Conn, qc *quizSocketController, response *Qu
 
 	if sentToWhom == constants.ToAdmin || sentToWhom == constants.ToAll {
 		// send event to admin
-
-		err := func() error {
-			arrangeMu.Lock()
-			defer arrangeMu.Unlock()
-			return utils.JSONSuccessWs(c, event, response)
-		}()
+		err := host.send(event, *response)
 
 		if err != nil {
 			qc.logger.Error(fmt.Sprintf("socket error sending event: %s event, %s action %v code", constants.EventSendQuestion, response.Action, invitationCode), zap.Error(err))
@@ -921,7 +784,11 @@ func shareEvenWithUser(c *websocket.Conn, qc *quizSocketController, response *Qu
 	}
 }
 
-func questionAndScoreHandler(c *websocket.Conn, qc *quizSocketController, response *QuizSendResponse, session models.ActiveQuiz, isConnected *bool, arrangeMu *sync.Mutex) {
+func (d *sessionDriver) questionAndScoreHandler(isResumed bool) {
+	qc := d.qc
+	session := d.session
+	response := &QuizSendResponse{}
+
 	// get questions/remaining question
 	response.Component = constants.Question
 	questions, lastQuestionDeliveryTime, err := qc.quizModel.GetSharedQuestions(int(session.InvitationCode.Int32))
@@ -929,12 +796,7 @@ func questionAndScoreHandler(c *websocket.Conn, qc *quizSocketController, respon
 		response.Action = constants.ErrInGettingQuestion
 		qc.logger.Error(fmt.Sprintf("socket error get remaining questions: %s event, %s action %v code", constants.EventStartQuiz, response.Action, session.InvitationCode), zap.Error(err))
 
-		err := func() error {
-			arrangeMu.Lock()
-			defer arrangeMu.Unlock()
-			return utils.JSONFailWs(c, constants.EventSendQuestion, response)
-		}()
-
+		err := d.host.fail(constants.EventSendQuestion, response)
 		if err != nil {
 			qc.logger.Error("error during get remaining question", zap.Error(err))
 		}
@@ -947,89 +809,96 @@ func questionAndScoreHandler(c *websocket.Conn, qc *quizSocketController, respon
 		return
 	}
 
-	var wg sync.WaitGroup
-	chanNextEvent := make(chan bool)
-	chanSkipEvent := make(chan bool)
-	chanSkipTimer := make(chan bool)
-	chanPauseQuiz := make(chan bool)
-	var isQuizEnd bool = false
-
-	go listenAllEvents(c, qc, response, session, chanNextEvent, chanSkipEvent, chanSkipTimer, chanPauseQuiz, isQuizEnd, arrangeMu)
+	// the scoreboard of the last question was already shown before the host lost the session
+	state := session.SessionState()
+	if isResumed && (state.Phase == constants.SessionPhaseScoreboard || state.Phase == constants.SessionPhaseAwaitingNext) {
+		if !d.askNextQuestion(response) {
+			return
+		}
+	}
 
 	// handle question
 	var isFirst bool = lastQuestionDeliveryTime.Valid
 	response.Component = constants.Question
 	for _, question := range questions {
-		wg.Add(1)
 		if isFirst { // handle running question
 			isFirst = false
-			sendSingleQuestion(c, qc, &wg, response, session, question, lastQuestionDeliveryTime, chanSkipEvent, chanSkipTimer, chanPauseQuiz, totalQuestion, arrangeMu)
+			d.sendSingleQuestion(response, question, lastQuestionDeliveryTime, totalQuestion)
 		} else { // handle new question
-			sendSingleQuestion(c, qc, &wg, response, session, question, sql.NullTime{}, chanSkipEvent, chanSkipTimer, chanPauseQuiz, totalQuestion, arrangeMu)
-		}
-
-		err := func() error {
-			arrangeMu.Lock()
-			defer arrangeMu.Unlock()
-			return utils.JSONSuccessWs(c, constants.EventNextQuestionAsked, response)
-		}()
-
-		if err != nil {
-			qc.logger.Error("socket error during asking for next question", zap.Error(err))
+			d.sendSingleQuestion(response, question, sql.NullTime{}, totalQuestion)
 		}
 
 		// handle next question
-		if <-chanNextEvent {
-			continue
+		if !d.askNextQuestion(response) {
+			return
 		}
-
-		wg.Wait()
 	}
 
 	// termination of quiz
-	if session.ActivatedFrom.Valid && *isConnected {
-		terminateQuiz(c, qc, response, session, arrangeMu)
-		// isQuizEnd = false
+	if session.ActivatedFrom.Valid {
+		d.terminateQuiz(response)
+	}
+}
+
+// askNextQuestion waits on the scoreboard until the host moves on to the next question
+func (d *sessionDriver) askNextQuestion(response *QuizSendResponse) bool {
+	if d.isStopped() {
+		return false
+	}
+
+	d.setPhase(constants.SessionPhaseAwaitingNext, time.Time{})
+
+	err := d.host.send(constants.EventNextQuestionAsked, *response)
+	if err != nil {
+		d.qc.logger.Error("socket error during asking for next question", zap.Error(err))
 	}
+
+	return d.awaitNext()
 }
 
-func listenAllEvents(c *websocket.Conn, qc *quizSocketController, response *QuizSendResponse, session models.ActiveQuiz, chanNextEvent chan bool, chanSkipEvent chan bool, chanSkipTimer chan bool, chanPauseQuiz chan bool, isQuizEnd bool, arrangeMu *sync.Mutex) {
+// listenAllEvents reads the host's commands from c and hands them to the session driver until the socket is closed
+func listenAllEvents(c *websocket.Conn, qc *quizSocketController, arrangeMu *sync.Mutex, sessionId uuid.UUID) {
 	for {
 		message := QuizReceiveResponse{}
 		err := c.ReadJSON(&message)
 
 		if err != nil {
 			qc.logger.Error("error in receiving message from question", zap.Error(err))
-			// isConnected = false
-			break
+			return
 		}
 
-		switch message.Event {
-		case constants.EventSkipAsked:
-			chanSkipEvent <- false
-		case constants.EventForceSkip:
-			chanSkipEvent <- true
-		case constants.EventNextQuestionAsked:
-			chanNextEvent <- true
-		case constants.EventSkipTimer:
-			chanSkipTimer <- true
-		case constants.EventPauseQuiz:
-			isPauseQuiz := message.Data.(bool)
-			chanPauseQuiz <- isPauseQuiz
+		if message.Event == constants.EventPing {
+			err := func() error {
+				arrangeMu.Lock()
+				defer arrangeMu.Unlock()
+				return utils.JSONSuccessWs(c, constants.EventPong, "")
+			}()
+
+			if err != nil {
+				qc.logger.Error("error while sending pong message", zap.Error(err))
+			}
+			continue
+		}
+
+		// players can be moderated from any replica, the driver is not involved
+		if isModerationEvent(message.Event) {
+			qc.moderatePlayer(c, sessionId, message, arrangeMu)
+			continue
 		}
-	}
 
-	// handle connection lost during quiz
-	if !isQuizEnd {
-		response.Component = constants.Loading
-		response.Data = constants.AdminDisconnected
-		shareEvenWithUser(c, qc, response, constants.AdminDisconnected, session.ID.String(), int(session.InvitationCode.Int32), constants.ToUser, arrangeMu)
+		// late joiners are let in by whichever replica their socket reaches, the lock is read from the session
+		if message.Event == constants.EventLockLobby {
+			qc.lockLobby(c, sessionId, message, arrangeMu)
+			continue
+		}
+
+		publishHostCommand(qc, sessionId.String(), message)
 	}
 }
 
-func sendSingleQuestion(c *websocket.Conn, qc *quizSocketController, wg *sync.WaitGroup, response *QuizSendResponse, session models.ActiveQuiz, question models.Question, lastQuestionTimeStamp sql.NullTime, chanSkipEvent chan bool, chanSkipTimer chan bool, chanPauseQuiz chan bool, totalQuestions int64, arrangeMu *sync.Mutex) {
-
-	defer wg.Done()
+func (d *sessionDriver) sendSingleQuestion(response *QuizSendResponse, question models.Question, lastQuestionTimeStamp sql.NullTime, totalQuestions int64) {
+	qc := d.qc
+	session := d.session
 
 	totalUserJoin, err := qc.userPlayedQuizModel.GetCountOfTotalJoinUsers(session.ID.String())
 	if err != nil {
@@ -1041,11 +910,18 @@ func sendSingleQuestion(c *websocket.Conn, qc *quizSocketController, wg *sync.Wa
 
 	// start counter if not any question running
 	if !lastQuestionTimeStamp.Valid {
+		d.setPhase(constants.SessionPhaseCounter, time.Now().Add(time.Duration(constants.Counter)*time.Second))
+
 		response.Component = constants.Question
 		response.Action = constants.ActionCounter
-		response.Data = map[string]int{"counter": constants.Counter, "count": constants.Count}
-		shareEvenWithUser(c, qc, response, constants.EventStartCount5, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAll, arrangeMu)
-		time.Sleep(time.Duration(constants.Counter) * time.Second)
+		response.Data = protocol.Counter{Counter: constants.Counter, Count: constants.Count}
+		shareEvenWithUser(d.host, qc, response, constants.EventStartCount5, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAll)
+
+		select {
+		case <-time.After(time.Duration(constants.Counter) * time.Second):
+		case <-d.done:
+			return
+		}
 
 		// Set the question start time to NOW (after counter finishes)
 		questionStartTime = time.Now()
@@ -1061,33 +937,53 @@ func sendSingleQuestion(c *websocket.Conn, qc *quizSocketController, wg *sync.Wa
 		questionStartTime = lastQuestionTimeStamp.Time
 	}
 
+	d.setPhase(constants.SessionPhaseQuestion, questionStartTime.Add(time.Duration(question.DurationInSeconds)*time.Second))
+
+	// players see the unit of a numeric question, never its target
+	numericAnswer, err := protocolNumericAnswer(question.Type, question.AnswerRules)
+	if err != nil {
+		qc.logger.Error("error while reading numeric answer rules", zap.Error(err))
+	}
+	unit := ""
+	if numericAnswer != nil {
+		unit = numericAnswer.Unit
+	}
+	// and the definitions of a matching question, never its pairs
+	matching, err := matchingRules(question.Type, question.AnswerRules)
+	if err != nil {
+		qc.logger.Error("error while reading matching rules", zap.Error(err))
+	}
+	var definitions map[string]string
+	if matching != nil {
+		definitions = matching.Definitions
+	}
+
 	// question sent
 	response.Action = constants.ActionSendQuestion
-	responseData := map[string]any{
-		"id":             question.ID,
-		"quiz_id":        question.QuizId,
-		"no":             question.OrderNumber,
-		"duration":       question.DurationInSeconds,
-		"start_time":     questionStartTime.Format(time.RFC3339),
-		"server_time":    time.Now().UTC().Format(time.RFC3339Nano),
-		"question":       question.Question,
-		"options":        question.Options,
-		"question_media": question.QuestionMedia,
-		"options_media":  question.OptionsMedia,
-		"resource":       question.Resource.String,
-		"totalQuestions": totalQuestions,
-		"totalJoinUser":  totalUserJoin,
-	}
-
-	response.Data = responseData
+	response.Data = &protocol.Question{
+		ID:             question.ID,
+		QuizID:         &question.QuizId,
+		No:             question.OrderNumber,
+		Duration:       question.DurationInSeconds,
+		StartTime:      questionStartTime.Format(time.RFC3339),
+		ServerTime:     time.Now().UTC().Format(time.RFC3339Nano),
+		Question:       question.Question,
+		Type:           question.Type,
+		Options:        question.Options,
+		QuestionMedia:  question.QuestionMedia,
+		OptionsMedia:   question.OptionsMedia,
+		Resource:       question.Resource.String,
+		TotalQuestions: totalQuestions,
+		TotalJoinUser:  &totalUserJoin,
+		Unit:           unit,
+		Definitions:    definitions,
+	}
 	if !lastQuestionTimeStamp.Valid { // handling new question
-		shareEvenWithUser(c, qc, response, constants.EventSendQuestion, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAll, arrangeMu)
+		shareEvenWithUser(d.host, qc, response, constants.EventSendQuestion, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAll)
 	} else { // handling running question
-		shareEvenWithUser(c, qc, response, constants.EventSendQuestion, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAdmin, arrangeMu)
+		shareEvenWithUser(d.host, qc, response, constants.EventSendQuestion, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAdmin)
 	}
 
-	wgForQuestion := &sync.WaitGroup{}
-	wgForQuestion.Add(1)
 	var duration int
 	if !lastQuestionTimeStamp.Valid { // new question
 		duration = question.DurationInSeconds
@@ -1097,8 +993,11 @@ func sendSingleQuestion(c *websocket.Conn, qc *quizSocketController, wg *sync.Wa
 			duration = 1
 		}
 	}
-	go handleAnswerSubmission(c, qc, session, question.ID, duration, wgForQuestion, chanSkipEvent, response, arrangeMu)
-	wgForQuestion.Wait()
+	d.handleAnswerSubmission(question.ID, question.Type, duration, response)
+
+	if d.isStopped() {
+		return
+	}
 
 	// update current status to deactivate
 	err = qc.quizModel.UpdateCurrentQuestion(session.ID, question.ID, false)
@@ -1110,7 +1009,7 @@ func sendSingleQuestion(c *websocket.Conn, qc *quizSocketController, wg *sync.Wa
 	// score-board rendering
 	response.Component = constants.Score
 	response.Action = constants.ActionShowScore
-	userRankBoard, err := qc.userPlayedQuizModel.GetRank(session.ID, question.ID)
+	userRankBoard, err := qc.rankBoard(session.ID, question.ID)
 	if err != nil {
 		qc.logger.Error("error during get userRankBoard", zap.Error(err))
 		return
@@ -1119,6 +1018,10 @@ func sendSingleQuestion(c *websocket.Conn, qc *quizSocketController, wg *sync.Wa
 	if err != nil {
 		qc.logger.Error("error during get userResponses", zap.Error(err))
 	}
+	teamRankBoard, err := qc.sessionTeamModel.GetTeamRank(session.ID)
+	if err != nil {
+		qc.logger.Error("error during get teamRankBoard", zap.Error(err))
+	}
 
 	scoreboardMaxDurationEnv := qc.appConfig.Quiz.ScoreboardMaxDuration
 	scoreboardMaxDuration := 20
@@ -1129,50 +1032,66 @@ func sendSingleQuestion(c *websocket.Conn, qc *quizSocketController, wg *sync.Wa
 		}
 	}
 
-	response.Data = map[string]any{
-		"question_no":    question.OrderNumber,
-		"quiz_id":        question.QuizId,
-		"rankList":       userRankBoard,
-		"question":       question.Question,
-		"answers":        question.Answers,
-		"options":        question.Options,
-		"question_media": question.QuestionMedia,
-		"options_media":  question.OptionsMedia,
-		"resource":       question.Resource.String,
-		"duration":       scoreboardMaxDuration,
-		"totalQuestions": totalQuestions,
-		"userResponses":  userResponses,
-	}
-	shareEvenWithUser(c, qc, response, constants.EventShowScore, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAdmin, arrangeMu)
-
-	response.Data = map[string]any{
-		"question_no":    question.OrderNumber,
-		"quiz_id":        question.QuizId,
-		"rankList":       userRankBoard,
-		"question":       question.Question,
-		"answers":        question.Answers,
-		"options":        question.Options,
-		"question_media": question.QuestionMedia,
-		"options_media":  question.OptionsMedia,
-		"resource":       question.Resource.String,
-		"duration":       scoreboardMaxDuration,
-		"totalQuestions": totalQuestions,
-	}
-	shareEvenWithUser(c, qc, response, constants.EventShowScore, session.ID.String(), int(session.InvitationCode.Int32), constants.ToUser, arrangeMu)
-
-	wgForSkipTimer := &sync.WaitGroup{}
-	wgForSkipTimer.Add(1)
+	d.setPhase(constants.SessionPhaseScoreboard, time.Now().Add(time.Duration(scoreboardMaxDuration)*time.Second))
+
+	adminResponses := protocolResponses(userResponses)
+	scoreboard := protocol.Scoreboard{
+		QuestionNo:     question.OrderNumber,
+		QuizID:         &question.QuizId,
+		RankList:       protocolRanks(userRankBoard),
+		TeamRankList:   protocolTeamRanks(teamRankBoard),
+		Question:       question.Question,
+		Answers:        question.Answers,
+		Options:        question.Options,
+		QuestionMedia:  question.QuestionMedia,
+		OptionsMedia:   question.OptionsMedia,
+		Resource:       question.Resource.String,
+		Duration:       scoreboardMaxDuration,
+		TotalQuestions: totalQuestions,
+		UserResponses:  &adminResponses,
+	}
+
+	rules, err := shortAnswerRules(question.Type, question.AnswerRules)
+	if err != nil {
+		qc.logger.Error("error while reading short answer rules", zap.Error(err))
+	}
+	if rules != nil {
+		wrongAnswers := protocolWrongAnswers(rules, userResponses)
+		scoreboard.AcceptedAnswers = rules.Accepted
+		scoreboard.WrongAnswers = &wrongAnswers
+	}
+	scoreboard.NumericAnswer = numericAnswer
+	if matching != nil {
+		scoreboard.Definitions = matching.Definitions
+		scoreboard.Pairs = matching.Pairs
+	}
+	// a word cloud earns no points, the cloud is shown in place of the ranks
+	if question.Type == constants.WordCloud {
+		scoreboard.WordCloud = protocolWordCloud(question.ID, userResponses, qc.wordCloudOptions())
+		scoreboard.RankList = []protocol.Rank{}
+		scoreboard.TeamRankList = []protocol.TeamRank{}
+	}
+	response.Data = scoreboard
+	shareEvenWithUser(d.host, qc, response, constants.EventShowScore, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAdmin)
+
+	// players get the question id to shuffle the options with, and not the answers of the others
+	scoreboard.QuestionID = &question.ID
+	scoreboard.UserResponses = nil
+	scoreboard.WrongAnswers = nil
+	response.Data = scoreboard
+	shareEvenWithUser(d.host, qc, response, constants.EventShowScore, session.ID.String(), int(session.InvitationCode.Int32), constants.ToUser)
 
 	// skip timer
-	go handleSkipTimer(c, qc, wgForSkipTimer, response, session, chanSkipTimer, chanPauseQuiz, scoreboardMaxDuration, arrangeMu)
-	wgForSkipTimer.Wait()
+	d.handleSkipTimer(response, scoreboardMaxDuration)
 }
 
-func terminateQuiz(c *websocket.Conn, qc *quizSocketController, response *QuizSendResponse, session models.ActiveQuiz, arrangeMu *sync.Mutex) {
+func (d *sessionDriver) terminateQuiz(response *QuizSendResponse) {
+	qc := d.qc
+	session := d.session
 
 	response.Component = constants.Score
 	response.Data = constants.ActionTerminateQuiz
-	shareEvenWithUser(c, qc, response, constants.EventTerminateQuiz, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAll, arrangeMu)
+	shareEvenWithUser(d.host, qc, response, constants.EventTerminateQuiz, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAll)
 
 	err := qc.activeQuizModel.Deactivate(session.ID)
 	if err != nil {
@@ -1180,9 +1099,11 @@ func terminateQuiz(c *websocket.Conn, qc *quizSocketController, response *QuizSe
 		return
 	}
 
+	qc.closeLeaderboard(session.ID)
+
 	qc.logger.Info("terminateQuiz")
 	// here logic of publishing data of user to admin that terminate quiz so no need to listen for joining users
-	err = qc.redis.PubSubModel.Client.Publish(qc.redis.PubSubModel.Ctx, constants.EventTerminateQuiz, constants.EventTerminateQuiz).Err()
+	err = qc.pubsub.Publish(constants.EventTerminateQuiz, constants.EventTerminateQuiz)
 	if err != nil {
 		qc.logger.Error(fmt.Sprintf("socket error while terminationg quiz %s", constants.ActionTerminateQuiz), zap.Error(err))
 		return
@@ -1194,24 +1115,24 @@ func terminateQuiz(c *websocket.Conn, qc *quizSocketController, response *QuizSe
 // shareEvenWithUser, and exists for callers (like the HTTP Terminate handler) that
 // have no websocket conn and therefore cannot invoke shareEvenWithUser directly.
 func publishTerminateToPlayers(qc *quizSocketController, sessionId string) {
-	payload := map[string]any{
-		"event":    constants.EventTerminateQuiz,
-		"response": &QuizSendResponse{Component: constants.Score, Data: constants.ActionTerminateQuiz},
-	}
-	data, err := json.Marshal(payload)
+	data, err := json.Marshal(playerMessage{
+		Event:    constants.EventTerminateQuiz,
+		Response: QuizSendResponse{Component: constants.Score, Data: constants.ActionTerminateQuiz},
+	})
 	if err != nil {
 		qc.logger.Error("error marshaling terminate payload for players", zap.Error(err))
 		return
 	}
 
-	err = qc.redis.PubSubModel.Client.Publish(qc.redis.PubSubModel.Ctx, sessionId, data).Err()
+	err = qc.pubsub.Publish(sessionId, data)
 	if err != nil {
 		qc.logger.Error("error publishing terminate event to players", zap.Error(err))
 	}
 }
 
-func handleSkipTimer(c *websocket.Conn, qc *quizSocketController, wg *sync.WaitGroup, response *QuizSendResponse, session models.ActiveQuiz, chanSkipTimer chan bool, chanPauseQuiz chan bool, scoreboardMaxDuration int, arrangeMu *sync.Mutex) {
-	defer wg.Done()
+func (d *sessionDriver) handleSkipTimer(response *QuizSendResponse, scoreboardMaxDuration int) {
+	qc := d.qc
+	session := d.session
 
 	remainingTime := time.Duration(scoreboardMaxDuration) * time.Second
 	startTime := time.Now()
@@ -1220,16 +1141,21 @@ func handleSkipTimer(c *websocket.Conn, qc *quizSocketController, wg *sync.WaitG
 
 	for {
 		select {
+		case <-d.done:
+			return
 		case <-isTimeout.C:
 			if !timerPaused {
 				return
 			}
-		case isSkip := <-chanSkipTimer:
+		case isSkip := <-d.chanSkipTimer:
 			if isSkip {
 				return
 			}
-		case isPause := <-chanPauseQuiz:
+		case isPause := <-d.chanPauseQuiz:
 			if isPause {
+				if timerPaused {
+					continue
+				}
 				// Stop the timer and calculate the remaining time
 				if !isTimeout.Stop() {
 					// drain the channel if the timer has expired
@@ -1237,51 +1163,67 @@ func handleSkipTimer(c *websocket.Conn, qc *quizSocketController, wg *sync.WaitG
 				}
 				remainingTime -= time.Since(startTime)
 				timerPaused = true
+				d.setPaused(true, remainingTime)
 
 				// send event to the user
 				response.Component = constants.Score
 				response.Data = constants.EventPauseQuiz
-				shareEvenWithUser(c, qc, response, constants.EventPauseQuiz, session.ID.String(), int(session.InvitationCode.Int32), constants.ToUser, arrangeMu)
+				shareEvenWithUser(d.host, qc, response, constants.EventPauseQuiz, session.ID.String(), int(session.InvitationCode.Int32), constants.ToUser)
 			} else {
 				// Resume with the remaining time
 				if timerPaused {
 					startTime = time.Now()
 					isTimeout.Reset(remainingTime)
 					timerPaused = false
+					d.setPaused(false, remainingTime)
 
 					// send event to the user
 					response.Component = constants.Score
 					response.Data = constants.EventResumeQuiz
-					shareEvenWithUser(c, qc, response, constants.EventResumeQuiz, session.ID.String(), int(session.InvitationCode.Int32), constants.ToUser, arrangeMu)
+					shareEvenWithUser(d.host, qc, response, constants.EventResumeQuiz, session.ID.String(), int(session.InvitationCode.Int32), constants.ToUser)
 				}
 			}
 		}
 	}
 }
 
-func handleAnswerSubmission(c *websocket.Conn, qc *quizSocketController, session models.ActiveQuiz, questionId uuid.UUID, duration int, wg *sync.WaitGroup, chanSkipEvent chan bool, response *QuizSendResponse, arrangeMu *sync.Mutex) {
-	defer wg.Done()
+func (d *sessionDriver) handleAnswerSubmission(questionId uuid.UUID, questionType int, duration int, response *QuizSendResponse) {
+	qc := d.qc
+	session := d.session
 
 	isTimeout := time.NewTicker(time.Duration(duration) * time.Second)
+	defer isTimeout.Stop()
 
-	pubsub := qc.redis.PubSubModel.Client.Subscribe(qc.redis.PubSubModel.Ctx, fmt.Sprintf("%s-%s", constants.ChannelSetAnswer, session.ID.String()))
-	defer func() {
-		if pubsub != nil {
-			err := pubsub.Unsubscribe(qc.redis.PubSubModel.Ctx, fmt.Sprintf("%s-%s", constants.ChannelSetAnswer, session.ID.String()))
-			if err != nil {
-				qc.logger.Error("unsubscribe failed", zap.Error(err))
-			}
-			pubsub.Close()
-		}
-	}()
+	subscription, err := qc.pubsub.Subscribe(fmt.Sprintf("%s-%s", constants.ChannelSetAnswer, session.ID.String()))
+	if err != nil {
+		qc.logger.Error("subscribe failed", zap.Error(err))
+		return
+	}
+	defer subscription.Close()
 
-	ch := pubsub.Channel()
+	ch := subscription.Channel()
+
+	// the words of a cloud are counted again at most once per wordCloudInterval, not on every answer
+	var cloudTick <-chan time.Time
+	isCloudStale := false
+	if questionType == constants.WordCloud {
+		cloudTicker := time.NewTicker(wordCloudInterval)
+		defer cloudTicker.Stop()
+		cloudTick = cloudTicker.C
+	}
 
 	for {
 		select {
+		case <-d.done:
+			return
 		case <-isTimeout.C:
 			return
-		case isForce := <-chanSkipEvent:
+		case <-cloudTick:
+			if isCloudStale {
+				d.sendWordCloud(questionId)
+				isCloudStale = false
+			}
+		case isForce := <-d.chanSkipEvent:
 			if isForce {
 				return
 			} else {
@@ -1294,11 +1236,11 @@ func handleAnswerSubmission(c *websocket.Conn, qc *quizSocketController, session
 					return
 				} else { // send warning if all participant not given answer
 					response.Data = constants.WarnSkip
-					shareEvenWithUser(c, qc, response, constants.EventSkipAsked, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAdmin, arrangeMu)
+					shareEvenWithUser(d.host, qc, response, constants.EventSkipAsked, session.ID.String(), int(session.InvitationCode.Int32), constants.ToAdmin)
 				}
 			}
 		case msg := <-ch:
-			user := models.User{}
+			user := protocol.AnsweredPlayer{}
 
 			err := json.Unmarshal([]byte(msg.Payload), &user)
 			if err != nil {
@@ -1308,20 +1250,43 @@ func handleAnswerSubmission(c *websocket.Conn, qc *quizSocketController, session
 			response.Data = user
 			response.Action = constants.ActionAnserSubmittedByUser
 
-			err = func() error {
-				arrangeMu.Lock()
-				defer arrangeMu.Unlock()
-				return utils.JSONSuccessWs(c, constants.EventAnswerSubmittedByUser, response)
-			}()
-
+			err = d.host.send(constants.EventAnswerSubmittedByUser, *response)
 			if err != nil {
 				qc.logger.Error(fmt.Sprintf("socket error sending event: %s event, %s action, %v user", constants.EventSendQuestion, response.Action, user), zap.Error(err))
 			}
+
+			isCloudStale = questionType == constants.WordCloud
 		}
 	}
 }
 
+// wordCloudInterval is how often the host gets the words of a running word cloud question while answers
+// come in, the scoreboard after the question has them all anyway
+const wordCloudInterval = time.Second
+
+// sendWordCloud sends the host the words typed so far to the running word cloud question. Each cloud
+// replaces the previous one, so they are not kept for replay.
+func (d *sessionDriver) sendWordCloud(questionId uuid.UUID) {
+	qc := d.qc
+
+	responses, err := qc.userQuizResponseModel.GetUsersResponses(d.session.ID, questionId)
+	if err != nil {
+		qc.logger.Error("error during get userResponses for the word cloud", zap.Error(err))
+		return
+	}
+
+	err = d.host.write(constants.EventWordCloud, QuizSendResponse{
+		Component: constants.Question,
+		Action:    constants.ActionWordCloud,
+		Data:      protocolWordCloud(questionId, responses, qc.wordCloudOptions()),
+	})
+	if err != nil {
+		qc.logger.Error(fmt.Sprintf("socket error sending event: %s event, %s action", constants.EventWordCloud, constants.ActionWordCloud), zap.Error(err))
+	}
+}
+
 func (qc *quizSocketController) SetAnswer(c *fiber.Ctx) error {
+	receivedAt := time.Now()
 	currentQuiz := c.Query(constants.CurrentUserQuiz)
 	sessionId := c.Query(constants.SessionIDParam)
 
@@ -1350,73 +1315,181 @@ func (qc *quizSocketController) SetAnswer(c *fiber.Ctx) error {
 		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
 	}
 
+	if err := qc.recordAnswer(user, currentQuizId, sessionId, answer, receivedAt); err != nil {
+		if err.status >= http.StatusInternalServerError {
+			return utils.JSONError(c, err.status, err.message)
+		}
+		return utils.JSONFail(c, err.status, err.message)
+	}
+
+	return utils.JSONSuccess(c, http.StatusAccepted, nil)
+}
+
+// answerError is why an answer was refused, status is the http status it is reported with
+type answerError struct {
+	status  int
+	message string
+}
+
+// recordAnswer scores the answer of a player to the running question and lets the host know it arrived,
+// it is shared by the http endpoint and the submit_answer event of the player socket
+func (qc *quizSocketController) recordAnswer(user models.User, currentQuizId uuid.UUID, sessionId string, answer structs.ReqAnswerSubmit, receivedAt time.Time) *answerError {
 	validate := validator.New()
-	err = validate.Struct(answer)
+	err := validate.Struct(answer)
+	if err != nil {
+		return &answerError{http.StatusBadRequest, utils.ValidatorErrorString(err)}
+	}
+
+	// a banned or kicked player keeps no say in the session, whichever way the answer comes in
+	sessionUUID, err := uuid.Parse(sessionId)
+	if err != nil {
+		return &answerError{http.StatusBadRequest, constants.ErrSessionNotFound}
+	}
+	participant, err := qc.userPlayedQuizModel.GetParticipant(user.ID, sessionUUID)
 	if err != nil {
-		return utils.JSONFail(c, http.StatusBadRequest, utils.ValidatorErrorString(err))
+		if err == sql.ErrNoRows {
+			return &answerError{http.StatusBadRequest, constants.ErrQuizNotFound}
+		}
+		qc.logger.Error("error during answer submit get participant", zap.Error(err))
+		return &answerError{http.StatusInternalServerError, constants.UnknownError}
+	}
+	if participant.ID != currentQuizId {
+		return &answerError{http.StatusBadRequest, constants.ErrQuizNotFound}
+	}
+	if participant.IsBanned {
+		return &answerError{http.StatusForbidden, constants.ErrPlayerBanned}
 	}
 
 	// check for question is active or not to receive answers
-	currentQuestion, err := qc.userPlayedQuizModel.GetCurrentActiveQuestion(sessionId)
+	currentQuestion, err := qc.userPlayedQuizModel.GetActiveQuestion(sessionId)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			qc.logger.Error("error during answer submit get current active question", zap.Any("answers", answer), zap.Any("current_quiz_id", currentQuizId))
-			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrAnswerSubmit)
+			return &answerError{http.StatusBadRequest, constants.ErrAnswerSubmit}
 		}
 		qc.logger.Error("error during answer submit", zap.Error(err))
-		return utils.JSONFail(c, http.StatusBadRequest, constants.UnknownError)
+		return &answerError{http.StatusBadRequest, constants.UnknownError}
 	}
 
-	if currentQuestion != answer.QuestionId {
+	if currentQuestion.ID != answer.QuestionId {
 		qc.logger.Error(constants.ErrQuestionNotActive)
-		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrQuestionNotActive)
+		return &answerError{http.StatusBadRequest, constants.ErrQuestionNotActive}
+	}
+
+	if currentQuestion.ShuffleOptions {
+		if err := qc.canonicalAnswer(currentQuizId, &answer); err != nil {
+			qc.logger.Error("error while mapping shuffled answer", zap.Error(err))
+			return &answerError{http.StatusInternalServerError, constants.UnknownError}
+		}
 	}
 
-	answers, answerPoints, answerDurationInSeconds, questionType, err := qc.questionModel.GetAnswersPointsDurationType(answer.QuestionId.String())
+	answerKey, err := qc.questionModel.GetAnswerKey(answer.QuestionId.String())
 	if err != nil {
 		qc.logger.Error("error while get answer, points, duration and type")
-		return utils.JSONFail(c, http.StatusBadRequest, "error while get answer, points, duration and type")
+		return &answerError{http.StatusBadRequest, "error while get answer, points, duration and type"}
+	}
+
+	// the time bonus is earned on the server clock, the time reported by the player is only kept for the analysis
+	timing := models.AnswerTiming{ClientResponseTime: sql.NullInt32{Int32: int32(answer.ResponseTime), Valid: true}}
+	if currentQuestion.DeliveredAt.Valid {
+		reconciled := utils.ReconcileResponseTime(answer.ResponseTime, currentQuestion.DeliveredAt.Time, receivedAt, answerKey.DurationInSeconds, qc.appConfig.Quiz.ResponseTolerance(), qc.appConfig.Quiz.ResponseAnomalyThreshold())
+		answer.ResponseTime = reconciled.ResponseTime
+		timing.IsTimeAnomaly = reconciled.IsAnomaly
+		if reconciled.IsAnomaly {
+			qc.logger.Warn("response time reported by player is off from the server time", zap.String("user_id", user.ID), zap.Any("question_id", answer.QuestionId), zap.Int("client_response_time", reconciled.ClientResponseTime), zap.Int("server_response_time", reconciled.ServerResponseTime))
+		}
 	}
 
 	// calculate points
-	points, score := utils.CalculatePointsAndScore(answer, answers, answerPoints, answerDurationInSeconds, questionType)
+	points, score := utils.ScoreAnswer(answer, answerKey)
 
 	streakCount, err := qc.userPlayedQuizModel.GetStreakCount(currentQuizId, answer.QuestionId)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			qc.logger.Error(constants.ErrGetStreakCount, zap.Error(err))
-			return utils.JSONError(c, http.StatusBadRequest, constants.ErrQuizNotFound)
+			return &answerError{http.StatusBadRequest, constants.ErrQuizNotFound}
 		}
 		qc.logger.Error(constants.ErrGetStreakCount, zap.Error(err))
-		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrGetStreakCount)
+		return &answerError{http.StatusInternalServerError, constants.ErrGetStreakCount}
 	}
 
 	// add streak score and update streak also
 	finalScore, newSreakCount := utils.CalculateStreakScore(streakCount, score)
+	if answerKey.Type == constants.WordCloud {
+		// a word cloud is not scored, the streak carries over it
+		newSreakCount = streakCount
+	}
 
 	// Submit answer
-	if err := qc.userQuizResponseModel.SubmitAnswer(currentQuizId, answer, points, finalScore, newSreakCount); err != nil {
+	if err := qc.userQuizResponseModel.SubmitAnswer(currentQuizId, answer, points, finalScore, newSreakCount, timing); err != nil {
 		if err == sql.ErrNoRows {
-			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrAnswerAlreadySubmitted)
+			return &answerError{http.StatusBadRequest, constants.ErrAnswerAlreadySubmitted}
 		}
 		qc.logger.Error("error during answer submit", zap.Error(err))
-		return utils.JSONFail(c, http.StatusInternalServerError, constants.UnknownError)
+		return &answerError{http.StatusInternalServerError, constants.UnknownError}
 	}
+	qc.scoreOnLeaderboard(sessionId, user.ID, finalScore, points)
 
 	// Publish to Redis in a goroutine
 	go func() {
-		data, err := json.Marshal(user)
+		data, err := json.Marshal(protocol.AnsweredPlayer{ID: user.ID, FirstName: user.FirstName, Username: user.Username, ImageKey: user.ImageKey})
 		if err != nil {
 			qc.logger.Error("Error marshaling user data", zap.Error(err))
 			return
 		}
 
-		if err := qc.redis.PubSubModel.Client.Publish(qc.redis.PubSubModel.Ctx, fmt.Sprintf("%s-%s", constants.ChannelSetAnswer, sessionId), data).Err(); err != nil {
+		if err := qc.pubsub.Publish(fmt.Sprintf("%s-%s", constants.ChannelSetAnswer, sessionId), data); err != nil {
 			qc.logger.Error("Error publishing answer to Redis", zap.Error(err))
 		}
 	}()
 
-	return utils.JSONSuccess(c, http.StatusAccepted, nil)
+	return nil
+}
+
+// handleSocketAnswer records an answer sent with the submit_answer event and replies on the same socket
+func handleSocketAnswer(client *hub.Client, qc *quizSocketController, user models.User, session models.ActiveQuiz, data any) {
+	receivedAt := time.Now()
+	response := QuizSendResponse{Component: constants.Question, Action: constants.ActionSubmitAnswer}
+
+	var answer structs.ReqAnswerSubmit
+	answerErr := func() *answerError {
+		body, err := json.Marshal(data)
+		if err != nil {
+			return &answerError{http.StatusBadRequest, err.Error()}
+		}
+		if err := json.Unmarshal(body, &answer); err != nil {
+			return &answerError{http.StatusBadRequest, err.Error()}
+		}
+
+		// the participation is looked up for the socket user, it is never taken from the message
+		currentQuizId, err := qc.userPlayedQuizModel.GetUserPlayedQuizId(user.ID, session.ID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return &answerError{http.StatusBadRequest, constants.ErrQuizNotFound}
+			}
+			qc.logger.Error("error while getting user played quiz for socket answer", zap.Error(err))
+			return &answerError{http.StatusInternalServerError, constants.UnknownError}
+		}
+
+		return qc.recordAnswer(user, currentQuizId, session.ID.String(), answer, receivedAt)
+	}()
+
+	err := func() error {
+		if answerErr == nil {
+			response.Data = protocol.AnswerAck{ID: answer.QuestionId}
+			return client.Send(constants.EventSubmitAnswer, response)
+		}
+
+		response.Data = protocol.AnswerAck{ID: answer.QuestionId, Message: answerErr.message}
+		if answerErr.status >= http.StatusInternalServerError {
+			return client.Error(constants.EventSubmitAnswer, response)
+		}
+		return client.Fail(constants.EventSubmitAnswer, response)
+	}()
+
+	if err != nil {
+		qc.logger.Error(fmt.Sprintf("socket error send answer acknowledgement: %s event, %s action", constants.EventSubmitAnswer, response.Action), zap.Error(err))
+	}
 }
 
 func (ctrl *quizSocketController) ListActiveSessions(c *fiber.Ctx) error {
@@ -1462,6 +1535,7 @@ func (ctrl *quizSocketController) Terminate(c *fiber.Ctx) error {
 	// Notify every connected player in real time before tearing the session down, so
 	// their play screen receives terminate_quiz and routes to the scoreboard/home.
 	publishTerminateToPlayers(ctrl, session.ID.String())
+	ctrl.stopDriver(session.ID.String())
 
 	err = ctrl.activeQuizModel.Deactivate(session.ID)
 	if err != nil {
@@ -1473,12 +1547,13 @@ func (ctrl *quizSocketController) Terminate(c *fiber.Ctx) error {
 		return utils.JSONError(c, http.StatusInternalServerError, constants.UnknownError)
 	}
 
-	if err := ctrl.redis.PubSubModel.Client.Del(ctrl.redis.PubSubModel.Ctx, session.ID.String()).Err(); err != nil {
+	if err := ctrl.pubsub.Del(rosterKey(session.ID.String())); err != nil {
 		ctrl.logger.Error("error deleting session roster from redis", zap.Error(err))
 	}
+	ctrl.closeLeaderboard(session.ID)
 
 	// Stop any host/join-watcher goroutines still listening for late joiners.
-	err = ctrl.redis.PubSubModel.Client.Publish(ctrl.redis.PubSubModel.Ctx, constants.EventTerminateQuiz, constants.EventTerminateQuiz).Err()
+	err = ctrl.pubsub.Publish(constants.EventTerminateQuiz, constants.EventTerminateQuiz)
 	if err != nil {
 		ctrl.logger.Error("error publishing global terminate event", zap.Error(err))
 	}
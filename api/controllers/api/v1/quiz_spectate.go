@@ -0,0 +1,195 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Improwised/jovvix/api/constants"
+	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/Improwised/jovvix/api/utils"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Spectate is a read-only socket for a projector: it gets the events players get without taking part in the
+// quiz, together with the lobby roster and the live answer count of the running question. Only the host may
+// spectate a session that is not open to everybody.
+func (qc *quizSocketController) Spectate(c *websocket.Conn) {
+	var spectateMu sync.Mutex
+	sendHello(c, qc, protocol.SocketSpectate, lockedSocket{c, &spectateMu})
+
+	response := QuizSendResponse{
+		Component: constants.Waiting,
+		Action:    constants.ActionJoinQuiz,
+		Data:      "",
+	}
+
+	invitationCode := quizUtilsHelper.GetString(c.Locals(constants.QuizSessionInvitationCode))
+
+	session, err := qc.activeQuizModel.GetSessionByCode(invitationCode)
+	if err == nil && session.IsAssignment() {
+		err = fmt.Errorf(constants.ErrAssignmentIsSelfPaced)
+	}
+	// the invitation code alone must not show a protected session, a passcode or allowlist keeps out of it
+	if err == nil && session.AccessMode != constants.SessionAccessOpen && session.AdminID != quizUtilsHelper.GetString(c.Locals(constants.ContextUid)) {
+		err = fmt.Errorf(constants.ErrSpectateProtected)
+	}
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			response.Data = constants.ErrInvitationCodeNotFound
+		case err.Error() == constants.ErrAssignmentIsSelfPaced, err.Error() == constants.ErrSpectateProtected:
+			response.Data = err.Error()
+		default:
+			response.Data = constants.UnknownError
+			qc.logger.Error("error in invitation code for spectator", zap.Error(err))
+		}
+
+		wsErr := func() error {
+			spectateMu.Lock()
+			defer spectateMu.Unlock()
+			return utils.JSONFailWs(c, constants.EventJoinQuiz, response)
+		}()
+		if wsErr != nil {
+			qc.logger.Error(fmt.Sprintf("socket error on spectate: %s event, %s action", constants.EventJoinQuiz, response.Action), zap.Error(wsErr))
+		}
+
+		c.Close()
+		return
+	}
+
+	isSpectatorConnected := make(chan bool, 1)
+
+	defer func() {
+		c.Close()
+		qc.logger.Info("connection closed by spectator")
+	}()
+
+	go func() {
+		for {
+			message := QuizReceiveResponse{}
+			if err := c.ReadJSON(&message); err != nil {
+				isSpectatorConnected <- false
+				return
+			}
+
+			if message.Event == constants.EventWebsocketClose {
+				isSpectatorConnected <- false
+				return
+			}
+
+			if message.Event == constants.EventPing {
+				err := func() error {
+					spectateMu.Lock()
+					defer spectateMu.Unlock()
+					return utils.JSONSuccessWs(c, constants.EventPong, "")
+				}()
+				if err != nil {
+					qc.logger.Error("error while sending pong message to spectator", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	// a spectator has no player of its own, the state it gets is the one every player shares
+	sendPlayerState(lockedSocket{c, &spectateMu}, qc, session, models.User{})
+	if session.HasTeams() {
+		sendTeamRoster(lockedSocket{c, &spectateMu}, qc, session.ID)
+	}
+	sendAnswerCount(c, qc, session.ID, &spectateMu)
+
+	handleSpectator(c, qc, session, isSpectatorConnected, &spectateMu)
+}
+
+// handleSpectator forwards the events of the session to a spectator until the quiz is terminated
+func handleSpectator(c *websocket.Conn, qc *quizSocketController, session models.ActiveQuiz, isSpectatorConnected chan bool, spectateMu *sync.Mutex) {
+	sessionId := session.ID.String()
+	userJoinChannel := fmt.Sprintf("%s-%s", constants.ChannelUserJoin, sessionId)
+	userDisconnectChannel := fmt.Sprintf("%s-%s", constants.ChannelUserDisconnect, sessionId)
+	setAnswerChannel := fmt.Sprintf("%s-%s", constants.ChannelSetAnswer, sessionId)
+
+	subscription, err := qc.pubsub.Subscribe(sessionId, userJoinChannel, userDisconnectChannel, setAnswerChannel)
+	if err != nil {
+		qc.logger.Error("subscribe failed", zap.Error(err))
+		return
+	}
+	defer subscription.Close()
+
+	// the players waiting in the lobby, the same list the host sees
+	lobby := newLobbyView(c, spectateMu)
+	if err := lobby.reset(qc.lobbyRoster(sessionId)); err != nil {
+		qc.logger.Error("error while sending initial roster to spectator", zap.Error(err))
+	}
+
+	ch := subscription.Channel()
+	for {
+		select {
+		case isConnected := <-isSpectatorConnected:
+			if !isConnected {
+				return
+			}
+		case msg := <-ch:
+			switch msg.Channel {
+			case setAnswerChannel:
+				sendAnswerCount(c, qc, session.ID, spectateMu)
+			case userJoinChannel, userDisconnectChannel:
+				if err := lobby.apply(msg.Payload); err != nil {
+					qc.logger.Error("error while sending roster to spectator", zap.Error(err))
+				}
+			default:
+				message := playerMessage{}
+				if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+					qc.logger.Error("error while unmarshaling session event for spectator", zap.Error(err))
+					continue
+				}
+
+				err := func() error {
+					spectateMu.Lock()
+					defer spectateMu.Unlock()
+					return utils.JSONSuccessWs(c, message.Event, message.Response)
+				}()
+				if err != nil {
+					qc.logger.Error(fmt.Sprintf("socket error send event to spectator: %s event", message.Event), zap.Error(err))
+				}
+
+				switch message.Event {
+				case constants.EventSendQuestion:
+					sendAnswerCount(c, qc, session.ID, spectateMu)
+				case constants.EventTerminateQuiz:
+					return
+				}
+			}
+		}
+	}
+}
+
+// sendAnswerCount sends how many players answered the running question, nothing is sent between questions
+func sendAnswerCount(c *websocket.Conn, qc *quizSocketController, sessionId uuid.UUID, spectateMu *sync.Mutex) {
+	questionId, err := qc.userPlayedQuizModel.GetCurrentActiveQuestion(sessionId.String())
+	if err != nil {
+		if err != sql.ErrNoRows {
+			qc.logger.Error("error while getting current question for answer count", zap.Error(err))
+		}
+		return
+	}
+
+	count, err := qc.userQuizResponseModel.GetAnswerCount(sessionId, questionId)
+	if err != nil {
+		qc.logger.Error("error while counting answers", zap.Error(err))
+		return
+	}
+
+	err = func() error {
+		spectateMu.Lock()
+		defer spectateMu.Unlock()
+		return utils.JSONSuccessWs(c, constants.EventAnswerCount, QuizSendResponse{Component: constants.Question, Action: constants.ActionAnswerCount, Data: protocol.AnswerCount{QuestionID: count.QuestionID, Answered: count.Answered, Total: count.Total}})
+	}()
+	if err != nil {
+		qc.logger.Error(fmt.Sprintf("socket error send answer count: %s event, %s action", constants.EventAnswerCount, constants.ActionAnswerCount), zap.Error(err))
+	}
+}
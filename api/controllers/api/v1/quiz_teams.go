@@ -0,0 +1,292 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Improwised/jovvix/api/constants"
+	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
+	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/utils"
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// publishTeamRoster shares the teams of a session with its players and with the host waiting in the lobby
+func publishTeamRoster(qc *quizSocketController, sessionId uuid.UUID) {
+	rosters, err := qc.sessionTeamModel.ListTeams(sessionId)
+	if err != nil {
+		qc.logger.Error("error while listing teams to publish", zap.Error(err))
+		return
+	}
+
+	teams := protocolTeams(rosters)
+	data, err := json.Marshal(teams)
+	if err != nil {
+		qc.logger.Error("error while marshaling team roster", zap.Error(err))
+		return
+	}
+
+	response := QuizSendResponse{Component: constants.Waiting, Action: constants.ActionTeamRoster, Data: teams}
+	payload, err := json.Marshal(playerMessage{Event: constants.EventTeamRoster, Response: response})
+	if err != nil {
+		qc.logger.Error("error while marshaling team roster event", zap.Error(err))
+		return
+	}
+
+	if err := qc.pubsub.Publish(sessionId.String(), payload); err != nil {
+		qc.logger.Error(fmt.Sprintf("socket error publishing event: %s event, %s action", constants.EventTeamRoster, response.Action), zap.Error(err))
+	}
+
+	if err := qc.pubsub.Publish(fmt.Sprintf("%s-%s", constants.ChannelTeamRoster, sessionId), data); err != nil {
+		qc.logger.Error(fmt.Sprintf("socket error publishing event: %s event, %s action", constants.EventTeamRoster, response.Action), zap.Error(err))
+	}
+}
+
+// sendTeamRoster sends the teams of a session over a single socket
+func sendTeamRoster(w frameWriter, qc *quizSocketController, sessionId uuid.UUID) {
+	rosters, err := qc.sessionTeamModel.ListTeams(sessionId)
+	if err != nil {
+		qc.logger.Error("error while listing teams to send", zap.Error(err))
+		return
+	}
+
+	if len(rosters) == 0 {
+		return
+	}
+
+	err = w.Send(constants.EventTeamRoster, QuizSendResponse{Component: constants.Waiting, Action: constants.ActionTeamRoster, Data: protocolTeams(rosters)})
+
+	if err != nil {
+		qc.logger.Error(fmt.Sprintf("socket error send team roster: %s event, %s action", constants.EventTeamRoster, constants.ActionTeamRoster), zap.Error(err))
+	}
+}
+
+// getLiveSession returns the live session in the path, when it can not be used the failure is already
+// written to the response and false is returned
+func (ctrl *quizSocketController) getLiveSession(c *fiber.Ctx) (models.ActiveQuiz, bool, error) {
+	sessionId, err := uuid.Parse(c.Params(constants.SessionIDParam))
+	if err != nil {
+		return models.ActiveQuiz{}, false, utils.JSONFail(c, http.StatusBadRequest, "invalid UUID")
+	}
+
+	session, err := ctrl.activeQuizModel.GetSession(sessionId.String())
+	if err != nil {
+		if err.Error() == constants.ErrSessionNotFound {
+			return session, false, utils.JSONFail(c, http.StatusBadRequest, constants.ErrSessionNotFound)
+		}
+		ctrl.logger.Error("error getting session for teams", zap.Error(err))
+		return session, false, utils.JSONError(c, http.StatusInternalServerError, constants.UnknownError)
+	}
+
+	if !session.IsActive {
+		return session, false, utils.JSONFail(c, http.StatusBadRequest, constants.ErrSessionWasCompleted)
+	}
+
+	if session.IsAssignment() {
+		return session, false, utils.JSONFail(c, http.StatusBadRequest, constants.ErrAssignmentIsSelfPaced)
+	}
+
+	return session, true, nil
+}
+
+// getHostedSession is getLiveSession for the host of the session only
+func (ctrl *quizSocketController) getHostedSession(c *fiber.Ctx) (models.ActiveQuiz, bool, error) {
+	session, ok, err := ctrl.getLiveSession(c)
+	if !ok {
+		return session, ok, err
+	}
+
+	userId := quizUtilsHelper.GetString(c.Locals(constants.ContextUid))
+	if session.AdminID != userId {
+		return session, false, utils.JSONError(c, http.StatusUnauthorized, constants.ErrUnauthorized)
+	}
+
+	return session, true, nil
+}
+
+// getHostedLobbySession is getHostedSession while the session waits in the lobby, the settings of a
+// running quiz are locked
+func (ctrl *quizSocketController) getHostedLobbySession(c *fiber.Ctx) (models.ActiveQuiz, bool, error) {
+	session, ok, err := ctrl.getHostedSession(c)
+	if !ok {
+		return session, ok, err
+	}
+
+	if session.IsStarted() {
+		return session, false, utils.JSONFail(c, http.StatusBadRequest, constants.ErrTeamsLocked)
+	}
+
+	return session, true, nil
+}
+
+// ConfigureTeams to split the players of a session into teams.
+// swagger:route PUT /v1/quiz/sessions/{session_id}/teams Teams RequestConfigureTeams
+//
+// Enable teams for a session in the lobby, players are auto-balanced or pick their team.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseListTeams
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *quizSocketController) ConfigureTeams(c *fiber.Ctx) error {
+	session, ok, err := ctrl.getHostedLobbySession(c)
+	if !ok {
+		return err
+	}
+
+	var teamsReq structs.ReqSessionTeams
+	err = json.Unmarshal(c.Body(), &teamsReq)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	validate := validator.New()
+	err = validate.Struct(teamsReq)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, utils.ValidatorErrorString(err))
+	}
+
+	if teamsReq.Scoring == constants.TeamScoringBestN && teamsReq.BestN < 1 {
+		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrTeamBestN)
+	}
+
+	err = ctrl.sessionTeamModel.ConfigureTeams(session.ID, models.TeamSettings{
+		Mode:    teamsReq.Mode,
+		Scoring: teamsReq.Scoring,
+		BestN:   teamsReq.BestN,
+	}, teamsReq.Teams)
+	if err != nil {
+		ctrl.logger.Error(constants.ErrConfigureTeams, zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrConfigureTeams)
+	}
+
+	publishTeamRoster(ctrl, session.ID)
+	return ctrl.ListTeams(c)
+}
+
+// DisableTeams to let the players of a session play on their own again.
+// swagger:route DELETE /v1/quiz/sessions/{session_id}/teams Teams RequestDisableTeams
+//
+// Disable teams for a session in the lobby.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseOkWithMessage
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *quizSocketController) DisableTeams(c *fiber.Ctx) error {
+	session, ok, err := ctrl.getHostedLobbySession(c)
+	if !ok {
+		return err
+	}
+
+	if err := ctrl.sessionTeamModel.DisableTeams(session.ID); err != nil {
+		ctrl.logger.Error(constants.ErrConfigureTeams, zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrConfigureTeams)
+	}
+
+	publishTeamRoster(ctrl, session.ID)
+	return utils.JSONSuccess(c, http.StatusOK, "success")
+}
+
+// ListTeams to list the teams of a session with their members.
+// swagger:route GET /v1/quiz/sessions/{session_id}/teams Teams RequestListTeams
+//
+// List the teams of a session with their members.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseListTeams
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *quizSocketController) ListTeams(c *fiber.Ctx) error {
+	session, ok, err := ctrl.getLiveSession(c)
+	if !ok {
+		return err
+	}
+
+	rosters, err := ctrl.sessionTeamModel.ListTeams(session.ID)
+	if err != nil {
+		ctrl.logger.Error(constants.ErrGetTeams, zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrGetTeams)
+	}
+
+	return utils.JSONSuccess(c, http.StatusOK, map[string]any{
+		"mode":    session.TeamMode.String,
+		"scoring": session.TeamScoring,
+		"best_n":  session.TeamBestN.Int32,
+		"teams":   rosters,
+	})
+}
+
+// ChooseTeam to move the player into a team of their choice.
+// swagger:route PUT /v1/quiz/sessions/{session_id}/team Teams RequestChooseTeam
+//
+// Pick a team in the lobby, only when the host lets players choose.
+//
+//		Consumes:
+//		- application/json
+//
+//		Schemes: http, https
+//
+//		Responses:
+//		  200: ResponseOkWithMessage
+//	     400: GenericResFailNotFound
+//		  500: GenericResError
+func (ctrl *quizSocketController) ChooseTeam(c *fiber.Ctx) error {
+	session, ok, err := ctrl.getLiveSession(c)
+	if !ok {
+		return err
+	}
+
+	if session.TeamMode.String != constants.TeamModeChoice {
+		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrTeamChoiceDisabled)
+	}
+
+	if session.IsStarted() {
+		return utils.JSONFail(c, http.StatusBadRequest, constants.ErrTeamsLocked)
+	}
+
+	var teamReq structs.ReqChooseTeam
+	err = json.Unmarshal(c.Body(), &teamReq)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	validate := validator.New()
+	err = validate.Struct(teamReq)
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, utils.ValidatorErrorString(err))
+	}
+
+	userId := quizUtilsHelper.GetString(c.Locals(constants.ContextUid))
+	err = ctrl.sessionTeamModel.JoinTeam(session.ID, userId, uuid.MustParse(teamReq.TeamId))
+	if err != nil {
+		if err.Error() == constants.ErrTeamNotFound {
+			return utils.JSONFail(c, http.StatusBadRequest, constants.ErrTeamNotFound)
+		}
+		ctrl.logger.Error(constants.ErrConfigureTeams, zap.Error(err))
+		return utils.JSONError(c, http.StatusInternalServerError, constants.ErrConfigureTeams)
+	}
+
+	publishTeamRoster(ctrl, session.ID)
+	return utils.JSONSuccess(c, http.StatusOK, "success")
+}
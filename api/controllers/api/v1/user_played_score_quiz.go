@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Improwised/jovvix/api/config"
 	"github.com/Improwised/jovvix/api/constants"
@@ -22,7 +23,9 @@ type UserPlayedQuizeController struct {
 	activeQuizModel       *models.ActiveQuizModel
 	userQuizResponseModel *models.UserQuizResponseModel
 	quizModel             *models.QuizModel
+	userModel             models.UserModel
 	logger                *zap.Logger
+	appConfig             *config.AppConfig
 }
 
 // NewUserController returns a user
@@ -35,12 +38,19 @@ func NewUserPlayedQuizeController(goqu *goqu.Database, logger *zap.Logger, appCo
 
 	quizModel := models.InitQuizModel(goqu)
 
+	userModel, err := models.InitUserModel(goqu, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	return &UserPlayedQuizeController{
 		userPlayedQuizModel:   userPlayedQuizModel,
 		activeQuizModel:       activeQuizModel,
 		userQuizResponseModel: userQuizResponseModel,
 		quizModel:             quizModel,
+		userModel:             userModel,
 		logger:                logger,
+		appConfig:             appConfig,
 	}, nil
 }
 
@@ -143,6 +153,12 @@ func (ctrl *UserPlayedQuizeController) PlayedQuizValidation(c *fiber.Ctx) error
 	}
 	ctrl.logger.Debug("activeQuizModel.activeQuizModel success", zap.Any("session", session))
 
+	if session.IsAssignment() {
+		if err := session.CheckAssignmentWindow(time.Now()); err != nil {
+			return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+		}
+	}
+
 	// The host normally cannot play their own session. Exception: for a PUBLIC quiz,
 	// whoever started it may also play — guest, registered user, or the quiz creator
 	// alike. Hosts of non-public (demo/private) sessions stay host-only.
@@ -159,6 +175,27 @@ func (ctrl *UserPlayedQuizeController) PlayedQuizValidation(c *fiber.Ctx) error
 		}
 	}
 
+	participant, err := ctrl.userPlayedQuizModel.GetParticipant(userId, session.ID)
+	if err != nil && err != sql.ErrNoRows {
+		ctrl.logger.Error(constants.ErrUserQuizSessionValidation, zap.Error(err))
+		return utils.JSONFail(c, http.StatusInternalServerError, constants.ErrUserQuizSessionValidation)
+	}
+	if err == nil && participant.IsBanned {
+		return utils.JSONFail(c, http.StatusForbidden, constants.ErrPlayerBanned)
+	}
+
+	// a protected session is checked before the participation exists, players who got in come back freely
+	if err == sql.ErrNoRows && userId != session.AdminID {
+		reason, err := ctrl.checkAccess(c, session, userId)
+		if err != nil {
+			ctrl.logger.Error(constants.ErrUserQuizSessionValidation, zap.Error(err))
+			return utils.JSONFail(c, http.StatusInternalServerError, constants.ErrUserQuizSessionValidation)
+		}
+		if reason != "" {
+			return utils.JSONFail(c, http.StatusForbidden, reason)
+		}
+	}
+
 	ctrl.logger.Debug("userPlayedQuizModel.CreateUserPlayedQuizIfNotExists called", zap.Any("userId", userId), zap.Any("sessionID", session.ID))
 	userPlayedQuizId, isNonExistingParticipants, err := ctrl.userPlayedQuizModel.CreateUserPlayedQuizIfNotExists(userId, session.ID)
 	if err != nil {
@@ -184,6 +221,7 @@ func (ctrl *UserPlayedQuizeController) PlayedQuizValidation(c *fiber.Ctx) error
 		"user_played_quiz": userPlayedQuizId.String(),
 		"session_id":       session.ID.String(),
 		"quiz_title":       session.Title,
+		"mode":             session.Mode,
 	}
 
 	return utils.JSONSuccess(c, http.StatusOK, response)
@@ -0,0 +1,7 @@
+-- +migrate Down
+
+ALTER TABLE IF EXISTS active_quizzes
+DROP COLUMN IF EXISTS phase,
+DROP COLUMN IF EXISTS phase_deadline,
+DROP COLUMN IF EXISTS is_paused,
+DROP COLUMN IF EXISTS paused_remaining_ms;
@@ -0,0 +1,8 @@
+-- +migrate Up
+
+-- track the host-independent progress of a running session so the host can reconnect
+ALTER TABLE active_quizzes
+ADD COLUMN phase varchar(20) NOT NULL DEFAULT 'lobby',
+ADD COLUMN phase_deadline timestamp,
+ADD COLUMN is_paused boolean NOT NULL DEFAULT false,
+ADD COLUMN paused_remaining_ms integer;
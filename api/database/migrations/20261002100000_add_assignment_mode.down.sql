@@ -0,0 +1,7 @@
+-- +migrate Down
+
+ALTER TABLE IF EXISTS user_quiz_responses
+DROP COLUMN IF EXISTS delivered_at;
+
+ALTER TABLE IF EXISTS active_quizzes
+DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,9 @@
+-- +migrate Up
+
+-- live sessions are driven by the host, assignments are played self-paced between activated_from and activated_to
+ALTER TABLE active_quizzes
+ADD COLUMN mode varchar(20) NOT NULL DEFAULT 'live';
+
+-- when a question of an assignment was served to the player, the per-question timer starts here
+ALTER TABLE user_quiz_responses
+ADD COLUMN delivered_at timestamp;
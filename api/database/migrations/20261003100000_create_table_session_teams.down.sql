@@ -0,0 +1,13 @@
+-- +migrate Down
+
+DROP INDEX IF EXISTS user_played_quizzes_team_id_idx;
+
+ALTER TABLE IF EXISTS user_played_quizzes
+DROP COLUMN IF EXISTS team_id;
+
+ALTER TABLE IF EXISTS active_quizzes
+DROP COLUMN IF EXISTS team_mode,
+DROP COLUMN IF EXISTS team_scoring,
+DROP COLUMN IF EXISTS team_best_n;
+
+DROP TABLE IF EXISTS session_teams;
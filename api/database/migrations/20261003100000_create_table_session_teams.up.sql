@@ -0,0 +1,21 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS "session_teams" (
+  "id" uuid PRIMARY KEY,
+  "active_quiz_id" uuid NOT NULL REFERENCES active_quizzes(id) ON DELETE CASCADE,
+  "name" varchar(50) NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "updated_at" timestamp NOT NULL DEFAULT (now()),
+  UNIQUE ("active_quiz_id", "name")
+);
+
+-- team_mode is null while teams are off, 'auto' balances players and 'choice' lets them pick in the lobby
+ALTER TABLE active_quizzes
+ADD COLUMN team_mode varchar(20),
+ADD COLUMN team_scoring varchar(20) NOT NULL DEFAULT 'sum',
+ADD COLUMN team_best_n integer;
+
+ALTER TABLE user_played_quizzes
+ADD COLUMN team_id uuid REFERENCES session_teams(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS user_played_quizzes_team_id_idx ON user_played_quizzes (team_id);
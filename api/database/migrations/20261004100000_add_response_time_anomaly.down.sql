@@ -0,0 +1,5 @@
+-- +migrate Down
+
+ALTER TABLE IF EXISTS user_quiz_responses
+DROP COLUMN IF EXISTS is_time_anomaly,
+DROP COLUMN IF EXISTS client_response_time;
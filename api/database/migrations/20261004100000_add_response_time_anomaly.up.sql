@@ -0,0 +1,6 @@
+-- +migrate Up
+
+-- response_time is measured by the server, the time reported by the player is kept to spot tampered clients
+ALTER TABLE user_quiz_responses
+ADD COLUMN client_response_time integer,
+ADD COLUMN is_time_anomaly boolean NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+-- +migrate Down
+
+ALTER TABLE IF EXISTS active_quizzes
+DROP COLUMN IF EXISTS shuffle_options,
+DROP COLUMN IF EXISTS shuffle_questions;
@@ -0,0 +1,6 @@
+-- +migrate Up
+
+-- exam-style sessions: the questions and the options of every question are shown in a different order to each player
+ALTER TABLE active_quizzes
+ADD COLUMN shuffle_questions boolean NOT NULL DEFAULT false,
+ADD COLUMN shuffle_options boolean NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+-- +migrate Down
+
+ALTER TABLE IF EXISTS user_played_quizzes
+DROP COLUMN IF EXISTS display_name,
+DROP COLUMN IF EXISTS is_banned;
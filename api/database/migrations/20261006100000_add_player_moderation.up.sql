@@ -0,0 +1,6 @@
+-- +migrate Up
+
+-- hosts moderate their lobby: a banned player can not join the session again and a renamed player is shown under display_name
+ALTER TABLE user_played_quizzes
+ADD COLUMN is_banned boolean NOT NULL DEFAULT false,
+ADD COLUMN display_name varchar(50);
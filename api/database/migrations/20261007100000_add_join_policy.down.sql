@@ -0,0 +1,10 @@
+-- +migrate Down
+
+ALTER TABLE IF EXISTS user_played_quizzes
+DROP COLUMN IF EXISTS admitted_at;
+
+ALTER TABLE IF EXISTS active_quizzes
+DROP COLUMN IF EXISTS max_players,
+DROP COLUMN IF EXISTS allow_late_join,
+DROP COLUMN IF EXISTS late_join_credit,
+DROP COLUMN IF EXISTS is_lobby_locked;
@@ -0,0 +1,15 @@
+-- +migrate Up
+
+-- hosts decide who may still join: max_players caps the session (null is no cap), allow_late_join lets players in
+-- after the start with 'zero' or 'average' credit for the questions they missed, is_lobby_locked keeps newcomers out
+ALTER TABLE active_quizzes
+ADD COLUMN max_players integer,
+ADD COLUMN allow_late_join boolean NOT NULL DEFAULT true,
+ADD COLUMN late_join_credit varchar(20) NOT NULL DEFAULT 'zero',
+ADD COLUMN is_lobby_locked boolean NOT NULL DEFAULT false;
+
+-- a participation counts once its socket was let in, the players who joined before are let in already
+ALTER TABLE user_played_quizzes
+ADD COLUMN admitted_at timestamp;
+
+UPDATE user_played_quizzes SET admitted_at = created_at;
@@ -0,0 +1,7 @@
+-- +migrate Down
+
+DROP TABLE IF EXISTS session_allowlist;
+
+ALTER TABLE IF EXISTS active_quizzes
+DROP COLUMN IF EXISTS access_mode,
+DROP COLUMN IF EXISTS passcode_hash;
@@ -0,0 +1,16 @@
+-- +migrate Up
+
+-- access_mode is 'open' for anybody with the code, 'passcode' asks for the passcode hashed in passcode_hash
+-- and 'allowlist' only takes registered users whose email or email domain is in session_allowlist
+ALTER TABLE active_quizzes
+ADD COLUMN access_mode varchar(20) NOT NULL DEFAULT 'open',
+ADD COLUMN passcode_hash varchar(100);
+
+-- an entry holding an @ is an email, otherwise it is a domain every address of which is let in
+CREATE TABLE IF NOT EXISTS "session_allowlist" (
+  "id" uuid PRIMARY KEY,
+  "active_quiz_id" uuid NOT NULL REFERENCES active_quizzes(id) ON DELETE CASCADE,
+  "entry" varchar(320) NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  UNIQUE ("active_quiz_id", "entry")
+);
@@ -0,0 +1,4 @@
+-- +migrate Down
+
+ALTER TABLE IF EXISTS questions
+DROP COLUMN IF EXISTS grading;
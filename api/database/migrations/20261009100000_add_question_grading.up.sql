@@ -0,0 +1,5 @@
+-- +migrate Up
+
+-- how a multiple answer question is graded, the other types ignore it
+ALTER TABLE questions
+ADD COLUMN IF NOT EXISTS grading VARCHAR(30) NOT NULL DEFAULT 'all_or_nothing';
@@ -0,0 +1,7 @@
+-- +migrate Down
+
+ALTER TABLE IF EXISTS user_quiz_responses
+DROP COLUMN IF EXISTS answer_text;
+
+ALTER TABLE IF EXISTS questions
+DROP COLUMN IF EXISTS answer_rules;
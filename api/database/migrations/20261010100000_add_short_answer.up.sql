@@ -0,0 +1,9 @@
+-- +migrate Up
+
+-- what the answers of the types without options are checked against
+ALTER TABLE questions
+ADD COLUMN IF NOT EXISTS answer_rules jsonb;
+
+-- the text typed by the player, for the types without options
+ALTER TABLE user_quiz_responses
+ADD COLUMN IF NOT EXISTS answer_text TEXT;
@@ -0,0 +1,4 @@
+-- +migrate Down
+
+ALTER TABLE IF EXISTS user_quiz_responses
+DROP COLUMN IF EXISTS answer_value;
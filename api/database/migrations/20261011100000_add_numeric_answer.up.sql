@@ -0,0 +1,5 @@
+-- +migrate Up
+
+-- the value sent by the player, for numeric questions
+ALTER TABLE user_quiz_responses
+ADD COLUMN IF NOT EXISTS answer_value DOUBLE PRECISION;
@@ -0,0 +1,5 @@
+-- +migrate Up
+
+-- the definition key the player paired each option with, for matching questions
+ALTER TABLE user_quiz_responses
+ADD COLUMN IF NOT EXISTS answer_pairs jsonb;
@@ -2,19 +2,37 @@ package quizUtilsHelper
 
 import (
 	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/pkg/textmatch"
 )
 
 /*
 1 - Single Answer
 2 - Survey
+3 - Multiple Answer
+4 - Short Answer
+5 - Numeric
+6 - Ordering
+7 - Matching
+8 - Word cloud
 */
 
 // add other types to first constants and then here
 var questionTypeIDs = map[string]int{
-	constants.SingleAnswerString: constants.SingleAnswer,
-	constants.SurveyString:       constants.Survey,
+	constants.SingleAnswerString:   constants.SingleAnswer,
+	constants.SurveyString:         constants.Survey,
+	constants.MultipleAnswerString: constants.MultipleAnswer,
+	constants.ShortAnswerString:    constants.ShortAnswer,
+	constants.NumericString:        constants.Numeric,
+	constants.OrderingString:       constants.Ordering,
+	constants.MatchingString:       constants.Matching,
+	constants.WordCloudString:      constants.WordCloud,
 }
 
 // function to check if passed type exist as a type or not
@@ -34,3 +52,139 @@ func GetQuestionType(typeNumber int) (string, error) {
 	}
 	return "", errors.New(constants.ErrQuestionId)
 }
+
+// CheckAnswerCount checks the correct answers suit the type of the question, answers are option keys
+func CheckAnswerCount(questionType int, optionCount int, answers []int) error {
+	switch questionType {
+	case constants.SingleAnswer:
+		if len(answers) != 1 {
+			return errors.New(constants.ErrSingleAnswerLength)
+		}
+	case constants.Survey:
+		if len(answers) < 1 {
+			return errors.New(constants.ErrSurveyAnswerLength)
+		}
+	case constants.MultipleAnswer:
+		distinct := map[int]struct{}{}
+		for _, answer := range answers {
+			distinct[answer] = struct{}{}
+		}
+		// with every option correct there is nothing left to choose
+		if len(distinct) < 1 || len(distinct) >= optionCount {
+			return errors.New(constants.ErrMultipleAnswerLength)
+		}
+	case constants.Ordering:
+		distinct := map[int]struct{}{}
+		for _, answer := range answers {
+			distinct[answer] = struct{}{}
+		}
+		if optionCount < 2 || len(answers) != optionCount || len(distinct) != optionCount {
+			return errors.New(constants.ErrOrderingAnswer)
+		}
+	}
+	return nil
+}
+
+// CheckGrading returns the grading of a multiple answer question, all or nothing when none is given
+func CheckGrading(grading string) (string, error) {
+	switch grading {
+	case "":
+		return constants.GradingAllOrNothing, nil
+	case constants.GradingAllOrNothing, constants.GradingProportional, constants.GradingPenalty:
+		return grading, nil
+	}
+	return "", errors.New(constants.ErrGrading)
+}
+
+// CheckShortAnswer returns the rules of a short answer question with the accepted answers trimmed and
+// the blank or repeated ones left out
+func CheckShortAnswer(rules *structs.ShortAnswerRules) (structs.ShortAnswerRules, error) {
+	if rules == nil {
+		return structs.ShortAnswerRules{}, errors.New(constants.ErrShortAnswerRules)
+	}
+
+	checked := structs.ShortAnswerRules{Accepted: []string{}, Rules: rules.Rules}
+	seen := map[string]bool{}
+	for _, answer := range rules.Accepted {
+		answer = strings.TrimSpace(answer)
+		if answer == "" || seen[answer] {
+			continue
+		}
+		seen[answer] = true
+		checked.Accepted = append(checked.Accepted, answer)
+	}
+
+	if len(checked.Accepted) == 0 || checked.MaxDistance < 0 || checked.MaxDistance > constants.MaxAnswerDistance {
+		return checked, errors.New(constants.ErrShortAnswerRules)
+	}
+
+	if _, err := textmatch.Compile(checked.Rules); err != nil {
+		return checked, errors.New(constants.ErrAnswerPattern)
+	}
+
+	return checked, nil
+}
+
+// CheckNumeric returns the rules of a numeric question with an absolute tolerance when no type is given
+func CheckNumeric(rules *structs.NumericRules) (structs.NumericRules, error) {
+	if rules == nil {
+		return structs.NumericRules{}, errors.New(constants.ErrNumericRules)
+	}
+
+	checked := *rules
+	checked.Unit = strings.TrimSpace(checked.Unit)
+	switch checked.ToleranceType {
+	case "":
+		checked.ToleranceType = constants.ToleranceAbsolute
+	case constants.ToleranceAbsolute, constants.TolerancePercent:
+	default:
+		return checked, errors.New(constants.ErrToleranceType)
+	}
+
+	for _, value := range []float64{checked.Target, checked.Tolerance, checked.PartialRange} {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return checked, errors.New(constants.ErrNumericRules)
+		}
+	}
+	if checked.Tolerance < 0 || (checked.PartialRange != 0 && checked.PartialRange <= checked.Tolerance) {
+		return checked, errors.New(constants.ErrNumericRules)
+	}
+
+	if utf8.RuneCountInString(checked.Unit) > constants.MaxUnitLength {
+		return checked, errors.New(constants.ErrUnitLength)
+	}
+
+	return checked, nil
+}
+
+// CheckMatching returns the rules of a matching question with the definitions trimmed, every option has to
+// be paired with a definition of its own and every definition with an option
+func CheckMatching(options map[string]string, rules *structs.MatchingRules) (structs.MatchingRules, error) {
+	if rules == nil || len(options) < 2 || len(rules.Definitions) != len(options) || len(rules.Pairs) != len(options) {
+		return structs.MatchingRules{}, errors.New(constants.ErrMatchingRules)
+	}
+
+	checked := structs.MatchingRules{Definitions: map[string]string{}, Pairs: map[string]int{}}
+	for key, definition := range rules.Definitions {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			return checked, errors.New(constants.ErrMatchingRules)
+		}
+		checked.Definitions[key] = definition
+	}
+
+	used := map[int]bool{}
+	for key := range options {
+		definitionKey, ok := rules.Pairs[key]
+		if !ok || used[definitionKey] {
+			return checked, errors.New(constants.ErrMatchingRules)
+		}
+		if _, ok := checked.Definitions[strconv.Itoa(definitionKey)]; !ok {
+			return checked, errors.New(constants.ErrMatchingRules)
+		}
+		used[definitionKey] = true
+		checked.Pairs[key] = definitionKey
+	}
+
+	return checked, nil
+}
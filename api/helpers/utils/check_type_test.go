@@ -1,9 +1,13 @@
 package quizUtilsHelper
 
 import (
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/pkg/textmatch"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -62,3 +66,112 @@ func TestGetTypeNumber(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "", questionType, "Expected result to be %s, but got %s", "", questionType)
 }
+
+func TestCheckAnswerCount(t *testing.T) {
+	t.Run("single answer", func(t *testing.T) {
+		assert.NoError(t, CheckAnswerCount(constants.SingleAnswer, 4, []int{2}))
+		assert.EqualError(t, CheckAnswerCount(constants.SingleAnswer, 4, []int{1, 2}), constants.ErrSingleAnswerLength)
+	})
+
+	t.Run("multiple answer", func(t *testing.T) {
+		assert.NoError(t, CheckAnswerCount(constants.MultipleAnswer, 4, []int{1}))
+		assert.NoError(t, CheckAnswerCount(constants.MultipleAnswer, 4, []int{1, 3, 4}))
+		assert.NoError(t, CheckAnswerCount(constants.MultipleAnswer, 3, []int{1, 2, 2}))
+		assert.EqualError(t, CheckAnswerCount(constants.MultipleAnswer, 4, []int{}), constants.ErrMultipleAnswerLength)
+		assert.EqualError(t, CheckAnswerCount(constants.MultipleAnswer, 3, []int{1, 2, 3}), constants.ErrMultipleAnswerLength)
+	})
+
+	t.Run("ordering", func(t *testing.T) {
+		assert.NoError(t, CheckAnswerCount(constants.Ordering, 3, []int{3, 1, 2}))
+		assert.EqualError(t, CheckAnswerCount(constants.Ordering, 3, []int{3, 1}), constants.ErrOrderingAnswer)
+		assert.EqualError(t, CheckAnswerCount(constants.Ordering, 3, []int{3, 1, 1}), constants.ErrOrderingAnswer)
+		assert.EqualError(t, CheckAnswerCount(constants.Ordering, 1, []int{1}), constants.ErrOrderingAnswer)
+	})
+
+	t.Run("multiple answer type by name", func(t *testing.T) {
+		questionID, err := CheckQuestionType(constants.MultipleAnswerString)
+		assert.NoError(t, err)
+		assert.Equal(t, constants.MultipleAnswer, questionID)
+	})
+}
+
+func TestCheckGrading(t *testing.T) {
+	grading, err := CheckGrading("")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.GradingAllOrNothing, grading)
+
+	grading, err = CheckGrading(constants.GradingPenalty)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.GradingPenalty, grading)
+
+	_, err = CheckGrading("half")
+	assert.EqualError(t, err, constants.ErrGrading)
+}
+
+func TestCheckShortAnswer(t *testing.T) {
+	rules, err := CheckShortAnswer(&structs.ShortAnswerRules{Accepted: []string{" Everest ", "", "Everest", "Sagarmatha"}, Rules: textmatch.Rules{MaxDistance: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Everest", "Sagarmatha"}, rules.Accepted)
+	assert.Equal(t, 1, rules.MaxDistance)
+
+	_, err = CheckShortAnswer(nil)
+	assert.EqualError(t, err, constants.ErrShortAnswerRules)
+
+	_, err = CheckShortAnswer(&structs.ShortAnswerRules{Accepted: []string{" "}})
+	assert.EqualError(t, err, constants.ErrShortAnswerRules)
+
+	_, err = CheckShortAnswer(&structs.ShortAnswerRules{Accepted: []string{"a"}, Rules: textmatch.Rules{MaxDistance: 6}})
+	assert.EqualError(t, err, constants.ErrShortAnswerRules)
+
+	_, err = CheckShortAnswer(&structs.ShortAnswerRules{Accepted: []string{"a"}, Rules: textmatch.Rules{Pattern: "[a"}})
+	assert.EqualError(t, err, constants.ErrAnswerPattern)
+}
+
+func TestCheckNumeric(t *testing.T) {
+	rules, err := CheckNumeric(&structs.NumericRules{Target: 9.81, Tolerance: 0.1, Unit: " m/s² "})
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ToleranceAbsolute, rules.ToleranceType)
+	assert.Equal(t, "m/s²", rules.Unit)
+
+	_, err = CheckNumeric(&structs.NumericRules{Target: 100, Tolerance: 5, ToleranceType: constants.TolerancePercent, PartialRange: 20})
+	assert.NoError(t, err)
+
+	_, err = CheckNumeric(nil)
+	assert.EqualError(t, err, constants.ErrNumericRules)
+
+	_, err = CheckNumeric(&structs.NumericRules{Target: 1, ToleranceType: "relative"})
+	assert.EqualError(t, err, constants.ErrToleranceType)
+
+	_, err = CheckNumeric(&structs.NumericRules{Target: 1, Tolerance: -1})
+	assert.EqualError(t, err, constants.ErrNumericRules)
+
+	_, err = CheckNumeric(&structs.NumericRules{Target: 1, Tolerance: 2, PartialRange: 2})
+	assert.EqualError(t, err, constants.ErrNumericRules)
+
+	_, err = CheckNumeric(&structs.NumericRules{Target: math.NaN()})
+	assert.EqualError(t, err, constants.ErrNumericRules)
+
+	_, err = CheckNumeric(&structs.NumericRules{Target: 1, Unit: strings.Repeat("m", constants.MaxUnitLength+1)})
+	assert.EqualError(t, err, constants.ErrUnitLength)
+}
+
+func TestCheckMatching(t *testing.T) {
+	options := map[string]string{"1": "TCP", "2": "UDP"}
+
+	rules, err := CheckMatching(options, &structs.MatchingRules{Definitions: map[string]string{"1": " reliable ", "2": "datagram"}, Pairs: map[string]int{"1": 1, "2": 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, "reliable", rules.Definitions["1"])
+	assert.Equal(t, map[string]int{"1": 1, "2": 2}, rules.Pairs)
+
+	_, err = CheckMatching(options, nil)
+	assert.EqualError(t, err, constants.ErrMatchingRules)
+
+	_, err = CheckMatching(options, &structs.MatchingRules{Definitions: map[string]string{"1": "reliable", "2": "datagram"}, Pairs: map[string]int{"1": 1, "2": 1}})
+	assert.EqualError(t, err, constants.ErrMatchingRules)
+
+	_, err = CheckMatching(options, &structs.MatchingRules{Definitions: map[string]string{"1": "reliable", "2": " "}, Pairs: map[string]int{"1": 1, "2": 2}})
+	assert.EqualError(t, err, constants.ErrMatchingRules)
+
+	_, err = CheckMatching(options, &structs.MatchingRules{Definitions: map[string]string{"1": "reliable", "3": "datagram"}, Pairs: map[string]int{"1": 1, "2": 2}})
+	assert.EqualError(t, err, constants.ErrMatchingRules)
+}
@@ -0,0 +1,60 @@
+package quizUtilsHelper
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+)
+
+// ValueDistribution summarizes the values sent to a numeric question and spreads them over at most
+// constants.MaxValueBuckets buckets of the same width between the lowest and the highest value
+func ValueDistribution(values []float64) structs.ValueDistribution {
+	distribution := structs.ValueDistribution{Count: len(values), Buckets: []structs.ValueBucket{}}
+	if len(values) == 0 {
+		return distribution
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, value := range sorted {
+		sum += value
+	}
+
+	count := len(sorted)
+	distribution.Min = sorted[0]
+	distribution.Max = sorted[count-1]
+	distribution.Mean = sum / float64(count)
+	distribution.Median = sorted[count/2]
+	if count%2 == 0 {
+		distribution.Median = (sorted[count/2-1] + sorted[count/2]) / 2
+	}
+
+	// all the same value, a single bar
+	if distribution.Min == distribution.Max {
+		distribution.Buckets = append(distribution.Buckets, structs.ValueBucket{From: distribution.Min, To: distribution.Max, Count: count})
+		return distribution
+	}
+
+	bucketCount := int(math.Ceil(math.Sqrt(float64(count))))
+	bucketCount = max(min(bucketCount, constants.MaxValueBuckets), 1)
+	width := (distribution.Max - distribution.Min) / float64(bucketCount)
+
+	for index := 0; index < bucketCount; index++ {
+		distribution.Buckets = append(distribution.Buckets, structs.ValueBucket{
+			From: distribution.Min + width*float64(index),
+			To:   distribution.Min + width*float64(index+1),
+		})
+	}
+	distribution.Buckets[bucketCount-1].To = distribution.Max
+
+	for _, value := range sorted {
+		index := min(int((value-distribution.Min)/width), bucketCount-1)
+		distribution.Buckets[index].Count++
+	}
+
+	return distribution
+}
@@ -0,0 +1,51 @@
+package quizUtilsHelper
+
+import (
+	"testing"
+
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueDistribution(t *testing.T) {
+	t.Run("no values", func(t *testing.T) {
+		distribution := ValueDistribution(nil)
+		assert.Equal(t, 0, distribution.Count)
+		assert.Empty(t, distribution.Buckets)
+	})
+
+	t.Run("median of an even count is the middle pair average", func(t *testing.T) {
+		distribution := ValueDistribution([]float64{10, 0, 4, 2})
+		assert.Equal(t, 4, distribution.Count)
+		assert.Equal(t, 0.0, distribution.Min)
+		assert.Equal(t, 10.0, distribution.Max)
+		assert.Equal(t, 4.0, distribution.Mean)
+		assert.Equal(t, 3.0, distribution.Median)
+		assert.Equal(t, []structs.ValueBucket{
+			{From: 0, To: 5, Count: 3},
+			{From: 5, To: 10, Count: 1},
+		}, distribution.Buckets)
+	})
+
+	t.Run("the same value makes a single bucket", func(t *testing.T) {
+		distribution := ValueDistribution([]float64{7, 7, 7})
+		assert.Equal(t, 7.0, distribution.Median)
+		assert.Equal(t, []structs.ValueBucket{{From: 7, To: 7, Count: 3}}, distribution.Buckets)
+	})
+
+	t.Run("bucket count is capped", func(t *testing.T) {
+		values := make([]float64, 400)
+		for index := range values {
+			values[index] = float64(index)
+		}
+		distribution := ValueDistribution(values)
+		assert.Len(t, distribution.Buckets, 10)
+		assert.Equal(t, 199.5, distribution.Median)
+
+		total := 0
+		for _, bucket := range distribution.Buckets {
+			total += bucket.Count
+		}
+		assert.Equal(t, 400, total)
+	})
+}
@@ -0,0 +1,62 @@
+package quizUtilsHelper
+
+import (
+	mathRand "math/rand"
+	"strconv"
+
+	"github.com/Improwised/jovvix/api/pkg/structs"
+)
+
+// ScrambleOrder gives the options of an ordering question new keys, so that listing them by key is not the
+// right order. It returns the options under their new keys and the right order in new keys.
+func ScrambleOrder(options map[string]string, order []int) (map[string]string, []int) {
+	keys := scrambledKeys(len(order))
+
+	scrambled := make(map[string]string, len(order))
+	for index, key := range order {
+		scrambled[strconv.Itoa(keys[index])] = options[strconv.Itoa(key)]
+	}
+
+	return scrambled, keys
+}
+
+// ScrambleDefinitions gives the definitions of a matching question new keys, so that listing the options
+// and the definitions by key does not put each option next to its definition
+func ScrambleDefinitions(rules structs.MatchingRules) structs.MatchingRules {
+	optionKeys := make([]string, 0, len(rules.Pairs))
+	for key := range rules.Pairs {
+		optionKeys = append(optionKeys, key)
+	}
+	sortOptionKeys(optionKeys)
+
+	keys := scrambledKeys(len(optionKeys))
+
+	scrambled := structs.MatchingRules{Definitions: make(map[string]string, len(optionKeys)), Pairs: make(map[string]int, len(optionKeys))}
+	for index, optionKey := range optionKeys {
+		scrambled.Definitions[strconv.Itoa(keys[index])] = rules.Definitions[strconv.Itoa(rules.Pairs[optionKey])]
+		scrambled.Pairs[optionKey] = keys[index]
+	}
+
+	return scrambled
+}
+
+// scrambledKeys returns the keys from 1 to count in a random order other than the ascending one
+func scrambledKeys(count int) []int {
+	keys := mathRand.Perm(count)
+	for index := range keys {
+		keys[index]++
+	}
+
+	isAscending := true
+	for index, key := range keys {
+		if key != index+1 {
+			isAscending = false
+			break
+		}
+	}
+	if isAscending && count > 1 {
+		keys[0], keys[1] = keys[1], keys[0]
+	}
+
+	return keys
+}
@@ -0,0 +1,35 @@
+package quizUtilsHelper
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrambleOrder(t *testing.T) {
+	options := map[string]string{"1": "plan", "2": "build", "3": "test", "4": "ship"}
+
+	for run := 0; run < 20; run++ {
+		scrambled, order := ScrambleOrder(options, []int{1, 2, 3, 4})
+		assert.NotEqual(t, []int{1, 2, 3, 4}, order)
+
+		inOrder := []string{}
+		for _, key := range order {
+			inOrder = append(inOrder, scrambled[strconv.Itoa(key)])
+		}
+		assert.Equal(t, []string{"plan", "build", "test", "ship"}, inOrder)
+	}
+}
+
+func TestScrambleDefinitions(t *testing.T) {
+	rules := structs.MatchingRules{
+		Definitions: map[string]string{"1": "reliable", "2": "datagram"},
+		Pairs:       map[string]int{"1": 1, "2": 2},
+	}
+
+	scrambled := ScrambleDefinitions(rules)
+	assert.Equal(t, map[string]int{"1": 2, "2": 1}, scrambled.Pairs)
+	assert.Equal(t, map[string]string{"1": "datagram", "2": "reliable"}, scrambled.Definitions)
+}
@@ -0,0 +1,55 @@
+package quizUtilsHelper
+
+import (
+	"strconv"
+
+	"github.com/Improwised/jovvix/api/pkg/structs"
+)
+
+// OptionSelections counts how many of the players who answered picked each option, selections holds
+// the option keys picked by every player and is empty for the ones who did not answer
+func OptionSelections(options map[string]string, correctAnswers []int, selections [][]int) []structs.OptionSelection {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sortOptionKeys(keys)
+
+	correct := map[int]bool{}
+	for _, answer := range correctAnswers {
+		correct[answer] = true
+	}
+
+	counts := map[int]int{}
+	answered := 0
+	for _, picked := range selections {
+		if len(picked) == 0 {
+			continue
+		}
+		answered++
+
+		// a key sent twice is still one pick
+		seen := map[int]bool{}
+		for _, key := range picked {
+			if !seen[key] {
+				seen[key] = true
+				counts[key]++
+			}
+		}
+	}
+
+	result := make([]structs.OptionSelection, 0, len(keys))
+	for _, key := range keys {
+		selection := structs.OptionSelection{Key: key}
+		if number, err := strconv.Atoi(key); err == nil {
+			selection.Count = counts[number]
+			selection.IsCorrect = correct[number]
+		}
+		if answered > 0 {
+			selection.Rate = float64(selection.Count) / float64(answered)
+		}
+		result = append(result, selection)
+	}
+
+	return result
+}
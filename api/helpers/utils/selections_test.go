@@ -0,0 +1,31 @@
+package quizUtilsHelper
+
+import (
+	"testing"
+
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionSelections(t *testing.T) {
+	options := map[string]string{"1": "a", "2": "b", "10": "c"}
+
+	t.Run("rates are out of the players who answered", func(t *testing.T) {
+		selections := [][]int{{1, 2}, {2}, {2, 2, 10}, nil}
+
+		assert.Equal(t, []structs.OptionSelection{
+			{Key: "1", Count: 1, Rate: 1.0 / 3, IsCorrect: true},
+			{Key: "2", Count: 3, Rate: 1, IsCorrect: true},
+			{Key: "10", Count: 1, Rate: 1.0 / 3},
+		}, OptionSelections(options, []int{1, 2}, selections))
+	})
+
+	t.Run("nobody answered", func(t *testing.T) {
+		result := OptionSelections(options, []int{1}, [][]int{nil, {}})
+		assert.Len(t, result, 3)
+		for _, selection := range result {
+			assert.Equal(t, 0, selection.Count)
+			assert.Equal(t, 0.0, selection.Rate)
+		}
+	})
+}
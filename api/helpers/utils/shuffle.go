@@ -0,0 +1,120 @@
+package quizUtilsHelper
+
+import (
+	"hash/fnv"
+	mathRand "math/rand"
+	"sort"
+	"strconv"
+)
+
+// OptionKeyMapping shuffles the option keys of a question for one player and returns, for every key the
+// player sees, the canonical key it stands for. The same seed always gives the same mapping, so the order
+// does not have to be stored to score the answer later.
+func OptionKeyMapping(seed string, keys []string) map[string]string {
+	displayed := make([]string, len(keys))
+	copy(displayed, keys)
+	sortOptionKeys(displayed)
+
+	canonical := make([]string, len(displayed))
+	copy(canonical, displayed)
+
+	hash := fnv.New64a()
+	hash.Write([]byte(seed))
+	random := mathRand.New(mathRand.NewSource(int64(hash.Sum64())))
+	random.Shuffle(len(canonical), func(i, j int) {
+		canonical[i], canonical[j] = canonical[j], canonical[i]
+	})
+
+	mapping := make(map[string]string, len(displayed))
+	for i, key := range displayed {
+		mapping[key] = canonical[i]
+	}
+
+	return mapping
+}
+
+// ShuffleOptions returns the options in the order a player sees them
+func ShuffleOptions(options map[string]string, mapping map[string]string) map[string]string {
+	shuffled := make(map[string]string, len(options))
+	for key, value := range options {
+		if canonicalKey, ok := mapping[key]; ok {
+			value = options[canonicalKey]
+		}
+		shuffled[key] = value
+	}
+
+	return shuffled
+}
+
+// ToCanonicalKeys translates the answer keys a player picked to the keys of the question
+func ToCanonicalKeys(keys []int, mapping map[string]string) []int {
+	return translateKeys(keys, mapping)
+}
+
+// ToDisplayedKeys translates keys of the question to the keys a player sees them under
+func ToDisplayedKeys(keys []int, mapping map[string]string) []int {
+	inverse := make(map[string]string, len(mapping))
+	for displayed, canonical := range mapping {
+		inverse[canonical] = displayed
+	}
+
+	return translateKeys(keys, inverse)
+}
+
+// ToCanonicalPairs translates the option keys of the pairs a player made to the keys of the question,
+// the definitions are not shuffled and keep their keys
+func ToCanonicalPairs(pairs map[string]int, mapping map[string]string) map[string]int {
+	return translatePairs(pairs, mapping)
+}
+
+// ToDisplayedPairs translates the option keys of pairs of the question to the keys a player sees them under
+func ToDisplayedPairs(pairs map[string]int, mapping map[string]string) map[string]int {
+	inverse := make(map[string]string, len(mapping))
+	for displayed, canonical := range mapping {
+		inverse[canonical] = displayed
+	}
+
+	return translatePairs(pairs, inverse)
+}
+
+func translatePairs(pairs map[string]int, mapping map[string]string) map[string]int {
+	if pairs == nil {
+		return nil
+	}
+
+	translated := make(map[string]int, len(pairs))
+	for key, definitionKey := range pairs {
+		if mapped, ok := mapping[key]; ok {
+			key = mapped
+		}
+		translated[key] = definitionKey
+	}
+
+	return translated
+}
+
+func translateKeys(keys []int, mapping map[string]string) []int {
+	translated := make([]int, 0, len(keys))
+	for _, key := range keys {
+		if mapped, ok := mapping[strconv.Itoa(key)]; ok {
+			if value, err := strconv.Atoi(mapped); err == nil {
+				key = value
+			}
+		}
+		translated = append(translated, key)
+	}
+
+	return translated
+}
+
+// sortOptionKeys sorts numeric keys by value, so "10" comes after "9"
+func sortOptionKeys(keys []string) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		left, leftErr := strconv.Atoi(keys[i])
+		right, rightErr := strconv.Atoi(keys[j])
+		if leftErr == nil && rightErr == nil {
+			return left < right
+		}
+		return keys[i] < keys[j]
+	})
+}
@@ -0,0 +1,62 @@
+package quizUtilsHelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionKeyMapping(t *testing.T) {
+	keys := []string{"1", "2", "3", "4"}
+
+	t.Run("same seed gives the same mapping", func(t *testing.T) {
+		assert.Equal(t, OptionKeyMapping("player-question", keys), OptionKeyMapping("player-question", keys))
+	})
+
+	t.Run("every key is used once", func(t *testing.T) {
+		mapping := OptionKeyMapping("player-question", keys)
+		assert.Len(t, mapping, len(keys))
+
+		seen := map[string]bool{}
+		for _, canonical := range mapping {
+			assert.Contains(t, keys, canonical)
+			assert.False(t, seen[canonical])
+			seen[canonical] = true
+		}
+	})
+
+	t.Run("seeds give different orders", func(t *testing.T) {
+		distinct := map[string]bool{}
+		for _, seed := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+			mapping := OptionKeyMapping(seed, keys)
+			distinct[mapping["1"]+mapping["2"]+mapping["3"]+mapping["4"]] = true
+		}
+		assert.Greater(t, len(distinct), 1)
+	})
+}
+
+func TestShuffleOptions(t *testing.T) {
+	options := map[string]string{"1": "red", "2": "green", "3": "blue"}
+	mapping := map[string]string{"1": "3", "2": "1", "3": "2"}
+
+	t.Run("options follow the mapping", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"1": "blue", "2": "red", "3": "green"}, ShuffleOptions(options, mapping))
+	})
+
+	t.Run("answer keys round trip", func(t *testing.T) {
+		canonical := ToCanonicalKeys([]int{1, 3}, mapping)
+		assert.Equal(t, []int{3, 2}, canonical)
+		assert.Equal(t, []int{1, 3}, ToDisplayedKeys(canonical, mapping))
+	})
+
+	t.Run("pairs round trip on their option keys", func(t *testing.T) {
+		canonical := ToCanonicalPairs(map[string]int{"1": 2, "2": 3}, mapping)
+		assert.Equal(t, map[string]int{"3": 2, "1": 3}, canonical)
+		assert.Equal(t, map[string]int{"1": 2, "2": 3}, ToDisplayedPairs(canonical, mapping))
+		assert.Nil(t, ToCanonicalPairs(nil, mapping))
+	})
+
+	t.Run("unknown keys are kept", func(t *testing.T) {
+		assert.Equal(t, []int{7}, ToCanonicalKeys([]int{7}, mapping))
+	})
+}
@@ -1,7 +1,9 @@
 package quizUtilsHelper
 
 import (
+	"net/mail"
 	"strconv"
+	"strings"
 
 	"github.com/Improwised/jovvix/api/constants"
 )
@@ -23,3 +25,52 @@ func IsValidCode(code string) bool {
 
 	return true
 }
+
+// AllowlistEntry normalizes an email or an email domain of a session allowlist, it reports false when
+// the entry is neither
+func AllowlistEntry(entry string) (string, bool) {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	entry = strings.TrimPrefix(entry, "@")
+
+	if strings.Contains(entry, "@") {
+		address, err := mail.ParseAddress(entry)
+		if err != nil || address.Address != entry {
+			return "", false
+		}
+		return entry, true
+	}
+
+	// a domain has at least two labels, made of letters, digits and inner hyphens
+	labels := strings.Split(entry, ".")
+	if len(labels) < 2 || len(entry) > 253 {
+		return "", false
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "", false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return "", false
+			}
+		}
+	}
+	return entry, true
+}
+
+// IsAllowlisted reports whether email is one of the entries, or belongs to one of their domains
+func IsAllowlisted(email string, entries []string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return false
+	}
+	domain := email[at+1:]
+
+	for _, entry := range entries {
+		if entry == email || entry == domain {
+			return true
+		}
+	}
+	return false
+}
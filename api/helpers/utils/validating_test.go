@@ -35,3 +35,43 @@ func TestIsValidCode(t *testing.T) {
 		assert.False(t, IsValidCode(aboveMax))
 	})
 }
+
+func TestAllowlistEntry(t *testing.T) {
+
+	t.Run("emails are lowered", func(t *testing.T) {
+		entry, ok := AllowlistEntry(" Jane.Doe@Example.com ")
+		assert.True(t, ok)
+		assert.Equal(t, "jane.doe@example.com", entry)
+	})
+
+	t.Run("domains lose their @", func(t *testing.T) {
+		entry, ok := AllowlistEntry("@Improwised.com")
+		assert.True(t, ok)
+		assert.Equal(t, "improwised.com", entry)
+	})
+
+	t.Run("invalid entries", func(t *testing.T) {
+		for _, entry := range []string{"", "localhost", "jane@", "Jane <jane@example.com>", "-bad.com", "bad_domain.com", "a..b"} {
+			_, ok := AllowlistEntry(entry)
+			assert.False(t, ok, entry)
+		}
+	})
+}
+
+func TestIsAllowlisted(t *testing.T) {
+	entries := []string{"jane@example.com", "improwised.com"}
+
+	t.Run("listed email", func(t *testing.T) {
+		assert.True(t, IsAllowlisted("Jane@Example.com", entries))
+	})
+
+	t.Run("listed domain", func(t *testing.T) {
+		assert.True(t, IsAllowlisted("john@improwised.com", entries))
+	})
+
+	t.Run("not listed", func(t *testing.T) {
+		assert.False(t, IsAllowlisted("john@example.com", entries))
+		assert.False(t, IsAllowlisted("john@sub.improwised.com", entries))
+		assert.False(t, IsAllowlisted("", entries))
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/Improwised/jovvix/api/constants"
 	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
 	"github.com/Improwised/jovvix/api/pkg/jwt"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
 	"github.com/Improwised/jovvix/api/utils"
 	fiber "github.com/gofiber/fiber/v2"
 	j "github.com/lestrrat-go/jwx/v2/jwt"
@@ -44,6 +45,15 @@ func (m *Middleware) CustomAuthenticated(c *fiber.Ctx) error {
 	}
 }
 
+// OptionalAuthenticated authenticates a request carrying a cookie like CustomAuthenticated and lets one
+// without any through anonymous, the handler decides what an anonymous caller may see
+func (m *Middleware) OptionalAuthenticated(c *fiber.Ctx) error {
+	if c.Cookies(constants.KratosCookie) == "" && c.Cookies(constants.CookieUser) == "" {
+		return c.Next()
+	}
+	return m.CustomAuthenticated(c)
+}
+
 func (m *Middleware) CheckSessionId(c *fiber.Ctx) error {
 
 	// get session id from param
@@ -66,6 +76,17 @@ func (m *Middleware) CheckSessionCode(c *fiber.Ctx) error {
 	return c.Next()
 }
 
+// NegotiateProtocol picks the protocol version of a socket from the query, before it is upgraded
+func (m *Middleware) NegotiateProtocol(c *fiber.Ctx) error {
+	version, err := protocol.Negotiate(c.Query(protocol.VersionParam))
+	if err != nil {
+		return utils.JSONFail(c, http.StatusBadRequest, err.Error())
+	}
+
+	c.Locals(constants.ProtocolVersion, version)
+	return c.Next()
+}
+
 func AuthHavingTokenHandler(m *Middleware, c *fiber.Ctx, token string) error {
 	// JWK verification
 	claims, err := jwt.ParseToken(m.Config, token)
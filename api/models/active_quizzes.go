@@ -28,10 +28,34 @@ type ActiveQuiz struct {
 	CurrentQuestion      sql.NullString `json:"current_question" db:"current_question"`
 	IsQuestionActive     sql.NullBool   `json:"is_question_active" db:"is_question_active"`
 	QuestionDeliveryTime sql.NullTime   `json:"question_time" db:"question_delivery_time"`
+	Phase                string         `json:"phase" db:"phase"`
+	PhaseDeadline        sql.NullTime   `json:"phase_deadline" db:"phase_deadline"`
+	IsPaused             bool           `json:"is_paused" db:"is_paused"`
+	PausedRemainingMs    sql.NullInt64  `json:"paused_remaining_ms" db:"paused_remaining_ms"`
+	Mode                 string         `json:"mode" db:"mode"`
+	TeamMode             sql.NullString `json:"team_mode" db:"team_mode"`
+	TeamScoring          string         `json:"team_scoring" db:"team_scoring"`
+	TeamBestN            sql.NullInt32  `json:"team_best_n" db:"team_best_n"`
+	ShuffleQuestions     bool           `json:"shuffle_questions" db:"shuffle_questions"`
+	ShuffleOptions       bool           `json:"shuffle_options" db:"shuffle_options"`
+	MaxPlayers           sql.NullInt32  `json:"max_players" db:"max_players"`
+	AllowLateJoin        bool           `json:"allow_late_join" db:"allow_late_join"`
+	LateJoinCredit       string         `json:"late_join_credit" db:"late_join_credit"`
+	IsLobbyLocked        bool           `json:"is_lobby_locked" db:"is_lobby_locked"`
+	AccessMode           string         `json:"access_mode" db:"access_mode"`
+	PasscodeHash         sql.NullString `json:"-" db:"passcode_hash"`
 	CreatedAt            time.Time      `json:"created_at,omitempty" db:"created_at,omitempty"`
 	UpdatedAt            time.Time      `json:"updated_at,omitempty" db:"updated_at,omitempty"`
 }
 
+// SessionState is the progress of a running session that outlives the host's socket
+type SessionState struct {
+	Phase           string        `json:"phase"`
+	Deadline        sql.NullTime  `json:"deadline"`
+	IsPaused        bool          `json:"is_paused"`
+	PausedRemaining time.Duration `json:"paused_remaining"`
+}
+
 // ActiveSessionSummary model
 type ActiveSessionSummary struct {
 	ID             uuid.UUID  `json:"id" db:"id"`
@@ -39,6 +63,8 @@ type ActiveSessionSummary struct {
 	QuizID         uuid.UUID  `json:"quiz_id" db:"quiz_id"`
 	InvitationCode *int32     `json:"invitation_code" db:"invitation_code"`
 	ActivatedFrom  *time.Time `json:"activated_from" db:"activated_from"`
+	ActivatedTo    *time.Time `json:"activated_to" db:"activated_to"`
+	Mode           string     `json:"mode" db:"mode"`
 }
 
 // ActiveQuizModel implements quiz session related database operations
@@ -54,14 +80,15 @@ func InitActiveQuizModel(goqu *goqu.Database, logger *zap.Logger) *ActiveQuizMod
 	return &ActiveQuizModel{db: goqu, defaultUUID: uuid, logger: logger}
 }
 
-func (model *ActiveQuizModel) CreateActiveQuiz(title string, quizID string, adminID string, activatedTo sql.NullTime, activatedFrom sql.NullTime) (uuid.UUID, error) {
+// CreateActiveQuiz creates an inactive session, assignments keep their opening window in activated_from/activated_to
+func (model *ActiveQuizModel) CreateActiveQuiz(title string, quizID string, adminID string, activatedTo sql.NullTime, activatedFrom sql.NullTime, mode string) (uuid.UUID, error) {
 
-	if activatedFrom.Valid && activatedFrom.Time.Before(time.Now()) {
-		return model.defaultUUID, fmt.Errorf("session can not start with %s", activatedTo.Time)
+	if activatedTo.Valid && activatedTo.Time.Before(time.Now()) {
+		return model.defaultUUID, fmt.Errorf(constants.ErrAssignmentWindow)
 	}
 
-	if activatedFrom.Valid && activatedTo.Time.Before(activatedTo.Time) {
-		return model.defaultUUID, fmt.Errorf("can not ends session before starting")
+	if activatedFrom.Valid && activatedTo.Valid && !activatedTo.Time.After(activatedFrom.Time) {
+		return model.defaultUUID, fmt.Errorf(constants.ErrAssignmentWindow)
 	}
 
 	id, err := uuid.NewUUID()
@@ -77,15 +104,7 @@ func (model *ActiveQuizModel) CreateActiveQuiz(title string, quizID string, admi
 		"admin_id":       adminID,
 		"activated_to":   activatedTo,
 		"activated_from": activatedFrom,
-	}
-
-	if activatedFrom.Valid {
-		record["activated_from"] = nil
-		record["activated_to"] = nil
-	}
-
-	if activatedTo.Valid {
-		record["activated_to"] = nil
+		"mode":           mode,
 	}
 
 	_, err = model.db.Insert(ActiveQuizzesTable).Rows(record).Executor().Exec()
@@ -120,6 +139,7 @@ func (model *ActiveQuizModel) GetActiveQuizByQuizIDAndAdminID(quizID string, adm
 		goqu.I("quiz_id").Eq(quizID),
 		goqu.I("admin_id").Eq(adminID),
 		goqu.I("is_active").Eq(true),
+		goqu.I("mode").Eq(constants.SessionModeLive),
 	).Order(goqu.I("updated_at").Desc()).Limit(1).ScanStruct(&activeQuiz)
 
 	if err != nil {
@@ -136,7 +156,7 @@ func (model *ActiveQuizModel) GetActiveQuizByQuizIDAndAdminID(quizID string, adm
 func (model *ActiveQuizModel) GetActiveSessionsByAdminID(adminID string) ([]ActiveSessionSummary, error) {
 	sessions := []ActiveSessionSummary{}
 
-	err := model.db.Select("id", "title", "quiz_id", "invitation_code", "activated_from").
+	err := model.db.Select("id", "title", "quiz_id", "invitation_code", "activated_from", "activated_to", "mode").
 		From(ActiveQuizzesTable).
 		Where(
 			goqu.I("admin_id").Eq(adminID),
@@ -262,6 +282,11 @@ func (model *ActiveQuizModel) GetOrActivateSession(sessionId string, userId stri
 		return activeQuiz, fmt.Errorf(constants.Unauthenticated)
 	}
 
+	// assignments are opened when they are created and never hosted live
+	if activeQuiz.Mode == constants.SessionModeAssignment {
+		return activeQuiz, fmt.Errorf(constants.ErrAssignmentIsSelfPaced)
+	}
+
 	if activeQuiz.IsActive {
 		isOk = true
 		return activeQuiz, nil
@@ -326,7 +351,7 @@ func activateSession(transactionObj *goqu.TxDatabase, maxTry int, sessionId uuid
 		SET
 			invitation_code=$3,
 			is_active=true,
-			activated_from=now(),
+			activated_from=coalesce(activated_from, now()),
 			updated_at=now()
 		WHERE
 			id=$1 and
@@ -372,6 +397,9 @@ func (model *ActiveQuizModel) Deactivate(id uuid.UUID) error {
 		"activated_to":       goqu.L("now()"),
 		"current_question":   nil,
 		"is_question_active": nil,
+		"phase":              constants.SessionPhaseFinished,
+		"phase_deadline":     nil,
+		"is_paused":          false,
 		"updated_at":         goqu.L("now()"),
 	}).Where(goqu.I("id").Eq(id)).Executor().Exec()
 
@@ -402,9 +430,13 @@ func (model *ActiveQuizModel) DeactivateExpired(ttl time.Duration) (int64, error
 		"activated_to":       goqu.L("now()"),
 		"current_question":   nil,
 		"is_question_active": nil,
+		"phase":              constants.SessionPhaseFinished,
+		"phase_deadline":     nil,
+		"is_paused":          false,
 		"updated_at":         goqu.L("now()"),
 	}).Where(
 		goqu.I("is_active").Eq(true),
+		goqu.I("mode").Eq(constants.SessionModeLive),
 		goqu.L("activated_from < now() - ? * interval '1 second'", ttlSeconds),
 	).Executor().Exec()
 
@@ -415,6 +447,232 @@ func (model *ActiveQuizModel) DeactivateExpired(ttl time.Duration) (int64, error
 	return result.RowsAffected()
 }
 
+// CloseExpiredAssignments deactivates the assignments whose window is over, activated_to keeps the closing time
+func (model *ActiveQuizModel) CloseExpiredAssignments() (int64, error) {
+	result, err := model.db.Update(ActiveQuizzesTable).Set(goqu.Record{
+		"invitation_code": nil,
+		"is_active":       false,
+		"phase":           constants.SessionPhaseFinished,
+		"updated_at":      goqu.L("now()"),
+	}).Where(
+		goqu.I("is_active").Eq(true),
+		goqu.I("mode").Eq(constants.SessionModeAssignment),
+		goqu.L("activated_to <= now()"),
+	).Executor().Exec()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// OpenAssignment hands out the invitation code of an assignment right away, players may join once its window opens
+func (model *ActiveQuizModel) OpenAssignment(sessionId uuid.UUID, adminID string) (ActiveQuiz, error) {
+	var activeQuiz ActiveQuiz = ActiveQuiz{}
+	var isOk bool = false
+
+	transactionObj, err := model.db.Begin()
+
+	if err != nil {
+		return activeQuiz, err
+	}
+
+	defer func() {
+		if isOk {
+			err = transactionObj.Commit()
+			if err != nil {
+				model.logger.Error("error is transaction commit during OpenAssignment", zap.Error(err))
+			}
+		} else {
+			err = transactionObj.Rollback()
+			if err != nil {
+				model.logger.Error("error is transaction rollback during OpenAssignment", zap.Error(err))
+			}
+		}
+	}()
+
+	maxTry := 10
+	_, err = activateSession(transactionObj, maxTry, sessionId, adminID)
+	if err != nil {
+		return activeQuiz, err
+	}
+
+	activeQuiz, err = model.GetSessionById(transactionObj, sessionId.String())
+	if err != nil {
+		return activeQuiz, err
+	}
+
+	isOk = true
+	return activeQuiz, nil
+}
+
+// SetShuffle turns the per-player order of the questions and of the options of a session on or off
+func (model *ActiveQuizModel) SetShuffle(id uuid.UUID, shuffleQuestions bool, shuffleOptions bool) error {
+	_, err := model.db.Update(ActiveQuizzesTable).Set(goqu.Record{
+		"shuffle_questions": shuffleQuestions,
+		"shuffle_options":   shuffleOptions,
+		"updated_at":        goqu.L("now()"),
+	}).Where(goqu.I("id").Eq(id)).Executor().Exec()
+	return err
+}
+
+// SetJoinPolicy changes how many players a session takes and whether they may join it once it started
+func (model *ActiveQuizModel) SetJoinPolicy(id uuid.UUID, maxPlayers sql.NullInt32, allowLateJoin bool, lateJoinCredit string) error {
+	_, err := model.db.Update(ActiveQuizzesTable).Set(goqu.Record{
+		"max_players":      maxPlayers,
+		"allow_late_join":  allowLateJoin,
+		"late_join_credit": lateJoinCredit,
+		"updated_at":       goqu.L("now()"),
+	}).Where(goqu.I("id").Eq(id)).Executor().Exec()
+	return err
+}
+
+// SetLobbyLock keeps the players who are not in a session yet out of it, or lets them in again
+func (model *ActiveQuizModel) SetLobbyLock(id uuid.UUID, isLocked bool) error {
+	_, err := model.db.Update(ActiveQuizzesTable).Set(goqu.Record{
+		"is_lobby_locked": isLocked,
+		"updated_at":      goqu.L("now()"),
+	}).Where(goqu.I("id").Eq(id)).Executor().Exec()
+	return err
+}
+
+// ShuffleQuestionOrder puts the questions of a live session in a random order. Players of a live session
+// answer together, so the order is the same for all of them.
+func (model *ActiveQuizModel) ShuffleQuestionOrder(id uuid.UUID) error {
+	var isOk bool = false
+
+	transactionObj, err := model.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if isOk {
+			err = transactionObj.Commit()
+			if err != nil {
+				model.logger.Error("error is transaction commit during ShuffleQuestionOrder", zap.Error(err))
+			}
+		} else {
+			err = transactionObj.Rollback()
+			if err != nil {
+				model.logger.Error("error is transaction rollback during ShuffleQuestionOrder", zap.Error(err))
+			}
+		}
+	}()
+
+	questionIDs := []uuid.UUID{}
+	err = transactionObj.From(ActiveQuizQuestionsTable).Select("question_id").Where(goqu.I("active_quiz_id").Eq(id)).
+		Order(goqu.L("random()").Asc()).ScanVals(&questionIDs)
+	if err != nil {
+		return err
+	}
+
+	for index, questionID := range questionIDs {
+		nextQuestion := uuid.NullUUID{}
+		if index+1 < len(questionIDs) {
+			nextQuestion = uuid.NullUUID{UUID: questionIDs[index+1], Valid: true}
+		}
+
+		_, err = transactionObj.Update(ActiveQuizQuestionsTable).Set(goqu.Record{
+			"order_no":      index + 1,
+			"next_question": nextQuestion,
+			"updated_at":    goqu.L("now()"),
+		}).Where(goqu.I("active_quiz_id").Eq(id), goqu.I("question_id").Eq(questionID)).Executor().Exec()
+		if err != nil {
+			return err
+		}
+	}
+
+	isOk = true
+	return nil
+}
+
+// SaveSessionState persists the phase, timer deadline and pause flag of a running session
+func (model *ActiveQuizModel) SaveSessionState(id uuid.UUID, state SessionState) error {
+	record := goqu.Record{
+		"phase":               state.Phase,
+		"phase_deadline":      nil,
+		"is_paused":           state.IsPaused,
+		"paused_remaining_ms": nil,
+		"updated_at":          goqu.L("now()"),
+	}
+
+	if state.Deadline.Valid {
+		record["phase_deadline"] = state.Deadline.Time
+	}
+
+	if state.IsPaused {
+		record["paused_remaining_ms"] = state.PausedRemaining.Milliseconds()
+	}
+
+	_, err := model.db.Update(ActiveQuizzesTable).Set(record).Where(goqu.I("id").Eq(id), goqu.I("is_active").Eq(true)).Executor().Exec()
+	return err
+}
+
+// GetRunningSessions returns the active sessions whose question loop was already started
+func (model *ActiveQuizModel) GetRunningSessions() ([]ActiveQuiz, error) {
+	sessions := []ActiveQuiz{}
+
+	err := model.db.Select("*").From(ActiveQuizzesTable).Where(
+		goqu.I("is_active").Eq(true),
+		goqu.I("phase").NotIn(constants.SessionPhaseLobby, constants.SessionPhaseFinished),
+	).ScanStructs(&sessions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// SessionState returns the persisted state of a session, used when no live driver owns it
+func (session ActiveQuiz) SessionState() SessionState {
+	state := SessionState{
+		Phase:    session.Phase,
+		Deadline: session.PhaseDeadline,
+		IsPaused: session.IsPaused,
+	}
+
+	if state.Phase == "" {
+		state.Phase = constants.SessionPhaseLobby
+	}
+
+	if session.PausedRemainingMs.Valid {
+		state.PausedRemaining = time.Duration(session.PausedRemainingMs.Int64) * time.Millisecond
+	}
+
+	return state
+}
+
+// IsAssignment reports whether players work through the session at their own pace
+func (session ActiveQuiz) IsAssignment() bool {
+	return session.Mode == constants.SessionModeAssignment
+}
+
+// CheckAssignmentWindow fails when the assignment is not open at the given time
+func (session ActiveQuiz) CheckAssignmentWindow(now time.Time) error {
+	if session.ActivatedFrom.Valid && now.Before(session.ActivatedFrom.Time) {
+		return fmt.Errorf(constants.ErrAssignmentNotOpen)
+	}
+
+	if !session.IsActive || (session.ActivatedTo.Valid && !now.Before(session.ActivatedTo.Time)) {
+		return fmt.Errorf(constants.ErrAssignmentClosed)
+	}
+
+	return nil
+}
+
+// HasTeams reports whether the host split the players of the session into teams
+func (session ActiveQuiz) HasTeams() bool {
+	return session.TeamMode.Valid
+}
+
+// IsStarted reports whether the host already started the question loop of the session
+func (session ActiveQuiz) IsStarted() bool {
+	return session.CurrentQuestion.Valid || (session.Phase != "" && session.Phase != constants.SessionPhaseLobby)
+}
+
 func (model *ActiveQuizModel) GetCurrentActiveQuestion(id uuid.UUID) (uuid.UUID, error) {
 	var currentQuestion uuid.UUID
 	found, err := model.db.Select("current_question").From(ActiveQuizzesTable).Where(goqu.I("id").Eq(id), goqu.I("is_question_active").Eq(true)).ScanVal(&currentQuestion)
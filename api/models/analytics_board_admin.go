@@ -12,11 +12,14 @@ import (
 type AnalyticsBoardAdmin struct {
 	UserName         string            `db:"username" json:"username"`
 	FirstName        string            `db:"first_name" json:"firstname"`
+	TeamName         sql.NullString    `db:"team_name" json:"team_name"`
 	SelectedAnswer   sql.NullString    `db:"selected_answer,omitempty" json:"selected_answer"`
 	CorrectAnswer    string            `db:"correct_answer,omitempty" json:"correct_answer"`
 	CalculatedScore  int               `db:"calculated_score,omitempty" json:"calculated_score"`
 	IsAttend         bool              `db:"is_attend,omitempty" json:"is_attend"`
 	ResponseTime     int               `db:"response_time,omitempty" json:"response_time"`
+	ClientTime       sql.NullInt32     `db:"client_response_time" json:"client_response_time"`
+	IsTimeAnomaly    bool              `db:"is_time_anomaly" json:"is_time_anomaly"`
 	CalculatedPoints int               `db:"calculated_points,omitempty" json:"calculated_points"`
 	Question         string            `db:"question,omitempty" json:"question"`
 	RawOptions       []byte            `db:"options,omitempty" json:"raw_options"`
@@ -50,11 +53,14 @@ func (model *AnalyticsBoardAdminModel) GetAnalyticsForAdmin(activeQuizId string)
 		Select(
 			"username",
 			"first_name",
+			goqu.I(SessionTeamsTable+".name").As("team_name"),
 			goqu.I(constants.UserQuizResponsesTable+".answers").As("selected_answer"),
 			goqu.I(constants.QuestionsTable+".answers").As("correct_answer"),
 			"calculated_score",
 			"is_attend",
 			"response_time",
+			"client_response_time",
+			"is_time_anomaly",
 			"calculated_points",
 			"question",
 			"options",
@@ -68,6 +74,7 @@ func (model *AnalyticsBoardAdminModel) GetAnalyticsForAdmin(activeQuizId string)
 		InnerJoin(goqu.T(constants.QuestionsTable), goqu.On(goqu.I(constants.UserQuizResponsesTable+".question_id").Eq(goqu.I(constants.QuestionsTable+".id")))).
 		InnerJoin(goqu.T(constants.UserPlayedQuizzesTable), goqu.On(goqu.I(constants.UserPlayedQuizzesTable+".id").Eq(goqu.I(constants.UserQuizResponsesTable+".user_played_quiz_id")))).
 		InnerJoin(goqu.T(constants.UsersTable), goqu.On(goqu.I(constants.UsersTable+".id").Eq(goqu.I(constants.UserPlayedQuizzesTable+".user_id")))).
+		LeftJoin(goqu.T(SessionTeamsTable), goqu.On(goqu.I(SessionTeamsTable+".id").Eq(goqu.I(constants.UserPlayedQuizzesTable+".team_id")))).
 		InnerJoin(goqu.T(constants.ActiveQuizQuestionsTable), goqu.On(goqu.I(constants.UserPlayedQuizzesTable+".active_quiz_id").Eq(goqu.I(constants.ActiveQuizQuestionsTable+".active_quiz_id")), goqu.I(constants.QuestionsTable+".id").Eq(goqu.I(constants.ActiveQuizQuestionsTable+".question_id")))).
 		Where(goqu.Ex{
 			constants.UserPlayedQuizzesTable + ".active_quiz_id": activeQuizId,
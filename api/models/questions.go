@@ -43,11 +43,25 @@ type Question struct {
 	QuestionMedia     string            `json:"question_media" db:"question_media"`
 	OptionsMedia      string            `json:"options_media" db:"options_media"`
 	Resource          sql.NullString    `json:"resource" db:"resource"`
+	Grading           string            `json:"grading" db:"grading"`
+	// AnswerRules are the json rules of the types answered without options
+	AnswerRules []byte `json:"-" db:"answer_rules"`
+}
+
+// AnswerKey is what an answer to the question is scored against
+type AnswerKey struct {
+	Answers           []int
+	Points            int16
+	DurationInSeconds int
+	Type              int
+	Grading           string
+	AnswerRules       []byte
 }
 
 type QuestionForUser struct {
 	ID                uuid.UUID         `json:"id" db:"id"`
 	Question          string            `json:"question" db:"question"`
+	Type              int               `json:"type" db:"type"`
 	RawOptions        []byte            `json:"omitempty" db:"options"`
 	Options           map[string]string `json:"options" db:"omitempty"`
 	DurationInSeconds int               `json:"duration" db:"duration_in_seconds"`
@@ -56,6 +70,11 @@ type QuestionForUser struct {
 	QuestionMedia     string            `json:"question_media" db:"question_media"`
 	OptionsMedia      string            `json:"options_media" db:"options_media"`
 	Resource          sql.NullString    `json:"resource" db:"resource"`
+	// Unit is the unit a numeric answer is given in
+	Unit sql.NullString `json:"unit" db:"unit"`
+	// Definitions are what the options of a matching question are paired with
+	RawDefinitions []byte            `json:"-" db:"definitions"`
+	Definitions    map[string]string `json:"definitions" db:"-"`
 }
 
 // QuizModel implements quiz related database operations
@@ -222,6 +241,8 @@ func registerQuestions(transaction *goqu.TxDatabase, questions []Question) ([]uu
 			"question_media":      question.QuestionMedia,
 			"options_media":       question.OptionsMedia,
 			"resource":            question.Resource.String,
+			"grading":             question.Grading,
+			"answer_rules":        sql.NullString{String: string(question.AnswerRules), Valid: len(question.AnswerRules) > 0},
 		})
 	}
 
@@ -302,6 +323,8 @@ func (model *QuestionModel) GetQuestionById(QuestionId string) (structs.Question
 			"resource",
 			"points",
 			"type",
+			"grading",
+			"answer_rules",
 			"duration_in_seconds",
 		).
 		Where(goqu.Ex{
@@ -318,6 +341,11 @@ func (model *QuestionModel) GetQuestionById(QuestionId string) (structs.Question
 		return questionAnalytics, err
 	}
 
+	err = decodeAnswerRules(&questionAnalytics)
+	if err != nil {
+		return questionAnalytics, err
+	}
+
 	questionAnalytics.QuestionType, err = quizUtilsHelper.GetQuestionType(questionAnalytics.QuestionTypeID)
 	if err != nil {
 		return questionAnalytics, err
@@ -367,6 +395,8 @@ func (model *QuestionModel) ListQuestionsWithAnswerByQuizId(QuizId string, media
 			   q.resource,
 			   q.points,
 			   q.type,
+			   q.grading,
+			   q.answer_rules,
 			   q.duration_in_seconds
 		FROM chain
 		JOIN questions q ON q.id = chain.question_id`
@@ -385,6 +415,11 @@ func (model *QuestionModel) ListQuestionsWithAnswerByQuizId(QuizId string, media
 	for index := 0; index < len(questionAnalytics); index++ {
 		json.Unmarshal(questionAnalytics[index].RawOptions, &questionAnalytics[index].Options)
 
+		err = decodeAnswerRules(&questionAnalytics[index])
+		if err != nil {
+			return nil, quizPlayedCount, err
+		}
+
 		questionAnalytics[index].QuestionType, err = quizUtilsHelper.GetQuestionType(questionAnalytics[index].QuestionTypeID)
 		if err != nil {
 			return nil, quizPlayedCount, err
@@ -394,34 +429,57 @@ func (model *QuestionModel) ListQuestionsWithAnswerByQuizId(QuizId string, media
 	return questionAnalytics, quizPlayedCount, nil
 }
 
+// decodeAnswerRules gives the author back the rules of a question answered without options
+func decodeAnswerRules(question *structs.QuestionAnalytics) error {
+	if len(question.RawAnswerRules) == 0 {
+		return nil
+	}
+
+	switch question.QuestionTypeID {
+	case constants.ShortAnswer:
+		question.ShortAnswer = &structs.ShortAnswerRules{}
+		return json.Unmarshal(question.RawAnswerRules, question.ShortAnswer)
+	case constants.Numeric:
+		question.Numeric = &structs.NumericRules{}
+		return json.Unmarshal(question.RawAnswerRules, question.Numeric)
+	case constants.Matching:
+		question.Matching = &structs.MatchingRules{}
+		return json.Unmarshal(question.RawAnswerRules, question.Matching)
+	}
+	return nil
+}
+
 func (model *QuestionModel) GetAnswersPointsDurationType(QuestionID string) ([]int, int16, int, int, error) {
+	answerKey, err := model.GetAnswerKey(QuestionID)
+	return answerKey.Answers, answerKey.Points, answerKey.DurationInSeconds, answerKey.Type, err
+}
+
+// GetAnswerKey returns what answers to the question are scored against
+func (model *QuestionModel) GetAnswerKey(QuestionID string) (AnswerKey, error) {
 
-	var answers []int = []int{}
-	var answerDurationInSeconds int
+	var answerKey AnswerKey = AnswerKey{Answers: []int{}}
 	var answerBytes []byte = []byte{}
-	var answerPoints int16
-	var questionType int
 
-	rows, err := model.db.Select(goqu.I("answers"), goqu.I("points"), goqu.I("duration_in_seconds"), goqu.I("type")).From(QuestionTable).Where(goqu.I("id").Eq(QuestionID)).Executor().Query()
+	rows, err := model.db.Select(goqu.I("answers"), goqu.I("points"), goqu.I("duration_in_seconds"), goqu.I("type"), goqu.I("grading"), goqu.I("answer_rules")).From(QuestionTable).Where(goqu.I("id").Eq(QuestionID)).Executor().Query()
 
 	if err != nil {
-		return answers, answerPoints, answerDurationInSeconds, 0, err
+		return answerKey, err
 	}
 	defer rows.Close()
 
 	if rows.Next() {
-		err = rows.Scan(&answerBytes, &answerPoints, &answerDurationInSeconds, &questionType)
+		err = rows.Scan(&answerBytes, &answerKey.Points, &answerKey.DurationInSeconds, &answerKey.Type, &answerKey.Grading, &answerKey.AnswerRules)
 		if err != nil {
-			return answers, answerPoints, answerDurationInSeconds, 0, err
+			return answerKey, err
 		}
 	}
 
-	err = json.Unmarshal(answerBytes, &answers)
+	err = json.Unmarshal(answerBytes, &answerKey.Answers)
 	if err != nil {
-		return answers, answerPoints, answerDurationInSeconds, 0, err
+		return answerKey, err
 	}
 
-	return answers, answerPoints, answerDurationInSeconds, questionType, nil
+	return answerKey, nil
 }
 
 func (model *QuestionModel) GetCurrentQuestion(id uuid.UUID) (QuestionForUser, error) {
@@ -433,11 +491,14 @@ func (model *QuestionModel) GetCurrentQuestion(id uuid.UUID) (QuestionForUser, e
 			"order_no",
 			"duration_in_seconds",
 			"question",
+			"type",
 			"options",
 			"points",
 			"question_media",
 			"options_media",
 			"resource",
+			goqu.L("answer_rules->>'unit'").As("unit"),
+			goqu.L("answer_rules->'definitions'").As("definitions"),
 		).InnerJoin(
 		goqu.T(constants.ActiveQuizQuestionsTable), goqu.On(goqu.I(constants.QuestionsTable+".id").Eq(goqu.I(constants.ActiveQuizQuestionsTable+".question_id")))).
 		Where(goqu.Ex{
@@ -453,6 +514,12 @@ func (model *QuestionModel) GetCurrentQuestion(id uuid.UUID) (QuestionForUser, e
 		if err != nil {
 			return QuestionForUser{}, err
 		}
+		if len(question.RawDefinitions) > 0 {
+			err = json.Unmarshal(question.RawDefinitions, &question.Definitions)
+			if err != nil {
+				return QuestionForUser{}, err
+			}
+		}
 		return question, nil
 	}
 }
@@ -491,6 +558,8 @@ func (model *QuestionModel) CreateQuestion(transaction *goqu.TxDatabase, questio
 			"question_media":      question.QuestionMedia,
 			"options_media":       question.OptionsMedia,
 			"resource":            question.Resource.String,
+			"grading":             question.Grading,
+			"answer_rules":        sql.NullString{String: string(question.AnswerRules), Valid: len(question.AnswerRules) > 0},
 			"created_at":          goqu.L("now()"),
 			"updated_at":          goqu.L("now()"),
 		},
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/Improwised/jovvix/api/constants"
+	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
+	"github.com/Improwised/jovvix/api/pkg/structs"
 	"github.com/doug-martin/goqu/v9"
 	"github.com/google/uuid"
 )
@@ -36,6 +38,12 @@ type QuizAnalysis struct {
 	SelectedAnswers   map[string]interface{} `json:"selected_answers" db:"selected_answers"`
 	DurationInSeconds int                    `json:"duration" db:"duration_in_seconds"`
 	AvgResponseTime   float32                `json:"avg_response_time" db:"avg_response_time"`
+	TimeAnomalies     []string               `json:"time_anomalies" db:"time_anomalies"`
+	// OptionSelections is how often each option was picked
+	OptionSelections []structs.OptionSelection `json:"option_selections" db:"-"`
+	// Numeric is the expected value of a numeric question and ValueDistribution how the sent values spread
+	Numeric           *structs.NumericRules      `json:"numeric,omitempty" db:"-"`
+	ValueDistribution *structs.ValueDistribution `json:"value_distribution,omitempty" db:"-"`
 }
 
 type QuizzesAnalysis struct {
@@ -354,6 +362,8 @@ func (model *QuizModel) GetSharedQuestions(invitationCode int) ([]Question, sql.
 			order_no,
 			question_delivery_time,
 			question,
+			type,
+			answer_rules,
 			options,
 			answers,
 			points,
@@ -395,7 +405,7 @@ func (model *QuizModel) GetSharedQuestions(invitationCode int) ([]Question, sql.
 		question := Question{}
 		var options []byte
 		var answers []byte
-		err := rows.Scan(&question.ID, &question.QuizId, &question.OrderNumber, &QuestionDeliveryTime, &question.Question, &options, &answers, &question.Points, &question.DurationInSeconds, &question.QuestionMedia, &question.OptionsMedia, &question.Resource, &question.CreatedAt, &question.UpdatedAt)
+		err := rows.Scan(&question.ID, &question.QuizId, &question.OrderNumber, &QuestionDeliveryTime, &question.Question, &question.Type, &question.AnswerRules, &options, &answers, &question.Points, &question.DurationInSeconds, &question.QuestionMedia, &question.OptionsMedia, &question.Resource, &question.CreatedAt, &question.UpdatedAt)
 		if err != nil {
 
 			return nil, QuestionDeliveryTime, err
@@ -490,6 +500,8 @@ func (model *QuizModel) GetQuizAnalysis(activeQuizId string) ([]QuizAnalysis, er
 			goqu.C("question_id"),
 			goqu.L("jsonb_object_agg(?, ?)", goqu.I("u.username"), goqu.I("uqr.answers")).As("selected_answers"),
 			goqu.L("avg(?)", goqu.I("response_time")).As("avg_response_time"),
+			goqu.L("coalesce(jsonb_agg(?) filter (where ?), '[]')", goqu.I("u.username"), goqu.I("uqr.is_time_anomaly")).As("time_anomalies"),
+			goqu.L("coalesce(jsonb_agg(?) filter (where ? is not null), '[]')", goqu.I("uqr.answer_value"), goqu.I("uqr.answer_value")).As("submitted_values"),
 		).
 		Where(goqu.Ex{"upq.active_quiz_id": activeQuizId}).
 		GroupBy(goqu.C("question_id").Table("uqr"))
@@ -514,6 +526,9 @@ func (model *QuizModel) GetQuizAnalysis(activeQuizId string) ([]QuizAnalysis, er
 			goqu.C("duration_in_seconds").Table("q"),
 			goqu.C("avg_response_time").Table("a"),
 			goqu.C("type").Table("q"),
+			goqu.C("time_anomalies").Table("a"),
+			goqu.C("answer_rules").Table("q"),
+			goqu.C("submitted_values").Table("a"),
 		)
 
 	rows, err := query.Executor().Query()
@@ -530,7 +545,10 @@ func (model *QuizModel) GetQuizAnalysis(activeQuizId string) ([]QuizAnalysis, er
 		var options []byte
 		var answers []byte
 		var selectedAnswer []byte
-		err := rows.Scan(&quizAnalysisRow.ID, &quizAnalysisRow.Question, &options, &quizAnalysisRow.QuestionsMedia, &quizAnalysisRow.OptionsMedia, &quizAnalysisRow.Resource, &answers, &selectedAnswer, &quizAnalysisRow.DurationInSeconds, &quizAnalysisRow.AvgResponseTime, &quizAnalysisRow.Type)
+		var timeAnomalies []byte
+		var answerRules []byte
+		var submittedValues []byte
+		err := rows.Scan(&quizAnalysisRow.ID, &quizAnalysisRow.Question, &options, &quizAnalysisRow.QuestionsMedia, &quizAnalysisRow.OptionsMedia, &quizAnalysisRow.Resource, &answers, &selectedAnswer, &quizAnalysisRow.DurationInSeconds, &quizAnalysisRow.AvgResponseTime, &quizAnalysisRow.Type, &timeAnomalies, &answerRules, &submittedValues)
 		if err != nil {
 
 			return nil, err
@@ -554,12 +572,51 @@ func (model *QuizModel) GetQuizAnalysis(activeQuizId string) ([]QuizAnalysis, er
 			return nil, err
 		}
 
+		err = json.Unmarshal(timeAnomalies, &quizAnalysisRow.TimeAnomalies)
+
+		if err != nil {
+			return nil, err
+		}
+
+		quizAnalysisRow.OptionSelections = quizUtilsHelper.OptionSelections(quizAnalysisRow.Options, quizAnalysisRow.CorrectAnswers, selectedKeys(quizAnalysisRow.SelectedAnswers))
+
+		// numeric questions have no options, the values sent are shown instead
+		if quizAnalysisRow.Type == constants.Numeric {
+			quizAnalysisRow.Numeric = &structs.NumericRules{}
+			if err := json.Unmarshal(answerRules, quizAnalysisRow.Numeric); err != nil {
+				return nil, err
+			}
+
+			values := []float64{}
+			if err := json.Unmarshal(submittedValues, &values); err != nil {
+				return nil, err
+			}
+			distribution := quizUtilsHelper.ValueDistribution(values)
+			quizAnalysisRow.ValueDistribution = &distribution
+		}
+
 		quizAnalysis = append(quizAnalysis, quizAnalysisRow)
 	}
 
 	return quizAnalysis, err
 }
 
+// selectedKeys returns the option keys each player picked, the answers are decoded from json so the keys are floats
+func selectedKeys(selectedAnswers map[string]interface{}) [][]int {
+	selections := make([][]int, 0, len(selectedAnswers))
+	for _, answer := range selectedAnswers {
+		keys, _ := answer.([]interface{})
+		picked := make([]int, 0, len(keys))
+		for _, key := range keys {
+			if number, ok := key.(float64); ok {
+				picked = append(picked, int(number))
+			}
+		}
+		selections = append(selections, picked)
+	}
+	return selections
+}
+
 func (model *QuizModel) ListQuizzesAnalysis(name, order, orderBy, date, userId string, page int) ([]QuizzesAnalysis, int64, error) {
 
 	var quizzesAnalysis []QuizzesAnalysis
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPasscode returns the hash a session passcode is kept as
+func HashPasscode(passcode string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(passcode), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPasscode reports whether passcode is the one the host set for the session
+func (session ActiveQuiz) CheckPasscode(passcode string) bool {
+	if !session.PasscodeHash.Valid {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(session.PasscodeHash.String), []byte(passcode)) == nil
+}
+
+// SetAccess changes who may join a session, the allowlist is replaced by entries
+func (model *ActiveQuizModel) SetAccess(id uuid.UUID, accessMode string, passcodeHash sql.NullString, entries []string) error {
+	var isOk bool = false
+
+	transactionObj, err := model.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if isOk {
+			err = transactionObj.Commit()
+			if err != nil {
+				model.logger.Error("error is transaction commit during SetAccess", zap.Error(err))
+			}
+		} else {
+			err = transactionObj.Rollback()
+			if err != nil {
+				model.logger.Error("error is transaction rollback during SetAccess", zap.Error(err))
+			}
+		}
+	}()
+
+	_, err = transactionObj.Update(ActiveQuizzesTable).Set(goqu.Record{
+		"access_mode":   accessMode,
+		"passcode_hash": passcodeHash,
+		"updated_at":    goqu.L("now()"),
+	}).Where(goqu.I("id").Eq(id)).Executor().Exec()
+	if err != nil {
+		return err
+	}
+
+	_, err = transactionObj.Delete(constants.SessionAllowlistTable).Where(goqu.I("active_quiz_id").Eq(id)).Executor().Exec()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) > 0 {
+		rows := make([]goqu.Record, 0, len(entries))
+		for _, entry := range entries {
+			entryId, err := uuid.NewUUID()
+			if err != nil {
+				return err
+			}
+			rows = append(rows, goqu.Record{"id": entryId, "active_quiz_id": id, "entry": entry})
+		}
+
+		_, err = transactionObj.Insert(constants.SessionAllowlistTable).Rows(rows).OnConflict(goqu.DoNothing()).Executor().Exec()
+		if err != nil {
+			return err
+		}
+	}
+
+	isOk = true
+	return nil
+}
+
+// GetAllowlist returns the emails and email domains let into a session
+func (model *ActiveQuizModel) GetAllowlist(id uuid.UUID) ([]string, error) {
+	entries := []string{}
+	err := model.db.From(constants.SessionAllowlistTable).Select("entry").Where(goqu.I("active_quiz_id").Eq(id)).Order(goqu.I("entry").Asc()).ScanVals(&entries)
+	return entries, err
+}
@@ -0,0 +1,366 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const SessionTeamsTable = "session_teams"
+
+// SessionTeam model
+type SessionTeam struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	ActiveQuizID uuid.UUID `json:"active_quiz_id" db:"active_quiz_id"`
+	Name         string    `json:"name" db:"name"`
+	CreatedAt    time.Time `json:"created_at,omitempty" db:"created_at,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at,omitempty" db:"updated_at,omitempty"`
+}
+
+// TeamSettings is how the players of a session are split and how their team is scored
+type TeamSettings struct {
+	Mode    string
+	Scoring string
+	BestN   int
+}
+
+// TeamRoster is a team of a session with the players in it
+type TeamRoster struct {
+	ID      uuid.UUID    `json:"id"`
+	Name    string       `json:"name"`
+	Members []JoinedUser `json:"members"`
+}
+
+// TeamRank is the standing of a team, its score is aggregated from the calculated_score of its members
+type TeamRank struct {
+	Rank    int       `json:"rank" db:"rank"`
+	TeamID  uuid.UUID `json:"team_id" db:"id"`
+	Name    string    `json:"name" db:"name"`
+	Members int       `json:"members" db:"members"`
+	Score   int       `json:"score" db:"score"`
+}
+
+// SessionTeamModel implements team related database operations
+type SessionTeamModel struct {
+	db     *goqu.Database
+	logger *zap.Logger
+}
+
+// InitSessionTeamModel initializes the SessionTeamModel
+func InitSessionTeamModel(goqu *goqu.Database, logger *zap.Logger) *SessionTeamModel {
+	return &SessionTeamModel{db: goqu, logger: logger}
+}
+
+// ConfigureTeams replaces the teams of a session, players that already joined are balanced across them in auto mode
+func (model *SessionTeamModel) ConfigureTeams(sessionId uuid.UUID, settings TeamSettings, names []string) error {
+	var isOk bool = false
+
+	transactionObj, err := model.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if isOk {
+			err = transactionObj.Commit()
+			if err != nil {
+				model.logger.Error("error is transaction commit during ConfigureTeams", zap.Error(err))
+			}
+		} else {
+			err = transactionObj.Rollback()
+			if err != nil {
+				model.logger.Error("error is transaction rollback during ConfigureTeams", zap.Error(err))
+			}
+		}
+	}()
+
+	bestN := sql.NullInt32{Int32: int32(settings.BestN), Valid: settings.Scoring == constants.TeamScoringBestN}
+
+	result, err := transactionObj.Update(ActiveQuizzesTable).Set(goqu.Record{
+		"team_mode":    settings.Mode,
+		"team_scoring": settings.Scoring,
+		"team_best_n":  bestN,
+		"updated_at":   goqu.L("now()"),
+	}).Where(goqu.I("id").Eq(sessionId)).Executor().Exec()
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = transactionObj.Delete(SessionTeamsTable).Where(goqu.I("active_quiz_id").Eq(sessionId)).Executor().Exec()
+	if err != nil {
+		return err
+	}
+
+	teams := []goqu.Record{}
+	for _, name := range names {
+		id, err := uuid.NewUUID()
+		if err != nil {
+			return err
+		}
+		teams = append(teams, goqu.Record{"id": id, "active_quiz_id": sessionId, "name": name})
+	}
+
+	_, err = transactionObj.Insert(SessionTeamsTable).Rows(teams).Executor().Exec()
+	if err != nil {
+		return err
+	}
+
+	if settings.Mode == constants.TeamModeAuto {
+		if _, err := balanceUnassigned(transactionObj, sessionId); err != nil {
+			return err
+		}
+	}
+
+	isOk = true
+	return nil
+}
+
+// DisableTeams turns teams off for a session, players are released from their team
+func (model *SessionTeamModel) DisableTeams(sessionId uuid.UUID) error {
+	_, err := model.db.Update(ActiveQuizzesTable).Set(goqu.Record{
+		"team_mode":   nil,
+		"team_best_n": nil,
+		"updated_at":  goqu.L("now()"),
+	}).Where(goqu.I("id").Eq(sessionId)).Executor().Exec()
+	if err != nil {
+		return err
+	}
+
+	_, err = model.db.Delete(SessionTeamsTable).Where(goqu.I("active_quiz_id").Eq(sessionId)).Executor().Exec()
+	return err
+}
+
+// ListTeams returns the teams of a session with their members, in the order they were created
+func (model *SessionTeamModel) ListTeams(sessionId uuid.UUID) ([]TeamRoster, error) {
+	teams := []SessionTeam{}
+
+	err := model.db.From(SessionTeamsTable).Where(goqu.I("active_quiz_id").Eq(sessionId)).
+		Order(goqu.I("created_at").Asc(), goqu.I("name").Asc()).
+		ScanStructs(&teams)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []struct {
+		TeamID uuid.UUID `db:"team_id"`
+		JoinedUser
+	}{}
+
+	err = model.db.Select("upq.team_id", "u.id", goqu.COALESCE(goqu.I("upq.display_name"), goqu.I("u.first_name")).As("first_name"), "u.img_key").
+		From(goqu.T(UserPlayedQuizTable).As("upq")).
+		Join(goqu.T(UserTable).As("u"), goqu.On(goqu.I("u.id").Eq(goqu.I("upq.user_id")))).
+		Where(goqu.I("upq.active_quiz_id").Eq(sessionId), goqu.I("upq.team_id").IsNotNull()).
+		Order(goqu.I("upq.created_at").Asc()).
+		ScanStructs(&members)
+	if err != nil {
+		return nil, err
+	}
+
+	rosters := make([]TeamRoster, 0, len(teams))
+	index := map[uuid.UUID]int{}
+	for i, team := range teams {
+		rosters = append(rosters, TeamRoster{ID: team.ID, Name: team.Name, Members: []JoinedUser{}})
+		index[team.ID] = i
+	}
+
+	for _, member := range members {
+		if i, ok := index[member.TeamID]; ok {
+			rosters[i].Members = append(rosters[i].Members, member.JoinedUser)
+		}
+	}
+
+	return rosters, nil
+}
+
+// JoinTeam moves a player of the session into teamId
+func (model *SessionTeamModel) JoinTeam(sessionId uuid.UUID, userId string, teamId uuid.UUID) error {
+	result, err := model.db.Update(UserPlayedQuizTable).Set(goqu.Record{
+		"team_id":    teamId,
+		"updated_at": goqu.L("now()"),
+	}).Where(
+		goqu.I("user_id").Eq(userId),
+		goqu.I("active_quiz_id").Eq(sessionId),
+		goqu.L("exists (select 1 from session_teams where id = ? and active_quiz_id = ?)", teamId, sessionId),
+	).Executor().Exec()
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf(constants.ErrTeamNotFound)
+	}
+
+	return nil
+}
+
+// AssignToSmallestTeam puts a player without a team into the team with the fewest members, it
+// reports false when the player already had a team
+func (model *SessionTeamModel) AssignToSmallestTeam(sessionId uuid.UUID, userId string) (bool, error) {
+	result, err := model.db.Exec(assignToSmallestTeamQuery, sessionId, userId)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// BalanceUnassigned spreads the players who did not pick a team across the smallest teams and
+// returns how many were placed
+func (model *SessionTeamModel) BalanceUnassigned(sessionId uuid.UUID) (int, error) {
+	var isOk bool = false
+
+	transactionObj, err := model.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if isOk {
+			err = transactionObj.Commit()
+			if err != nil {
+				model.logger.Error("error is transaction commit during BalanceUnassigned", zap.Error(err))
+			}
+		} else {
+			err = transactionObj.Rollback()
+			if err != nil {
+				model.logger.Error("error is transaction rollback during BalanceUnassigned", zap.Error(err))
+			}
+		}
+	}()
+
+	placed, err := balanceUnassigned(transactionObj, sessionId)
+	if err != nil {
+		return 0, err
+	}
+
+	isOk = true
+	return placed, nil
+}
+
+// GetTeamRank ranks the teams of a session with the scoring chosen by the host
+func (model *SessionTeamModel) GetTeamRank(sessionId uuid.UUID) ([]TeamRank, error) {
+	ranks := []TeamRank{}
+
+	err := model.db.ScanStructs(&ranks, `
+	with member_scores as (
+		select upq.team_id, coalesce(sum(uqr.calculated_score), 0) as score
+		from user_played_quizzes upq
+		left join user_quiz_responses uqr on uqr.user_played_quiz_id = upq.id
+		where upq.active_quiz_id = $1 and upq.team_id is not null and upq.is_banned = false
+		group by upq.team_id, upq.id
+	), ranked_members as (
+		select team_id, score, row_number() over (partition by team_id order by score desc) as position
+		from member_scores
+	), team_scores as (
+		select
+			t.id,
+			t.name,
+			t.created_at,
+			count(rm.team_id) as members,
+			coalesce(case aq.team_scoring
+				when 'average' then round(avg(rm.score))
+				when 'best_n' then sum(rm.score) filter (where rm.position <= coalesce(aq.team_best_n, 1))
+				else sum(rm.score)
+			end, 0)::integer as score
+		from session_teams t
+		join active_quizzes aq on aq.id = t.active_quiz_id
+		left join ranked_members rm on rm.team_id = t.id
+		where t.active_quiz_id = $1
+		group by t.id, t.name, t.created_at, aq.team_scoring, aq.team_best_n
+	)
+	select dense_rank() over (order by score desc) as rank, id, name, members, score
+	from team_scores
+	order by rank, created_at`, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return ranks, nil
+}
+
+// GetTeamRankOfPlayedQuiz ranks the teams of the session a participation belongs to
+func (model *SessionTeamModel) GetTeamRankOfPlayedQuiz(userPlayedQuizId uuid.UUID) ([]TeamRank, error) {
+	var activeQuizId uuid.UUID
+
+	found, err := model.db.Select("active_quiz_id").From(UserPlayedQuizTable).Where(goqu.I("id").Eq(userPlayedQuizId)).ScanVal(&activeQuizId)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+
+	return model.GetTeamRank(activeQuizId)
+}
+
+// the team with the fewest members wins, ties go to the team created first
+const assignToSmallestTeamQuery = `
+	update user_played_quizzes
+	set
+		team_id = (
+			select t.id
+			from session_teams t
+			left join user_played_quizzes member on member.team_id = t.id
+			where t.active_quiz_id = $1
+			group by t.id, t.created_at
+			order by count(member.id), t.created_at
+			limit 1
+		),
+		updated_at = now()
+	where
+		active_quiz_id = $1 and
+		user_id = $2 and
+		team_id is null
+`
+
+func balanceUnassigned(transactionObj *goqu.TxDatabase, sessionId uuid.UUID) (int, error) {
+	teams, err := transactionObj.From(SessionTeamsTable).Where(goqu.I("active_quiz_id").Eq(sessionId)).Count()
+	if err != nil {
+		return 0, err
+	}
+
+	if teams == 0 {
+		return 0, nil
+	}
+
+	userIds := []string{}
+
+	err = transactionObj.From(UserPlayedQuizTable).Select("user_id").Where(
+		goqu.I("active_quiz_id").Eq(sessionId),
+		goqu.I("team_id").IsNull(),
+		goqu.I("is_host").IsFalse(),
+	).Order(goqu.I("created_at").Asc()).ScanVals(&userIds)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, userId := range userIds {
+		if _, err := transactionObj.Exec(assignToSmallestTeamQuery, sessionId, userId); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(userIds), nil
+}
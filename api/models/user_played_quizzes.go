@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
 	"github.com/Improwised/jovvix/api/pkg/structs"
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/google/uuid"
 )
 
@@ -172,6 +174,32 @@ func (model *UserPlayedQuizModel) GetActiveSession(id string, invitationCode str
 	return activeQuiz, nil
 }
 
+// GetPlayedSession returns the session of a participation owned by userID, whether it is still active or not
+func (model *UserPlayedQuizModel) GetPlayedSession(id uuid.UUID, userID string) (ActiveQuiz, error) {
+	var activeQuiz ActiveQuiz
+
+	found, err := model.db.From(goqu.T(UserPlayedQuizTable).As("upq")).Join(
+		goqu.I(ActiveQuizzesTable).As("aq"),
+		goqu.On(goqu.I("upq.active_quiz_id").Eq(goqu.I("aq.id"))),
+	).Select(
+		goqu.I("aq.*"),
+	).Where(
+		goqu.Ex{
+			"upq.id":      id,
+			"upq.user_id": userID,
+		},
+	).Limit(1).ScanStruct(&activeQuiz)
+
+	if err != nil {
+		return activeQuiz, err
+	}
+	if !found {
+		return activeQuiz, sql.ErrNoRows
+	}
+
+	return activeQuiz, nil
+}
+
 func (model *UserPlayedQuizModel) GetCurrentActiveQuestion(id string) (uuid.UUID, error) {
 	var currentQuestion uuid.UUID
 	found, err := model.db.Select("current_question").From(ActiveQuizzesTable).Where(goqu.Ex{"is_question_active": true, "id": id}).ScanVal(&currentQuestion)
@@ -187,6 +215,29 @@ func (model *UserPlayedQuizModel) GetCurrentActiveQuestion(id string) (uuid.UUID
 	return currentQuestion, nil
 }
 
+// ActiveQuestion is the question of a session that currently receives answers
+type ActiveQuestion struct {
+	ID             uuid.UUID    `db:"current_question"`
+	DeliveredAt    sql.NullTime `db:"question_delivery_time"`
+	ShuffleOptions bool         `db:"shuffle_options"`
+}
+
+// GetActiveQuestion returns the question of the session that receives answers with the time it was delivered
+func (model *UserPlayedQuizModel) GetActiveQuestion(id string) (ActiveQuestion, error) {
+	question := ActiveQuestion{}
+
+	found, err := model.db.Select("current_question", "question_delivery_time", "shuffle_options").From(ActiveQuizzesTable).Where(goqu.Ex{"is_question_active": true, "id": id}).ScanStruct(&question)
+	if err != nil {
+		return question, err
+	}
+
+	if !found {
+		return question, sql.ErrNoRows
+	}
+
+	return question, nil
+}
+
 type UserRank struct {
 	Rank         int    `json:"rank" db:"rank"`
 	Points       int    `json:"points" db:"points"`
@@ -196,70 +247,71 @@ type UserRank struct {
 	FirstName    string `json:"firstname" db:"first_name"`
 	ImageKey     string `json:"img_key" db:"img_key"`
 	StreakCount  int    `json:"streak_count" db:"streak_count"`
+	RankDelta    int    `json:"rank_delta" db:"-"`
 }
 
-func (model *UserPlayedQuizModel) GetRank(sessionId uuid.UUID, questionId uuid.UUID) ([]UserRank, error) {
-
-	mainQuery := model.db.
-		From(goqu.T("get_question_info").As("gqi")).
-		Join(goqu.T("get_sum").As("gs"), goqu.On(goqu.I("gs.user_id").Eq(goqu.I("gqi.user_id")))).
-		Join(goqu.T(UserTable).As("u"), goqu.On(goqu.I("gs.user_id").Eq(goqu.I("u.id"))))
-
-	// Define the common table expressions (CTEs)
-	core := mainQuery.
-		With("core", goqu.
-			Select("uqr.calculated_score", "uqr.calculated_points", "uqr.question_id", "uqr.response_time", "uqr.is_attend", "upq.user_id", "uqr.streak_count").
-			From(goqu.T(UserPlayedQuizTable).As("upq")).
-			Join(goqu.T("user_quiz_responses").As("uqr"), goqu.On(goqu.Ex{
-				"upq.id":             goqu.I("uqr.user_played_quiz_id"),
-				"upq.active_quiz_id": sessionId,
-			}),
-			))
-
-	getSum := core.
-		With("get_sum", goqu.
-			Select("user_id", goqu.SUM("calculated_score").As("calculated_total_score"), goqu.SUM("calculated_points").As("total_points")).
-			From("core").
-			GroupBy("user_id"),
-		)
-	getQuestionInfo := getSum.
-		With("get_question_info", goqu.
-			Select("is_attend", "user_id", "response_time", "streak_count").
-			From("core").
-			Where(goqu.Ex{
-				"question_id": questionId,
-			}),
-		)
-	final_query := getQuestionInfo.Select(
-		goqu.DENSE_RANK().Over(goqu.W().OrderBy(goqu.I("gs.calculated_total_score").Desc())).As("rank"),
-		goqu.I("gs.calculated_total_score"),
-		goqu.I("gs.total_points"),
-		goqu.I("gqi.response_time"),
-		goqu.I("u.username"),
-		goqu.I("u.first_name"),
-		goqu.I("u.img_key"),
-		goqu.I("gqi.streak_count"),
-	)
+// QuestionStanding is how a player did on one question, the totals of the session are kept on the leaderboard
+type QuestionStanding struct {
+	UserID        string `db:"user_id"`
+	UserName      string `db:"username"`
+	FirstName     string `db:"first_name"`
+	ImageKey      string `db:"img_key"`
+	ResponseTime  int    `db:"response_time"`
+	StreakCount   int    `db:"streak_count"`
+	QuestionScore int    `db:"calculated_score"`
+}
 
-	// Define the main query to filter by rank
-	rows, err := final_query.Executor().Query()
+// GetQuestionStandings returns the players of the session who are not banned, with their response to the question
+func (model *UserPlayedQuizModel) GetQuestionStandings(sessionId uuid.UUID, questionId uuid.UUID) ([]QuestionStanding, error) {
+	standings := []QuestionStanding{}
 
-	if err != nil {
-		return []UserRank{}, err
-	}
-	defer rows.Close()
+	err := model.db.
+		Select(
+			goqu.I("upq.user_id"),
+			goqu.I("u.username"),
+			goqu.COALESCE(goqu.I("upq.display_name"), goqu.I("u.first_name")).As("first_name"),
+			goqu.I("u.img_key"),
+			goqu.I("uqr.response_time"),
+			goqu.I("uqr.streak_count"),
+			goqu.I("uqr.calculated_score"),
+		).
+		From(goqu.T(UserPlayedQuizTable).As("upq")).
+		Join(goqu.T(UserQuizResponsesTable).As("uqr"), goqu.On(goqu.I("uqr.user_played_quiz_id").Eq(goqu.I("upq.id")))).
+		Join(goqu.T(UserTable).As("u"), goqu.On(goqu.I("u.id").Eq(goqu.I("upq.user_id")))).
+		Where(goqu.Ex{
+			"upq.active_quiz_id": sessionId,
+			"upq.is_banned":      false,
+			"uqr.question_id":    questionId,
+		}).
+		ScanStructs(&standings)
 
-	userRanks := []UserRank{}
-	for rows.Next() {
-		var userRank UserRank
-		err := rows.Scan(&userRank.Rank, &userRank.Score, &userRank.Points, &userRank.ResponseTime, &userRank.UserName, &userRank.FirstName, &userRank.ImageKey, &userRank.StreakCount)
-		if err != nil {
-			return userRanks, err
-		}
-		userRanks = append(userRanks, userRank)
+	return standings, err
+}
 
-	}
-	return userRanks, nil
+// SessionTotal is the score and points of a player over the questions of a session so far
+type SessionTotal struct {
+	UserID string `db:"user_id"`
+	Score  int64  `db:"score"`
+	Points int64  `db:"points"`
+}
+
+// GetSessionTotals sums the responses of every player of the session, the leaderboard is rebuilt and checked with it
+func (model *UserPlayedQuizModel) GetSessionTotals(sessionId uuid.UUID) ([]SessionTotal, error) {
+	totals := []SessionTotal{}
+
+	err := model.db.
+		Select(
+			goqu.I("upq.user_id"),
+			goqu.COALESCE(goqu.SUM("uqr.calculated_score"), 0).As("score"),
+			goqu.COALESCE(goqu.SUM("uqr.calculated_points"), 0).As("points"),
+		).
+		From(goqu.T(UserPlayedQuizTable).As("upq")).
+		Join(goqu.T(UserQuizResponsesTable).As("uqr"), goqu.On(goqu.I("uqr.user_played_quiz_id").Eq(goqu.I("upq.id")))).
+		Where(goqu.Ex{"upq.active_quiz_id": sessionId}).
+		GroupBy(goqu.I("upq.user_id")).
+		ScanStructs(&totals)
+
+	return totals, err
 }
 
 func (model *UserPlayedQuizModel) ListUserPlayedQuizes(userId string, page int, titleSearch string) ([]structs.ResUserPlayedQuiz, int64, error) {
@@ -365,10 +417,10 @@ type JoinedUser struct {
 func (model *UserPlayedQuizModel) GetJoinedUsers(activeQuizId string) ([]JoinedUser, error) {
 	users := []JoinedUser{}
 
-	err := model.db.Select("u.id", "u.first_name", "u.img_key").
+	err := model.db.Select("u.id", goqu.COALESCE(goqu.I("upq.display_name"), goqu.I("u.first_name")).As("first_name"), "u.img_key").
 		From(goqu.T(UserPlayedQuizTable).As("upq")).
 		Join(goqu.T(UserTable).As("u"), goqu.On(goqu.I("u.id").Eq(goqu.I("upq.user_id")))).
-		Where(goqu.I("upq.active_quiz_id").Eq(activeQuizId)).
+		Where(goqu.I("upq.active_quiz_id").Eq(activeQuizId), goqu.I("upq.is_banned").IsFalse()).
 		Order(goqu.I("upq.created_at").Asc()).
 		ScanStructs(&users)
 
@@ -379,12 +431,274 @@ func (model *UserPlayedQuizModel) GetJoinedUsers(activeQuizId string) ([]JoinedU
 	return users, nil
 }
 
+// GetUserPlayedQuizId returns the participation of userId in a session
+func (model *UserPlayedQuizModel) GetUserPlayedQuizId(userId string, activeQuizId uuid.UUID) (uuid.UUID, error) {
+	var userPlayedQuizId uuid.UUID
+
+	found, err := model.db.From(UserPlayedQuizTable).Select("id").Where(goqu.Ex{
+		"user_id":        userId,
+		"active_quiz_id": activeQuizId,
+	}).ScanVal(&userPlayedQuizId)
+
+	if err != nil {
+		return model.defaultUUID, err
+	}
+
+	if !found {
+		return model.defaultUUID, sql.ErrNoRows
+	}
+
+	return userPlayedQuizId, nil
+}
+
 func (model *UserPlayedQuizModel) GetCountOfTotalJoinUsers(activeQuizId string) (int64, error) {
 	return model.db.From(UserPlayedQuizTable).Where(goqu.Ex{
 		"active_quiz_id": activeQuizId,
+		"is_banned":      false,
 	}).Count()
 }
 
+// Participant is how a player takes part in a session after the host moderated them
+type Participant struct {
+	ID          uuid.UUID      `db:"id"`
+	IsBanned    bool           `db:"is_banned"`
+	DisplayName sql.NullString `db:"display_name"`
+	AdmittedAt  sql.NullTime   `db:"admitted_at"`
+}
+
+// GetParticipant returns the participation of userId in a session, sql.ErrNoRows when they never joined
+func (model *UserPlayedQuizModel) GetParticipant(userId string, activeQuizId uuid.UUID) (Participant, error) {
+	participant := Participant{}
+
+	found, err := model.db.From(UserPlayedQuizTable).Select("id", "is_banned", "display_name", "admitted_at").Where(goqu.Ex{
+		"user_id":        userId,
+		"active_quiz_id": activeQuizId,
+	}).ScanStruct(&participant)
+	if err != nil {
+		return participant, err
+	}
+
+	if !found {
+		return participant, sql.ErrNoRows
+	}
+
+	return participant, nil
+}
+
+// AdmitPlayer lets the participation of userId into a session on the terms the host set. When the session
+// has a maximum of players or already started, its row stays locked until it is done so two players can
+// not take the last seat together. A player who is turned away loses the participation, they may try
+// again once the host changed their mind. It returns the score a late joiner was credited with for the
+// questions they missed.
+func (model *UserPlayedQuizModel) AdmitPlayer(userId string, activeQuizId uuid.UUID) (int64, error) {
+	transaction, err := model.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	isCommitted := false
+	defer func() {
+		if !isCommitted {
+			_ = transaction.Rollback()
+		}
+	}()
+
+	session := ActiveQuiz{}
+	found, err := transaction.From(ActiveQuizzesTable).Select("*").Where(goqu.I("id").Eq(activeQuizId)).ScanStruct(&session)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf(constants.ErrSessionNotFound)
+	}
+
+	participant := Participant{}
+	found, err = transaction.From(UserPlayedQuizTable).Select("id", "is_banned", "display_name", "admitted_at").Where(goqu.Ex{
+		"user_id":        userId,
+		"active_quiz_id": activeQuizId,
+	}).ScanStruct(&participant)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, sql.ErrNoRows
+	}
+
+	// players who were let in before come back whatever the terms are now
+	if participant.AdmittedAt.Valid {
+		return 0, nil
+	}
+
+	// the other joins only wait on this one when the seats or the questions played so far matter
+	if session.MaxPlayers.Valid || session.IsStarted() {
+		found, err = transaction.From(ActiveQuizzesTable).Select("*").Where(goqu.I("id").Eq(activeQuizId)).ForUpdate(exp.Wait).ScanStruct(&session)
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			return 0, fmt.Errorf(constants.ErrSessionNotFound)
+		}
+	}
+
+	reason, err := rejection(transaction, session)
+	if err != nil {
+		return 0, err
+	}
+
+	if reason != "" {
+		if _, err := transaction.Exec(removePlayerQuery, userId, activeQuizId); err != nil {
+			return 0, err
+		}
+		isCommitted = true
+		if err := transaction.Commit(); err != nil {
+			return 0, err
+		}
+		return 0, errors.New(reason)
+	}
+
+	_, err = transaction.Update(UserPlayedQuizTable).Set(goqu.Record{
+		"admitted_at": goqu.L("now()"),
+		"updated_at":  goqu.L("now()"),
+	}).Where(goqu.I("id").Eq(participant.ID)).Executor().Exec()
+	if err != nil {
+		return 0, err
+	}
+
+	var credit int64
+	if session.IsStarted() && session.LateJoinCredit == constants.LateJoinCreditAverage {
+		if _, err := transaction.ScanVal(&credit, creditMissedQuestionsQuery, participant.ID, activeQuizId); err != nil {
+			return 0, err
+		}
+	}
+
+	isCommitted = true
+	return credit, transaction.Commit()
+}
+
+// rejection returns why a newcomer is turned away from session, empty when they may join
+func rejection(transaction *goqu.TxDatabase, session ActiveQuiz) (string, error) {
+	if session.IsLobbyLocked {
+		return constants.ErrLobbyLocked, nil
+	}
+
+	if session.IsStarted() && !session.AllowLateJoin {
+		return constants.ErrLateJoinClosed, nil
+	}
+
+	if session.MaxPlayers.Valid {
+		players, err := transaction.From(UserPlayedQuizTable).Where(
+			goqu.I("active_quiz_id").Eq(session.ID),
+			goqu.I("is_host").IsFalse(),
+			goqu.I("is_banned").IsFalse(),
+			goqu.I("admitted_at").IsNotNull(),
+		).Count()
+		if err != nil {
+			return "", err
+		}
+		if players >= int64(session.MaxPlayers.Int32) {
+			return constants.ErrLobbyFull, nil
+		}
+	}
+
+	return "", nil
+}
+
+// a late joiner is credited with the average score of the other players on the questions they missed,
+// the current question is missed once it is closed. It returns the score credited in all.
+const creditMissedQuestionsQuery = `
+	with current_question as (
+		select aqq.order_no, coalesce(aq.is_question_active, false) as is_question_active
+		from active_quizzes aq
+		join active_quiz_questions aqq on aqq.active_quiz_id = aq.id and aqq.question_id = aq.current_question
+		where aq.id = $2
+	), missed_questions as (
+		select aqq.question_id
+		from active_quiz_questions aqq, current_question cq
+		where aqq.active_quiz_id = $2
+		and (aqq.order_no < cq.order_no or (aqq.order_no = cq.order_no and not cq.is_question_active))
+	), credited as (
+		update user_quiz_responses uqr
+		set calculated_score = coalesce((
+			select round(avg(other.calculated_score))::int
+			from user_quiz_responses other
+			join user_played_quizzes upq on upq.id = other.user_played_quiz_id
+			where other.question_id = uqr.question_id
+			and upq.active_quiz_id = $2
+			and upq.id <> $1
+			and upq.is_host = false
+			and upq.is_banned = false
+			and upq.admitted_at is not null
+		), 0), updated_at = now()
+		where uqr.user_played_quiz_id = $1
+		and uqr.answers is null
+		and uqr.question_id in (select question_id from missed_questions)
+		returning uqr.calculated_score
+	)
+	select coalesce(sum(calculated_score), 0) from credited
+`
+
+// RemovePlayer drops a player from a session, the answers they gave are deleted with it and leave
+// the scoreboards and reports for good, they may join again
+func (model *UserPlayedQuizModel) RemovePlayer(userId string, activeQuizId uuid.UUID) error {
+	result, err := model.db.Exec(removePlayerQuery, userId, activeQuizId)
+	if err != nil {
+		return err
+	}
+
+	return playerAffected(result)
+}
+
+// both deletes run in one statement, the foreign key of the responses is checked once it completes
+const removePlayerQuery = `
+	with removed_responses as (
+		delete from user_quiz_responses
+		where user_played_quiz_id in (
+			select id from user_played_quizzes where user_id = $1 and active_quiz_id = $2 and is_host = false
+		)
+	)
+	delete from user_played_quizzes
+	where user_id = $1 and active_quiz_id = $2 and is_host = false
+`
+
+// BanPlayer keeps a player out of a session, their participation stays so the ban outlives their socket
+func (model *UserPlayedQuizModel) BanPlayer(userId string, activeQuizId uuid.UUID) error {
+	result, err := model.db.Update(UserPlayedQuizTable).Set(goqu.Record{
+		"is_banned":  true,
+		"team_id":    nil,
+		"updated_at": goqu.L("now()"),
+	}).Where(goqu.Ex{"user_id": userId, "active_quiz_id": activeQuizId, "is_host": false}).Executor().Exec()
+	if err != nil {
+		return err
+	}
+
+	return playerAffected(result)
+}
+
+// RenamePlayer changes the name a player is shown under in a session, their account keeps its name
+func (model *UserPlayedQuizModel) RenamePlayer(userId string, activeQuizId uuid.UUID, displayName string) error {
+	result, err := model.db.Update(UserPlayedQuizTable).Set(goqu.Record{
+		"display_name": displayName,
+		"updated_at":   goqu.L("now()"),
+	}).Where(goqu.Ex{"user_id": userId, "active_quiz_id": activeQuizId, "is_banned": false}).Executor().Exec()
+	if err != nil {
+		return err
+	}
+
+	return playerAffected(result)
+}
+
+func playerAffected(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf(constants.ErrPlayerNotFound)
+	}
+
+	return nil
+}
+
 // Deletes all user-played quizzes and associated responses for a specific user (userId)
 func (model *UserPlayedQuizModel) DeleteUserPlayedQuizzesAndReponseByUserId(transaction *goqu.TxDatabase, userId string) error {
 
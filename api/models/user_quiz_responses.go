@@ -28,8 +28,11 @@ type UserQuizResponse struct {
 }
 
 type UsersQustionResponse struct {
-	UserId  string         `json:"id" db:"user_id"`
-	Answers sql.NullString `json:"answers" db:"answers"`
+	UserId      string          `json:"id" db:"user_id"`
+	Answers     sql.NullString  `json:"answers" db:"answers"`
+	AnswerText  sql.NullString  `json:"answer_text" db:"answer_text"`
+	AnswerValue sql.NullFloat64 `json:"answer_value" db:"answer_value"`
+	AnswerPairs sql.NullString  `json:"answer_pairs" db:"answer_pairs"`
 }
 
 // QuestionModel implements question related database operations
@@ -90,23 +93,49 @@ func (model *UserQuizResponseModel) GetQuestionsCopy(userPlayedQuizId uuid.UUID,
 	return nil
 }
 
-func (model *UserQuizResponseModel) SubmitAnswer(userPlayedQuizId uuid.UUID, answerStruct structs.ReqAnswerSubmit, points sql.NullInt16, score, streakCount int) error {
+// AnswerTiming is the response time reported by the player, kept next to the one the answer is scored with
+type AnswerTiming struct {
+	ClientResponseTime sql.NullInt32
+	IsTimeAnomaly      bool
+}
+
+func (model *UserQuizResponseModel) SubmitAnswer(userPlayedQuizId uuid.UUID, answerStruct structs.ReqAnswerSubmit, points sql.NullInt16, score, streakCount int, timing AnswerTiming) error {
+
+	// a typed or numeric answer comes without keys, an empty list still marks the question as answered
+	keys := answerStruct.AnswerKeys
+	if keys == nil {
+		keys = []int{}
+	}
 
-	answerArray, err := json.Marshal(answerStruct.AnswerKeys)
+	answerArray, err := json.Marshal(keys)
 
 	if err != nil {
 		return err
 	}
 
+	answerPairs := sql.NullString{}
+	if len(answerStruct.AnswerPairs) > 0 {
+		pairs, err := json.Marshal(answerStruct.AnswerPairs)
+		if err != nil {
+			return err
+		}
+		answerPairs = sql.NullString{String: string(pairs), Valid: true}
+	}
+
 	result, err := model.db.Update(UserQuizResponsesTable).Set(
 		goqu.Record{
-			"answers":           string(answerArray),
-			"calculated_points": points,
-			"is_attend":         points.Valid,
-			"response_time":     answerStruct.ResponseTime,
-			"calculated_score":  score,
-			"streak_count":      streakCount,
-			"updated_at":        goqu.L("now()"),
+			"answers":              string(answerArray),
+			"answer_text":          sql.NullString{String: answerStruct.AnswerText, Valid: answerStruct.AnswerText != ""},
+			"answer_value":         answerValue(answerStruct.AnswerValue),
+			"answer_pairs":         answerPairs,
+			"calculated_points":    points,
+			"is_attend":            points.Valid,
+			"response_time":        answerStruct.ResponseTime,
+			"client_response_time": timing.ClientResponseTime,
+			"is_time_anomaly":      timing.IsTimeAnomaly,
+			"calculated_score":     score,
+			"streak_count":         streakCount,
+			"updated_at":           goqu.L("now()"),
 		},
 	).Where(
 		goqu.I("user_played_quiz_id").Eq(userPlayedQuizId),
@@ -131,11 +160,19 @@ func (model *UserQuizResponseModel) SubmitAnswer(userPlayedQuizId uuid.UUID, ans
 	return nil
 }
 
+// answerValue stores a missing value as null
+func answerValue(value *float64) sql.NullFloat64 {
+	if value == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *value, Valid: true}
+}
+
 func (model *UserQuizResponseModel) GetUsersResponses(sessionId uuid.UUID, questionId uuid.UUID) ([]UsersQustionResponse, error) {
 
 	var userQuestionResponses []UsersQustionResponse
 	query := model.db.From(goqu.T(constants.UserQuizResponsesTable).As("uqr")).
-		Select("upq.user_id", "uqr.answers").
+		Select("upq.user_id", "uqr.answers", "uqr.answer_text", "uqr.answer_value", "uqr.answer_pairs").
 		Join(
 			goqu.T(constants.UserPlayedQuizzesTable).As("upq"),
 			goqu.On(goqu.Ex{
@@ -153,3 +190,184 @@ func (model *UserQuizResponseModel) GetUsersResponses(sessionId uuid.UUID, quest
 
 	return userQuestionResponses, err
 }
+
+// AnswerCount is how many of the players a question was served to already answered it
+type AnswerCount struct {
+	QuestionID uuid.UUID `json:"question_id" db:"question_id"`
+	Answered   int       `json:"answered" db:"answered"`
+	Total      int       `json:"total" db:"total"`
+}
+
+// GetAnswerCount counts the answers submitted to questionId in a session
+func (model *UserQuizResponseModel) GetAnswerCount(sessionId uuid.UUID, questionId uuid.UUID) (AnswerCount, error) {
+	count := AnswerCount{}
+
+	_, err := model.db.From(goqu.T(constants.UserQuizResponsesTable).As("uqr")).
+		Select(
+			goqu.COUNT(goqu.I("uqr.answers")).As("answered"),
+			goqu.COUNT(goqu.I("uqr.id")).As("total"),
+		).
+		Join(
+			goqu.T(constants.UserPlayedQuizzesTable).As("upq"),
+			goqu.On(goqu.Ex{
+				"uqr.user_played_quiz_id": goqu.I("upq.id"),
+			}),
+		).
+		Where(
+			goqu.Ex{
+				"uqr.question_id":    questionId,
+				"upq.active_quiz_id": sessionId,
+			},
+		).ScanStruct(&count)
+
+	count.QuestionID = questionId
+	return count, err
+}
+
+// PlayerProgress is the running result of a player, used to restore the screen of a reconnecting player
+type PlayerProgress struct {
+	TotalScore      int             `json:"total_score"`
+	StreakCount     int             `json:"streak_count"`
+	Answers         sql.NullString  `json:"answers"`
+	AnswerText      sql.NullString  `json:"answer_text"`
+	AnswerValue     sql.NullFloat64 `json:"answer_value"`
+	AnswerPairs     sql.NullString  `json:"answer_pairs"`
+	QuestionPoints  sql.NullInt32   `json:"question_points"`
+	QuestionScore   sql.NullInt32   `json:"question_score"`
+	IsAnswerPresent bool            `json:"is_answer_present"`
+}
+
+// GetPlayerProgress returns the total score and streak of a player together with their response to questionId
+func (model *UserQuizResponseModel) GetPlayerProgress(userPlayedQuizId uuid.UUID, questionId uuid.UUID) (PlayerProgress, error) {
+	progress := PlayerProgress{}
+
+	statement, err := model.db.Prepare(`
+	select
+		coalesce(sum(uqr.calculated_score) filter (where uqr.answers is not null), 0) as total_score,
+		coalesce((
+			select streak_count from user_quiz_responses
+			where user_played_quiz_id = $1 and answers is not null
+			order by updated_at desc
+			limit 1
+		), 0) as streak_count,
+		(select answers::text from user_quiz_responses where user_played_quiz_id = $1 and question_id = $2) as answers,
+		(select answer_text from user_quiz_responses where user_played_quiz_id = $1 and question_id = $2) as answer_text,
+		(select answer_value from user_quiz_responses where user_played_quiz_id = $1 and question_id = $2) as answer_value,
+		(select answer_pairs::text from user_quiz_responses where user_played_quiz_id = $1 and question_id = $2) as answer_pairs,
+		(select calculated_points from user_quiz_responses where user_played_quiz_id = $1 and question_id = $2 and answers is not null) as question_points,
+		(select calculated_score from user_quiz_responses where user_played_quiz_id = $1 and question_id = $2 and answers is not null) as question_score
+	from
+		user_quiz_responses uqr
+	where
+		uqr.user_played_quiz_id = $1
+	`)
+	if err != nil {
+		return progress, err
+	}
+	defer statement.Close()
+
+	err = statement.QueryRow(userPlayedQuizId, questionId).Scan(&progress.TotalScore, &progress.StreakCount, &progress.Answers, &progress.AnswerText, &progress.AnswerValue, &progress.AnswerPairs, &progress.QuestionPoints, &progress.QuestionScore)
+	if err != nil {
+		return progress, err
+	}
+
+	progress.IsAnswerPresent = progress.Answers.Valid
+	return progress, nil
+}
+
+// AssignmentQuestion is the progress of a player on one question of an assignment
+type AssignmentQuestion struct {
+	QuestionID        uuid.UUID    `json:"question_id"`
+	OrderNo           int          `json:"order_no"`
+	DurationInSeconds int          `json:"duration_in_seconds"`
+	DeliveredAt       sql.NullTime `json:"delivered_at"`
+	IsAnswered        bool         `json:"is_answered"`
+}
+
+// GetNextAssignmentQuestion returns the question a player works on: the first unanswered one whose timer
+// did not run out, questions left unanswered past their duration are skipped. sql.ErrNoRows means finished.
+// When the session shuffles questions every player goes through them in an order of their own.
+func (model *UserQuizResponseModel) GetNextAssignmentQuestion(userPlayedQuizId uuid.UUID) (AssignmentQuestion, error) {
+	question := AssignmentQuestion{}
+
+	statement, err := model.db.Prepare(`
+	select
+		question_id,
+		position,
+		duration_in_seconds,
+		delivered_at
+	from (
+		select
+			uqr.question_id,
+			uqr.answers,
+			uqr.delivered_at,
+			q.duration_in_seconds,
+			row_number() over (
+				order by
+					case when aq.shuffle_questions then md5(uqr.user_played_quiz_id::text || uqr.question_id::text) end,
+					aqq.order_no
+			) as position
+		from
+			user_quiz_responses uqr
+			join user_played_quizzes upq on upq.id = uqr.user_played_quiz_id
+			join active_quizzes aq on aq.id = upq.active_quiz_id
+			join active_quiz_questions aqq on aqq.active_quiz_id = upq.active_quiz_id and aqq.question_id = uqr.question_id
+			join questions q on q.id = uqr.question_id
+		where
+			uqr.user_played_quiz_id = $1
+	) player_questions
+	where
+		answers is null and
+		(delivered_at is null or delivered_at + duration_in_seconds * interval '1 second' > now())
+	order by
+		position
+	limit 1
+	`)
+	if err != nil {
+		return question, err
+	}
+	defer statement.Close()
+
+	err = statement.QueryRow(userPlayedQuizId).Scan(&question.QuestionID, &question.OrderNo, &question.DurationInSeconds, &question.DeliveredAt)
+	return question, err
+}
+
+// GetAssignmentQuestion returns the progress of a player on questionId
+func (model *UserQuizResponseModel) GetAssignmentQuestion(userPlayedQuizId uuid.UUID, questionId uuid.UUID) (AssignmentQuestion, error) {
+	question := AssignmentQuestion{}
+
+	statement, err := model.db.Prepare(`
+	select
+		uqr.question_id,
+		q.duration_in_seconds,
+		uqr.delivered_at,
+		uqr.answers is not null as is_answered
+	from
+		user_quiz_responses uqr
+		join questions q on q.id = uqr.question_id
+	where
+		uqr.user_played_quiz_id = $1 and
+		uqr.question_id = $2
+	`)
+	if err != nil {
+		return question, err
+	}
+	defer statement.Close()
+
+	err = statement.QueryRow(userPlayedQuizId, questionId).Scan(&question.QuestionID, &question.DurationInSeconds, &question.DeliveredAt, &question.IsAnswered)
+	return question, err
+}
+
+// MarkDelivered starts the timer of questionId for the player, a question served again keeps its first delivery time
+func (model *UserQuizResponseModel) MarkDelivered(userPlayedQuizId uuid.UUID, questionId uuid.UUID) (time.Time, error) {
+	var deliveredAt time.Time
+
+	_, err := model.db.Update(UserQuizResponsesTable).Set(goqu.Record{
+		"delivered_at": goqu.L("coalesce(delivered_at, now())"),
+	}).Where(
+		goqu.I("user_played_quiz_id").Eq(userPlayedQuizId),
+		goqu.I("question_id").Eq(questionId),
+	).Returning("delivered_at").Executor().ScanVal(&deliveredAt)
+
+	return deliveredAt, err
+}
@@ -0,0 +1,213 @@
+// Package client drives quiz sessions from Go, as a player or as the host. It wraps the HTTP
+// endpoints a player goes through before joining and the join and arrange sockets, speaking the
+// latest version of the socket protocol.
+//
+//	c, err := client.New("http://localhost:3000")
+//	_, err = c.CreateGuest(ctx, "alice", "Felix")
+//	player, err := c.Join(ctx, "123456")
+//	for event := range player.Events() {
+//		if question, ok := event.Payload.(protocol.Question); ok {
+//			err = player.Answer([]int{1})
+//		}
+//	}
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/fasthttp/websocket"
+	resty "github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+)
+
+const apiPrefix = "/api/v1"
+
+// Statuses of a jsend body, the same on the HTTP API and on the sockets
+const (
+	StatusSuccess = "success"
+	StatusFail    = "fail"
+	StatusError   = "error"
+)
+
+// Client talks to one jovvix server. The cookies set by the server are kept and sent again, on the
+// HTTP requests as well as on the sockets, so a client is one user.
+type Client struct {
+	baseURL *url.URL
+	jar     http.CookieJar
+	http    *resty.Client
+	dialer  *websocket.Dialer
+	version int
+}
+
+// User is the user the client plays as
+type User struct {
+	ID        string `json:"id"`
+	FirstName string `json:"first_name"`
+	Username  string `json:"username"`
+	ImageKey  string `json:"img_key"`
+}
+
+// Participation is what PlayedQuizValidation returns once the invitation code is accepted
+type Participation struct {
+	UserPlayedQuiz string `json:"user_played_quiz"`
+	SessionID      string `json:"session_id"`
+	QuizTitle      string `json:"quiz_title"`
+	Mode           string `json:"mode"`
+}
+
+// APIError is a fail or error response of the HTTP API
+type APIError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (%d): %s", e.Status, e.StatusCode, e.Message)
+}
+
+// New returns a client of the server at baseURL, the address the api is served on
+func New(baseURL string) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("base url %q must be http or https", baseURL)
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := *websocket.DefaultDialer
+	dialer.Jar = jar
+
+	return &Client{
+		baseURL: parsed,
+		jar:     jar,
+		http:    resty.New().SetBaseURL(parsed.String()+apiPrefix).SetCookieJar(jar).SetHeader("accept", "application/json"),
+		dialer:  &dialer,
+		version: protocol.Latest,
+	}, nil
+}
+
+// SetCookie sends a cookie with every request, the kratos session of a registered host for instance
+func (c *Client) SetCookie(name string, value string) {
+	c.jar.SetCookies(c.baseURL, []*http.Cookie{{Name: name, Value: value, Path: "/"}})
+}
+
+// CreateGuest creates a guest user, the client plays as that user from then on
+func (c *Client) CreateGuest(ctx context.Context, username string, avatar string) (User, error) {
+	user := User{}
+	err := c.do(ctx, http.MethodPost, "/user/"+url.PathEscape(username), url.Values{"avatar_name": {avatar}}, &user)
+	return user, err
+}
+
+// ValidateInvitation registers the user as a player of the session behind code
+func (c *Client) ValidateInvitation(ctx context.Context, code string) (Participation, error) {
+	participation := Participation{}
+	err := c.do(ctx, http.MethodPost, "/user_played_quizes/"+url.PathEscape(code), nil, &participation)
+	return participation, err
+}
+
+// DemoSession creates a session of a quiz the user owns, the user has to be a registered one
+func (c *Client) DemoSession(ctx context.Context, quizId uuid.UUID) (uuid.UUID, error) {
+	sessionId := uuid.UUID{}
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/quizzes/%s/demo_session", quizId), nil, &sessionId)
+	return sessionId, err
+}
+
+// Join validates the invitation code and connects to the join socket of the session
+func (c *Client) Join(ctx context.Context, code string) (*Player, error) {
+	participation, err := c.ValidateInvitation(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := c.dial(ctx, "/socket/join/"+url.PathEscape(code))
+	if err != nil {
+		return nil, err
+	}
+
+	return newPlayer(conn, participation), nil
+}
+
+// Host connects to the arrange socket of a session the user hosts
+func (c *Client) Host(ctx context.Context, sessionId uuid.UUID) (*Host, error) {
+	conn, err := c.dial(ctx, "/socket/admin/arrange/"+sessionId.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return newHost(conn), nil
+}
+
+func (c *Client) dial(ctx context.Context, path string) (*conn, error) {
+	socketURL := *c.baseURL
+	socketURL.Scheme = "ws"
+	if c.baseURL.Scheme == "https" {
+		socketURL.Scheme = "wss"
+	}
+	socketURL.Path += apiPrefix + path
+	socketURL.RawQuery = url.Values{protocol.VersionParam: {fmt.Sprint(c.version)}}.Encode()
+
+	ws, res, err := c.dialer.DialContext(ctx, socketURL.String(), nil)
+	if err != nil {
+		if res != nil {
+			return nil, &APIError{StatusCode: res.StatusCode, Status: StatusFail, Message: err.Error()}
+		}
+		return nil, err
+	}
+
+	return newConn(ws, c.version), nil
+}
+
+// envelope is the jsend body of every response
+type envelope struct {
+	Status  string          `json:"status"`
+	Data    json.RawMessage `json:"data"`
+	Message string          `json:"message"`
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, result any) error {
+	request := c.http.R().SetContext(ctx)
+	if query != nil {
+		request.SetQueryParamsFromValues(query)
+	}
+
+	res, err := request.Execute(method, path)
+	if err != nil {
+		return err
+	}
+
+	reply := envelope{}
+	if err := json.Unmarshal(res.Body(), &reply); err != nil {
+		return &APIError{StatusCode: res.StatusCode(), Status: StatusError, Message: res.String()}
+	}
+
+	if reply.Status != StatusSuccess {
+		message := reply.Message
+		if message == "" {
+			var data any
+			if err := json.Unmarshal(reply.Data, &data); err == nil {
+				message = fmt.Sprint(data)
+			}
+		}
+		return &APIError{StatusCode: res.StatusCode(), Status: reply.Status, Message: message}
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(reply.Data, result)
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/fasthttp/websocket"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func frame(t *testing.T, status string, event string, data any) []byte {
+	message, err := json.Marshal(map[string]any{"status": status, "data": structs.SocketResponseFormat{EventName: event, Data: data}})
+	assert.Nil(t, err)
+	return message
+}
+
+func TestDecodeEvent(t *testing.T) {
+	questionId := uuid.New()
+
+	t.Run("typed payload of a frame", func(t *testing.T) {
+		event, err := decodeEvent(protocol.V2, frame(t, StatusSuccess, constants.EventSendQuestion, protocol.Frame{
+			Component: constants.Question,
+			Action:    constants.ActionSendQuestion,
+			Data:      protocol.Question{ID: questionId, Options: map[string]string{"1": "a"}},
+		}))
+		assert.Nil(t, err)
+		assert.True(t, event.Is(constants.EventSendQuestion))
+		assert.False(t, event.Failed())
+		assert.Equal(t, constants.ActionSendQuestion, event.Action)
+
+		question, ok := event.Payload.(protocol.Question)
+		assert.True(t, ok)
+		assert.Equal(t, questionId, question.ID)
+	})
+
+	t.Run("bare event", func(t *testing.T) {
+		event, err := decodeEvent(protocol.V2, frame(t, StatusSuccess, constants.EventPong, ""))
+		assert.Nil(t, err)
+		assert.Equal(t, protocol.Status(""), event.Payload)
+	})
+
+	t.Run("failure sent without a frame", func(t *testing.T) {
+		event, err := decodeEvent(protocol.V2, frame(t, StatusFail, constants.EventJoinQuiz, constants.ErrPlayerBanned))
+		assert.Nil(t, err)
+		assert.True(t, event.Failed())
+		assert.Equal(t, protocol.Status(constants.ErrPlayerBanned), event.Payload)
+	})
+
+	t.Run("payload picked by its fields", func(t *testing.T) {
+		event, err := decodeEvent(protocol.V2, frame(t, StatusSuccess, constants.EventRenamePlayer, protocol.Frame{Data: protocol.Renamed{Name: "bob"}}))
+		assert.Nil(t, err)
+		assert.Equal(t, protocol.Renamed{Name: "bob"}, event.Payload)
+
+		event, err = decodeEvent(protocol.V2, frame(t, StatusSuccess, constants.EventRenamePlayer, protocol.Frame{Data: protocol.Moderation{UserID: "u1", Name: "bob"}}))
+		assert.Nil(t, err)
+		assert.Equal(t, protocol.Moderation{UserID: "u1", Name: "bob"}, event.Payload)
+	})
+
+	t.Run("renamed event", func(t *testing.T) {
+		event, err := decodeEvent(protocol.V2, frame(t, StatusSuccess, "answer_submitted", protocol.Frame{Data: protocol.AnsweredPlayer{ID: "u1"}}))
+		assert.Nil(t, err)
+		assert.True(t, event.Is(constants.EventAnswerSubmittedByUser))
+		assert.Equal(t, protocol.AnsweredPlayer{ID: "u1"}, event.Payload)
+	})
+}
+
+func TestPlayer(t *testing.T) {
+	questionId := uuid.New()
+	answers := make(chan protocol.Request, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/user/alice", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Felix", r.URL.Query().Get("avatar_name"))
+		http.SetCookie(w, &http.Cookie{Name: constants.CookieUser, Value: "token", Path: "/"})
+		_, _ = w.Write([]byte(`{"status":"success","data":{"id":"u1","username":"alice"}}`))
+	})
+	mux.HandleFunc("/api/v1/user_played_quizes/123456", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie(constants.CookieUser); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"status":"fail","data":"unauthenticated"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"user_played_quiz":"p1","session_id":"s1"}}`))
+	})
+	mux.HandleFunc("/api/v1/socket/join/123456", func(w http.ResponseWriter, r *http.Request) {
+		_, err := r.Cookie(constants.CookieUser)
+		assert.Nil(t, err)
+		assert.Equal(t, strconv.Itoa(protocol.Latest), r.URL.Query().Get(protocol.VersionParam))
+
+		upgrader := websocket.Upgrader{}
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		_ = ws.WriteMessage(websocket.TextMessage, frame(t, StatusSuccess, constants.EventSendQuestion, protocol.Frame{Data: protocol.Question{ID: questionId}}))
+
+		request := protocol.Request{}
+		if err := ws.ReadJSON(&request); err == nil {
+			answers <- request
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c, err := New(server.URL)
+	assert.Nil(t, err)
+
+	t.Run("join needs a user", func(t *testing.T) {
+		_, err := c.Join(ctx, "123456")
+		apiErr, ok := err.(*APIError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+		assert.Equal(t, StatusFail, apiErr.Status)
+	})
+
+	t.Run("guest answers the question", func(t *testing.T) {
+		user, err := c.CreateGuest(ctx, "alice", "Felix")
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", user.Username)
+
+		player, err := c.Join(ctx, "123456")
+		assert.Nil(t, err)
+		defer player.Close()
+		assert.Equal(t, "p1", player.Participation.UserPlayedQuiz)
+
+		event := <-player.Events()
+		assert.True(t, event.Is(constants.EventSendQuestion))
+		assert.Equal(t, questionId, player.Question())
+		assert.Nil(t, player.Answer([]int{2}))
+
+		select {
+		case request := <-answers:
+			assert.Equal(t, constants.EventSubmitAnswer, request.Event)
+			data, _ := json.Marshal(request.Data)
+			answer := protocol.SubmitAnswer{}
+			assert.Nil(t, json.Unmarshal(data, &answer))
+			assert.Equal(t, questionId, answer.QuestionId)
+			assert.Equal(t, []int{2}, answer.AnswerKeys)
+			assert.Positive(t, answer.ResponseTime)
+		case <-ctx.Done():
+			t.Fatal("answer was not received")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+)
+
+// Host is the arrange socket of the host of a session
+type Host struct {
+	*conn
+}
+
+func newHost(conn *conn) *Host {
+	conn.start()
+	return &Host{conn: conn}
+}
+
+// Start starts the quiz once players joined the lobby
+func (h *Host) Start() error {
+	return h.send(constants.EventStartQuiz, nil)
+}
+
+// Next moves on from the scoreboard, the server asks for it with a next_question_asked event
+func (h *Host) Next() error {
+	return h.send(constants.EventNextQuestionAsked, nil)
+}
+
+// Skip ends the running question. Unless force is set the server asks for a confirmation with a
+// skip_asked event while some players did not answer.
+func (h *Host) Skip(force bool) error {
+	if force {
+		return h.send(constants.EventForceSkip, nil)
+	}
+	return h.send(constants.EventSkipAsked, nil)
+}
+
+// SkipTimer leaves the scoreboard before its timer ends
+func (h *Host) SkipTimer() error {
+	return h.send(constants.EventSkipTimer, nil)
+}
+
+// Pause pauses the quiz, the timers stop until Resume
+func (h *Host) Pause() error {
+	return h.send(constants.EventPauseQuiz, true)
+}
+
+// Resume resumes a paused quiz
+func (h *Host) Resume() error {
+	return h.send(constants.EventPauseQuiz, false)
+}
+
+// Kick removes a player from the session and erases the answers they gave, they may join again
+func (h *Host) Kick(userId string) error {
+	return h.send(constants.EventKickPlayer, protocol.ModeratePlayer{UserId: userId})
+}
+
+// Ban removes a player from the session for good
+func (h *Host) Ban(userId string) error {
+	return h.send(constants.EventBanPlayer, protocol.ModeratePlayer{UserId: userId})
+}
+
+// Rename changes the name a player is shown under
+func (h *Host) Rename(userId string, name string) error {
+	return h.send(constants.EventRenamePlayer, protocol.ModeratePlayer{UserId: userId, Name: name})
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/google/uuid"
+)
+
+// ErrNoQuestion is returned by Answer before the first question arrives
+var ErrNoQuestion = errors.New("no question was received yet")
+
+// Player is the join socket of a player
+type Player struct {
+	*conn
+	Participation Participation
+
+	mu         sync.Mutex
+	question   uuid.UUID
+	receivedAt time.Time
+}
+
+func newPlayer(conn *conn, participation Participation) *Player {
+	player := &Player{conn: conn, Participation: participation}
+	conn.onEvent = player.track
+	conn.start()
+
+	return player
+}
+
+// track remembers the running question, the one Answer answers
+func (p *Player) track(event Event) {
+	var question *protocol.Question
+	switch payload := event.Payload.(type) {
+	case protocol.Question:
+		question = &payload
+	case protocol.PlayerState:
+		question = payload.Question
+	}
+	if question == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.question = question.ID
+	p.receivedAt = time.Now()
+}
+
+// Question returns the id of the last question received
+func (p *Player) Question() uuid.UUID {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.question
+}
+
+// Answer answers the last question received with the keys of the options, as the player sees them.
+// The server acknowledges it with a submit_answer event.
+func (p *Player) Answer(keys []int) error {
+	return p.Submit(protocol.SubmitAnswer{AnswerKeys: keys})
+}
+
+// Submit answers the last question received with the keys, text, value or pairs of answer, whichever the
+// question takes. Its question id and response time are filled in.
+func (p *Player) Submit(answer protocol.SubmitAnswer) error {
+	p.mu.Lock()
+	questionId, receivedAt := p.question, p.receivedAt
+	p.mu.Unlock()
+
+	if questionId == uuid.Nil {
+		return ErrNoQuestion
+	}
+
+	answer.QuestionId = questionId
+	answer.ResponseTime = max(int(time.Since(receivedAt).Milliseconds()), 1)
+	return p.send(constants.EventSubmitAnswer, answer)
+}
+
+// Close leaves the session and closes the socket
+func (p *Player) Close() error {
+	_ = p.send(constants.EventWebsocketClose, nil)
+	return p.conn.Close()
+}
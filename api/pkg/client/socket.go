@@ -0,0 +1,202 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
+	"github.com/fasthttp/websocket"
+)
+
+// keepAlive is how often a socket is pinged, as often as the web client does
+const keepAlive = 45 * time.Second
+
+// Event is a frame the server sent on a socket
+type Event struct {
+	// Name is the event as it is named on the wire
+	Name      string
+	Status    string
+	Component string
+	Action    string
+	// Data is the payload as it was received
+	Data json.RawMessage
+	// Payload is Data decoded into the protocol type of the event, a protocol.Question for a
+	// send_question for instance. It is nil when Data fits none of the types of the event.
+	Payload any
+
+	version int
+}
+
+// Is reports whether the event is event, named as in the constants package
+func (e Event) Is(event string) bool {
+	return e.Name == protocol.EventName(e.version, event)
+}
+
+// Failed reports whether the server sent the event as a fail or an error
+func (e Event) Failed() bool {
+	return e.Status != StatusSuccess
+}
+
+// conn is a socket of the client, its frames are read into the events channel until it closes
+type conn struct {
+	ws        *websocket.Conn
+	version   int
+	writeMu   sync.Mutex
+	events    chan Event
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+	onEvent   func(Event)
+}
+
+func newConn(ws *websocket.Conn, version int) *conn {
+	return &conn{
+		ws:      ws,
+		version: version,
+		events:  make(chan Event, 64),
+		done:    make(chan struct{}),
+	}
+}
+
+func (c *conn) start() {
+	go c.read()
+	go c.keepAlive()
+}
+
+// Events returns the events of the socket, it is closed with the socket. It has to be drained,
+// the socket is not read while the channel is full.
+func (c *conn) Events() <-chan Event {
+	return c.events
+}
+
+// Err returns why the socket closed, nil when it was closed by Close. It is only set once Events is closed.
+func (c *conn) Err() error {
+	return c.err
+}
+
+// Close closes the socket
+func (c *conn) Close() error {
+	c.stop()
+
+	c.writeMu.Lock()
+	_ = c.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	c.writeMu.Unlock()
+
+	return c.ws.Close()
+}
+
+func (c *conn) stop() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *conn) send(event string, data any) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.ws.WriteJSON(protocol.Request{Event: event, Data: data})
+}
+
+func (c *conn) read() {
+	defer close(c.events)
+
+	for {
+		_, message, err := c.ws.ReadMessage()
+		if err != nil {
+			select {
+			case <-c.done:
+			default:
+				c.err = err
+			}
+			c.stop()
+			return
+		}
+
+		event, err := decodeEvent(c.version, message)
+		if err != nil {
+			continue
+		}
+
+		if c.onEvent != nil {
+			c.onEvent(event)
+		}
+
+		select {
+		case c.events <- event:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *conn) keepAlive() {
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.send(constants.EventPing, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// decodeEvent reads a jsend frame of the server, the data of the events that are not bare is a protocol.Frame
+func decodeEvent(version int, message []byte) (Event, error) {
+	body := struct {
+		Status string `json:"status"`
+		Data   struct {
+			Event string          `json:"event"`
+			Data  json.RawMessage `json:"data"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(message, &body); err != nil {
+		return Event{}, err
+	}
+
+	event := Event{Name: body.Data.Event, Status: body.Status, Data: body.Data.Data, version: version}
+
+	described, ok := protocol.Lookup(version, protocol.ServerToClient, event.Name)
+	if !ok {
+		return event, nil
+	}
+
+	if !described.Bare {
+		frame := struct {
+			Component string          `json:"component"`
+			Action    string          `json:"action"`
+			Data      json.RawMessage `json:"data"`
+		}{}
+		// some failures are sent without a frame, their data is left as it is
+		if err := json.Unmarshal(event.Data, &frame); err == nil {
+			event.Component, event.Action, event.Data = frame.Component, frame.Action, frame.Data
+		}
+	}
+
+	event.Payload = decodePayload(event.Data, described.Payloads)
+	return event, nil
+}
+
+// decodePayload decodes data into the first of payloads it fits exactly
+func decodePayload(data json.RawMessage, payloads []any) any {
+	for _, payload := range payloads {
+		value := reflect.New(reflect.TypeOf(payload))
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(value.Interface()); err == nil {
+			return value.Elem().Interface()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,397 @@
+// Package hub fans the channels of the sessions out to the sockets connected to this node. A channel
+// is subscribed to once per node however many sockets listen to it, and every socket writes through a
+// bounded queue of its own, so a socket that does not keep up never holds the others back.
+package hub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Improwised/jovvix/api/config"
+	"github.com/Improwised/jovvix/api/pkg/pubsub"
+	"github.com/Improwised/jovvix/api/utils"
+	"github.com/gofiber/contrib/websocket"
+	"go.uber.org/zap"
+)
+
+// What happens to a socket that is a whole buffer behind on the messages of its channels. Only those
+// can be dropped, a direct reply the player waits for is never dropped: a socket without room for one
+// within replyWait is disconnected whatever the policy.
+const (
+	DISCONNECT = "disconnect"
+	DROP       = "drop"
+)
+
+// replyWait is how long a direct write waits for room in the queue of a slow socket
+const replyWait = time.Second
+
+var (
+	// ErrClosed is returned when writing to a client that is closed
+	ErrClosed = errors.New("hub: client is closed")
+	// ErrSlow is returned when a client had no room for a write in time, it is disconnected with it
+	ErrSlow = errors.New("hub: client is too slow")
+)
+
+// Stats is what the hub of this node is doing
+type Stats struct {
+	// Channels is how many channels are subscribed to
+	Channels int
+	// Clients is how many sockets are attached
+	Clients int
+	// Delivered counts the messages handed to sockets
+	Delivered uint64
+	// Dropped counts the messages a socket had no room for
+	Dropped uint64
+	// Disconnected counts the sockets closed for being slow
+	Disconnected uint64
+}
+
+// Hub holds the subscriptions of this node
+type Hub struct {
+	pubsub pubsub.PubSub
+	logger *zap.Logger
+	buffer int
+	policy string
+
+	mu       sync.RWMutex
+	channels map[string]*channel
+	clients  int
+
+	delivered    atomic.Uint64
+	dropped      atomic.Uint64
+	disconnected atomic.Uint64
+}
+
+// channel is a subscription shared by the clients listening to it
+type channel struct {
+	name    string
+	clients map[*Client]struct{}
+
+	// ready is closed once the subscription is made, err tells whether it failed
+	ready        chan struct{}
+	subscription pubsub.Subscription
+	err          error
+}
+
+// New returns a hub subscribing through p, with the buffer size and slow consumer policy of cfg
+func New(p pubsub.PubSub, cfg config.QuizConfig, logger *zap.Logger) (*Hub, error) {
+	policy := cfg.HubSlowConsumer
+	switch policy {
+	case "":
+		policy = DISCONNECT
+	case DISCONNECT, DROP:
+	default:
+		return nil, fmt.Errorf("unknown slow consumer policy %q", cfg.HubSlowConsumer)
+	}
+
+	return &Hub{
+		pubsub:   p,
+		logger:   logger,
+		buffer:   cfg.HubBuffer(),
+		policy:   policy,
+		channels: map[string]*channel{},
+	}, nil
+}
+
+// Stats returns the counts of the hub
+func (h *Hub) Stats() Stats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return Stats{
+		Channels:     len(h.channels),
+		Clients:      h.clients,
+		Delivered:    h.delivered.Load(),
+		Dropped:      h.dropped.Load(),
+		Disconnected: h.disconnected.Load(),
+	}
+}
+
+// Attach starts writing to conn through a client, the client is closed by the caller once done
+func (h *Hub) Attach(conn *websocket.Conn) *Client {
+	client := &Client{
+		hub:     h,
+		conn:    conn,
+		writes:  make(chan func(*websocket.Conn) error, h.buffer),
+		inbox:   make(chan *pubsub.Message, h.buffer),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	h.clients++
+	h.mu.Unlock()
+
+	go client.writeLoop()
+	return client
+}
+
+// Subscribe makes client receive what is published on channels, it returns once the subscriptions are active
+func (h *Hub) Subscribe(client *Client, channels ...string) error {
+	for _, name := range channels {
+		if err := h.join(client, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Hub) join(client *Client, name string) error {
+	h.mu.Lock()
+	ch, ok := h.channels[name]
+	if !ok {
+		ch = &channel{name: name, clients: map[*Client]struct{}{}, ready: make(chan struct{})}
+		h.channels[name] = ch
+	}
+	ch.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	client.mu.Lock()
+	client.channels = append(client.channels, name)
+	client.mu.Unlock()
+
+	// the first client subscribes, outside of the lock so other channels are not held up meanwhile
+	if !ok {
+		ch.subscription, ch.err = h.pubsub.Subscribe(name)
+		if ch.err == nil {
+			go h.fanOut(ch)
+		} else {
+			h.mu.Lock()
+			if h.channels[name] == ch {
+				delete(h.channels, name)
+			}
+			h.mu.Unlock()
+		}
+		close(ch.ready)
+	}
+
+	<-ch.ready
+	return ch.err
+}
+
+// leave unsubscribes client, the subscription of a channel is closed with its last client
+func (h *Hub) leave(client *Client) {
+	client.mu.Lock()
+	names := client.channels
+	client.channels = nil
+	client.mu.Unlock()
+
+	unused := []*channel{}
+	h.mu.Lock()
+	h.clients--
+	for _, name := range names {
+		ch, ok := h.channels[name]
+		if !ok {
+			continue
+		}
+		delete(ch.clients, client)
+		if len(ch.clients) == 0 {
+			delete(h.channels, name)
+			unused = append(unused, ch)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, ch := range unused {
+		<-ch.ready
+		if ch.err == nil {
+			if err := ch.subscription.Close(); err != nil {
+				h.logger.Error("error while closing hub subscription", zap.String("channel", ch.name), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (h *Hub) fanOut(ch *channel) {
+	for msg := range ch.subscription.Channel() {
+		h.mu.RLock()
+		clients := make([]*Client, 0, len(ch.clients))
+		for client := range ch.clients {
+			clients = append(clients, client)
+		}
+		h.mu.RUnlock()
+
+		for _, client := range clients {
+			client.deliver(msg)
+		}
+	}
+}
+
+// slow applies the slow consumer policy to a client that had no room for a message of its channels
+func (h *Hub) slow(client *Client) {
+	if h.policy == DROP {
+		h.dropped.Add(1)
+		return
+	}
+
+	h.disconnectSlow(client)
+}
+
+// disconnectSlow closes a client that does not keep up, the player rejoins and gets the state back
+func (h *Hub) disconnectSlow(client *Client) {
+	if client.disconnect() {
+		h.disconnected.Add(1)
+		h.logger.Warn("hub disconnected a slow socket")
+	}
+}
+
+// Client is a socket attached to the hub. Everything written to the socket goes through the client,
+// one write at a time.
+type Client struct {
+	hub  *Hub
+	conn *websocket.Conn
+
+	mu       sync.Mutex
+	channels []string
+
+	writes chan func(*websocket.Conn) error
+	inbox  chan *pubsub.Message
+
+	// done is closed once the client is closed, stopped once its last write is over
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// Messages returns what is published on the channels of the client
+func (c *Client) Messages() <-chan *pubsub.Message {
+	return c.inbox
+}
+
+// Done is closed once the client is closed, by the caller or for being slow
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Send writes a success frame of event to the socket
+func (c *Client) Send(event string, data any) error {
+	return c.enqueue(event, data, utils.JSONSuccessWs)
+}
+
+// Fail writes a fail frame of event to the socket
+func (c *Client) Fail(event string, data any) error {
+	return c.enqueue(event, data, utils.JSONFailWs)
+}
+
+// Error writes an error frame of event to the socket
+func (c *Client) Error(event string, data any) error {
+	return c.enqueue(event, data, utils.JSONErrorWs)
+}
+
+// enqueue queues a frame of data as it is now, the caller may change data once it returns
+func (c *Client) enqueue(event string, data any, frame func(*websocket.Conn, string, any) error) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.queue(func(conn *websocket.Conn) error {
+		return frame(conn, event, json.RawMessage(raw))
+	})
+}
+
+func (c *Client) queue(write func(*websocket.Conn) error) error {
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case c.writes <- write:
+		return nil
+	default:
+	}
+
+	// a direct reply is waited for a little, then the socket is dropped rather than the reply
+	timer := time.NewTimer(replyWait)
+	defer timer.Stop()
+	select {
+	case c.writes <- write:
+		return nil
+	case <-c.done:
+		return ErrClosed
+	case <-timer.C:
+		c.hub.disconnectSlow(c)
+		return ErrSlow
+	}
+}
+
+func (c *Client) deliver(msg *pubsub.Message) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.inbox <- msg:
+		c.hub.delivered.Add(1)
+	default:
+		c.hub.slow(c)
+	}
+}
+
+func (c *Client) writeLoop() {
+	defer close(c.stopped)
+
+	for {
+		select {
+		case write := <-c.writes:
+			if !c.write(write) {
+				return
+			}
+		case <-c.done:
+			// what was queued before the client was closed still goes out
+			for {
+				select {
+				case write := <-c.writes:
+					if !c.write(write) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) write(write func(*websocket.Conn) error) bool {
+	if err := write(c.conn); err != nil {
+		c.hub.logger.Debug("error while writing to hub socket", zap.Error(err))
+		c.close()
+		return false
+	}
+	return true
+}
+
+// Close detaches the client after the writes queued so far
+func (c *Client) Close() {
+	c.close()
+	<-c.stopped
+}
+
+func (c *Client) close() bool {
+	closed := false
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.hub.leave(c)
+		closed = true
+	})
+	return closed
+}
+
+// disconnect closes a slow client and its socket, whatever is still queued is dropped
+func (c *Client) disconnect() bool {
+	if !c.close() {
+		return false
+	}
+	if c.conn != nil && c.conn.Conn != nil {
+		c.conn.Close()
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package hub
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Improwised/jovvix/api/config"
+	"github.com/Improwised/jovvix/api/pkg/pubsub"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func receive(t *testing.T, client *Client) *pubsub.Message {
+	select {
+	case msg := <-client.Messages():
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("message was not received")
+		return nil
+	}
+}
+
+func TestHub(t *testing.T) {
+	t.Run("unknown slow consumer policy", func(t *testing.T) {
+		_, err := New(pubsub.NewMemory("node-a"), config.QuizConfig{HubSlowConsumer: "block"}, zap.NewNop())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("one subscription per channel", func(t *testing.T) {
+		memory := pubsub.NewMemory("node-a")
+		hub, err := New(memory, config.QuizConfig{}, zap.NewNop())
+		assert.Nil(t, err)
+
+		first := hub.Attach(nil)
+		second := hub.Attach(nil)
+		assert.Nil(t, hub.Subscribe(first, "session", "moderation"))
+		assert.Nil(t, hub.Subscribe(second, "session"))
+		assert.Equal(t, 2, hub.Stats().Channels)
+		assert.Equal(t, 2, hub.Stats().Clients)
+
+		assert.Nil(t, memory.Publish("session", "question"))
+		assert.Equal(t, "question", receive(t, first).Payload)
+		assert.Equal(t, "question", receive(t, second).Payload)
+		assert.Equal(t, uint64(2), hub.Stats().Delivered)
+
+		first.Close()
+		assert.Equal(t, 1, hub.Stats().Channels)
+		assert.Equal(t, 1, hub.Stats().Clients)
+
+		assert.Nil(t, memory.Publish("session", "score"))
+		assert.Equal(t, "score", receive(t, second).Payload)
+
+		second.Close()
+		assert.Equal(t, Stats{Delivered: 3}, hub.Stats())
+	})
+
+	t.Run("slow consumer is disconnected", func(t *testing.T) {
+		memory := pubsub.NewMemory("node-a")
+		hub, err := New(memory, config.QuizConfig{HubSendBuffer: 2}, zap.NewNop())
+		assert.Nil(t, err)
+
+		slow := hub.Attach(nil)
+		fast := hub.Attach(nil)
+		defer fast.Close()
+		assert.Nil(t, hub.Subscribe(slow, "session"))
+		assert.Nil(t, hub.Subscribe(fast, "session"))
+
+		for i := 0; i < 3; i++ {
+			assert.Nil(t, memory.Publish("session", i))
+			assert.Equal(t, strconv.Itoa(i), receive(t, fast).Payload)
+		}
+
+		select {
+		case <-slow.Done():
+		case <-time.After(time.Second):
+			t.Fatal("slow client was not disconnected")
+		}
+		assert.Equal(t, uint64(1), hub.Stats().Disconnected)
+		assert.Equal(t, ErrClosed, slow.Send("pong", ""))
+		slow.Close()
+		assert.Equal(t, 1, hub.Stats().Clients)
+	})
+
+	t.Run("slow consumer misses messages", func(t *testing.T) {
+		memory := pubsub.NewMemory("node-a")
+		hub, err := New(memory, config.QuizConfig{HubSendBuffer: 2, HubSlowConsumer: DROP}, zap.NewNop())
+		assert.Nil(t, err)
+
+		slow := hub.Attach(nil)
+		defer slow.Close()
+		assert.Nil(t, hub.Subscribe(slow, "session"))
+
+		for i := 0; i < 3; i++ {
+			assert.Nil(t, memory.Publish("session", i))
+		}
+
+		assert.Eventually(t, func() bool {
+			return hub.Stats().Dropped == 1
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, "0", receive(t, slow).Payload)
+		assert.Equal(t, "1", receive(t, slow).Payload)
+		assert.Nil(t, slow.Send("pong", ""))
+	})
+
+	t.Run("slow consumer is disconnected rather than missing a reply", func(t *testing.T) {
+		hub, err := New(pubsub.NewMemory("node-a"), config.QuizConfig{HubSendBuffer: 2, HubSlowConsumer: DROP}, zap.NewNop())
+		assert.Nil(t, err)
+
+		client := hub.Attach(nil)
+		block := make(chan struct{})
+		stalled := make(chan struct{}, 1)
+		stall := func(*websocket.Conn) error {
+			select {
+			case stalled <- struct{}{}:
+			default:
+			}
+			<-block
+			return nil
+		}
+
+		// the first write holds the socket up, the next two fill its queue
+		assert.Nil(t, client.queue(stall))
+		<-stalled
+		assert.Nil(t, client.queue(stall))
+		assert.Nil(t, client.queue(stall))
+
+		assert.Equal(t, ErrSlow, client.queue(stall))
+		select {
+		case <-client.Done():
+		default:
+			t.Fatal("slow client was not disconnected")
+		}
+		assert.Equal(t, uint64(1), hub.Stats().Disconnected)
+		assert.Equal(t, uint64(0), hub.Stats().Dropped)
+
+		close(block)
+		client.Close()
+	})
+}
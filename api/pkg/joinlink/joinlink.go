@@ -0,0 +1,101 @@
+// Package joinlink signs the short links that take a player straight to the join page of a session. A
+// token carries the invitation code, when it expires and optionally a grant letting its holder past the
+// passcode of the session, with a truncated HMAC so it can not be forged for another code. The token is
+// only signed, so the passcode itself is never put in it.
+package joinlink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalid is returned for a token that was not signed with the secret
+	ErrInvalid = errors.New("joinlink: invalid link")
+	// ErrExpired is returned for a token signed for a session that is over by now
+	ErrExpired = errors.New("joinlink: link expired")
+)
+
+// signatureSize is how many bytes of the HMAC are kept, enough to make guessing hopeless
+const signatureSize = 12
+
+// code and expiry take the first bytes of the payload, the grant the rest when there is one
+const (
+	codeSize   = 3
+	expirySize = 4
+	grantSize  = 8
+)
+
+// Link is what a token stands for
+type Link struct {
+	Code      int
+	ExpiresAt time.Time
+	// Grant lets the holder of the link past the passcode it was made for, empty for a link without it
+	Grant []byte
+}
+
+// Grant returns the grant of a link past the passcode stored as passcodeHash. It follows the hash, so
+// changing the passcode voids the links made past the former one.
+func Grant(secret string, passcodeHash string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("join-link-grant."))
+	mac.Write([]byte(passcodeHash))
+	return mac.Sum(nil)[:grantSize]
+}
+
+// Sign returns the token of link
+func Sign(secret string, link Link) string {
+	payload := make([]byte, codeSize+expirySize, codeSize+expirySize+grantSize)
+	payload[0] = byte(link.Code >> 16)
+	payload[1] = byte(link.Code >> 8)
+	payload[2] = byte(link.Code)
+	binary.BigEndian.PutUint32(payload[codeSize:], uint32(link.ExpiresAt.Unix()))
+	if len(link.Grant) >= grantSize {
+		payload = append(payload, link.Grant[:grantSize]...)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(signature(secret, payload))
+}
+
+// Parse returns the link of token, once its signature and expiry are checked. Whether its grant still
+// matches the passcode of the session is left to the caller.
+func Parse(secret string, token string, now time.Time) (Link, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Link{}, ErrInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || (len(payload) != codeSize+expirySize && len(payload) != codeSize+expirySize+grantSize) {
+		return Link{}, ErrInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(sig, signature(secret, payload)) {
+		return Link{}, ErrInvalid
+	}
+
+	link := Link{
+		Code:      int(payload[0])<<16 | int(payload[1])<<8 | int(payload[2]),
+		ExpiresAt: time.Unix(int64(binary.BigEndian.Uint32(payload[codeSize:])), 0),
+	}
+	if len(payload) > codeSize+expirySize {
+		link.Grant = payload[codeSize+expirySize:]
+	}
+	if !now.Before(link.ExpiresAt) {
+		return link, ErrExpired
+	}
+
+	return link, nil
+}
+
+func signature(secret string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("join-link."))
+	mac.Write(payload)
+	return mac.Sum(nil)[:signatureSize]
+}
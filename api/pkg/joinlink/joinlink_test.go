@@ -0,0 +1,73 @@
+package joinlink
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinLink(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	expiresAt := now.Add(24 * time.Hour)
+
+	t.Run("code only", func(t *testing.T) {
+		token := Sign("secret", Link{Code: 987654, ExpiresAt: expiresAt})
+		assert.Len(t, token, 27)
+
+		link, err := Parse("secret", token, now)
+		assert.Nil(t, err)
+		assert.Equal(t, Link{Code: 987654, ExpiresAt: expiresAt}, link)
+	})
+
+	t.Run("code and grant", func(t *testing.T) {
+		grant := Grant("secret", "$2a$10$hash of the passcode")
+		token := Sign("secret", Link{Code: 123456, ExpiresAt: expiresAt, Grant: grant})
+		assert.Len(t, token, 37)
+
+		link, err := Parse("secret", token, now)
+		assert.Nil(t, err)
+		assert.Equal(t, 123456, link.Code)
+		assert.Equal(t, grant, link.Grant)
+	})
+
+	t.Run("grant", func(t *testing.T) {
+		grant := Grant("secret", "$2a$10$hash of the passcode")
+		assert.Len(t, grant, 8)
+		assert.Equal(t, grant, Grant("secret", "$2a$10$hash of the passcode"))
+		assert.NotEqual(t, grant, Grant("secret", "$2a$10$hash of another passcode"))
+		assert.NotEqual(t, grant, Grant("other", "$2a$10$hash of the passcode"))
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		token := Sign("secret", Link{Code: 123456, ExpiresAt: expiresAt})
+
+		_, err := Parse("other", token, now)
+		assert.Equal(t, ErrInvalid, err)
+	})
+
+	t.Run("tampered code", func(t *testing.T) {
+		token := Sign("secret", Link{Code: 123456, ExpiresAt: expiresAt})
+		forged := Sign("secret", Link{Code: 654321, ExpiresAt: expiresAt})
+
+		payload, _, _ := strings.Cut(forged, ".")
+		_, sig, _ := strings.Cut(token, ".")
+		_, err := Parse("secret", payload+"."+sig, now)
+		assert.Equal(t, ErrInvalid, err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		token := Sign("secret", Link{Code: 123456, ExpiresAt: expiresAt})
+
+		_, err := Parse("secret", token, expiresAt)
+		assert.Equal(t, ErrExpired, err)
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		for _, token := range []string{"", "abc", "abc.def", "!!!.???", "AAAA.AAAA"} {
+			_, err := Parse("secret", token, now)
+			assert.Equal(t, ErrInvalid, err, token)
+		}
+	})
+}
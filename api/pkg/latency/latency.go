@@ -0,0 +1,55 @@
+// Package latency collects latency samples by metric and reads their percentiles, as the simulate
+// command reports them.
+package latency
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Recorder collects the samples and failures of every metric, it is safe for concurrent use
+type Recorder struct {
+	mu       sync.Mutex
+	samples  map[string][]time.Duration
+	failures map[string]int
+}
+
+// New returns an empty recorder
+func New() *Recorder {
+	return &Recorder{samples: map[string][]time.Duration{}, failures: map[string]int{}}
+}
+
+// Add records a sample of metric, negative samples from skewed clocks count as 0
+func (r *Recorder) Add(metric string, sample time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples[metric] = append(r.samples[metric], max(sample, 0))
+}
+
+// Fail records a failure of metric
+func (r *Recorder) Fail(metric string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures[metric]++
+}
+
+// Get returns the sorted samples and the number of failures of metric
+func (r *Recorder) Get(metric string) ([]time.Duration, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	samples := slices.Clone(r.samples[metric])
+	slices.Sort(samples)
+	return samples, r.failures[metric]
+}
+
+// Percentile returns the nearest rank percentile of sorted samples, rounded to the microsecond
+func Percentile(samples []time.Duration, p int) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	rank := (p*len(samples) + 99) / 100
+	return samples[max(rank, 1)-1].Round(time.Microsecond)
+}
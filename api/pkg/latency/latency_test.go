@@ -0,0 +1,56 @@
+package latency
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	samples := []time.Duration{}
+	for i := 1; i <= 10; i++ {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, time.Duration(0), Percentile(nil, 50))
+	assert.Equal(t, time.Millisecond, Percentile(samples, 0))
+	assert.Equal(t, time.Millisecond, Percentile(samples, 10))
+	assert.Equal(t, 2*time.Millisecond, Percentile(samples, 11))
+	assert.Equal(t, 5*time.Millisecond, Percentile(samples, 50))
+	assert.Equal(t, 10*time.Millisecond, Percentile(samples, 99))
+	assert.Equal(t, 10*time.Millisecond, Percentile(samples, 100))
+
+	assert.Equal(t, 7*time.Millisecond, Percentile([]time.Duration{7 * time.Millisecond}, 50))
+	assert.Equal(t, 1500*time.Microsecond, Percentile([]time.Duration{1500*time.Microsecond + 400}, 50))
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := New()
+
+	var wg sync.WaitGroup
+	for i := 5; i > 0; i-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder.Add("ack", time.Duration(i)*time.Millisecond)
+		}()
+	}
+	wg.Wait()
+	recorder.Add("ack", -time.Millisecond)
+	recorder.Fail("ack")
+	recorder.Fail("join")
+
+	samples, failed := recorder.Get("ack")
+	assert.Equal(t, []time.Duration{0, time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond, 4 * time.Millisecond, 5 * time.Millisecond}, samples)
+	assert.Equal(t, 1, failed)
+
+	samples[0] = time.Hour
+	samples, _ = recorder.Get("ack")
+	assert.Equal(t, time.Duration(0), samples[0])
+
+	samples, failed = recorder.Get("join")
+	assert.Empty(t, samples)
+	assert.Equal(t, 1, failed)
+}
@@ -1,6 +1,9 @@
 package prometheus
 
 import (
+	"sync"
+
+	"github.com/Improwised/jovvix/api/pkg/hub"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -32,3 +35,36 @@ func InitPrometheusMetrics() *PrometheusMetrics {
 
 	return metrics
 }
+
+var registerHubOnce sync.Once
+
+// RegisterHub reports the counts of the hub of this node along with the other metrics
+func (m *PrometheusMetrics) RegisterHub(h *hub.Hub) {
+	registerHubOnce.Do(func() {
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "hub_channels",
+			Help:      "Channels the hub of this node is subscribed to",
+		}, func() float64 { return float64(h.Stats().Channels) })
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "hub_clients",
+			Help:      "Sockets attached to the hub of this node",
+		}, func() float64 { return float64(h.Stats().Clients) })
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "hub_messages_delivered_total",
+			Help:      "Messages the hub handed to sockets",
+		}, func() float64 { return float64(h.Stats().Delivered) })
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "hub_messages_dropped_total",
+			Help:      "Messages dropped for sockets that were too slow",
+		}, func() float64 { return float64(h.Stats().Dropped) })
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "hub_slow_disconnects_total",
+			Help:      "Sockets disconnected for being too slow",
+		}, func() float64 { return float64(h.Stats().Disconnected) })
+	})
+}
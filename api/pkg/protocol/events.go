@@ -0,0 +1,110 @@
+package protocol
+
+import (
+	"slices"
+
+	"github.com/Improwised/jovvix/api/constants"
+)
+
+// Directions of an event
+const (
+	ServerToClient = "server"
+	ClientToServer = "client"
+)
+
+// Sockets an event is exchanged on
+const (
+	SocketJoin     = "join"
+	SocketArrange  = "arrange"
+	SocketSpectate = "spectate"
+)
+
+var allSockets = []string{SocketJoin, SocketArrange, SocketSpectate}
+
+// Event is a message of the protocol. Payloads are the shapes its data takes, a server event
+// wraps them in a Frame unless it is Bare.
+type Event struct {
+	Name        string
+	Direction   string
+	Sockets     []string
+	Description string
+	Payloads    []any
+	Bare        bool
+	Since       int
+}
+
+// Events lists every message of the protocol
+var Events = []Event{
+	// sent by the server
+	{Name: constants.EventHello, Direction: ServerToClient, Sockets: allSockets, Since: V2, Description: "first frame of the socket, the version it speaks and the events it may send", Payloads: []any{Hello{}}},
+	{Name: constants.EventPong, Direction: ServerToClient, Sockets: allSockets, Bare: true, Description: "answer to a ping", Payloads: []any{Status("")}},
+	{Name: constants.EventSessionValidation, Direction: ServerToClient, Sockets: []string{SocketArrange}, Bare: true, Description: "the session to host could not be loaded", Payloads: []any{Status("")}},
+	{Name: constants.EventAuthorization, Direction: ServerToClient, Sockets: []string{SocketArrange}, Description: "the host may not host the session", Payloads: []any{Status("")}},
+	{Name: constants.EventActivateSession, Direction: ServerToClient, Sockets: []string{SocketArrange}, Description: "the session could not be activated", Payloads: []any{Status("")}},
+	{Name: constants.EventSendInvitationCode, Direction: ServerToClient, Sockets: []string{SocketArrange, SocketSpectate}, Description: "the invitation code of the session, then the players in the lobby, each time it changes before v3", Payloads: []any{InvitationCode{}, []Player{}}},
+	{Name: constants.EventLobbyChanged, Direction: ServerToClient, Sockets: []string{SocketArrange, SocketSpectate}, Since: V3, Description: "a player joined, left or was renamed in the lobby", Payloads: []any{LobbyChange{}}},
+	{Name: constants.EventStartQuiz, Direction: ServerToClient, Sockets: []string{SocketArrange}, Description: "the quiz can not start without players", Payloads: []any{Status("")}},
+	{Name: constants.EventRedirectToAdmin, Direction: ServerToClient, Sockets: []string{SocketJoin}, Description: "the player is the host of the session", Payloads: []any{RedirectToAdmin{}}},
+	{Name: constants.EventJoinQuiz, Direction: ServerToClient, Sockets: []string{SocketJoin, SocketSpectate}, Description: "the player waits for the quiz, or can not join it", Payloads: []any{Status("")}},
+	{Name: constants.EventRejoinToken, Direction: ServerToClient, Sockets: []string{SocketJoin}, Description: "token to present when the socket reconnects", Payloads: []any{RejoinToken{}}},
+	{Name: constants.EventPlayerState, Direction: ServerToClient, Sockets: []string{SocketJoin, SocketSpectate}, Description: "state of a player who reconnects", Payloads: []any{PlayerState{}}},
+	{Name: constants.EventSessionState, Direction: ServerToClient, Sockets: []string{SocketArrange}, Description: "state of a running session for a host who reconnects", Payloads: []any{SessionState{}}},
+	{Name: constants.EventTeamRoster, Direction: ServerToClient, Sockets: allSockets, Description: "the teams of the session with their members", Payloads: []any{[]Team{}}},
+	{Name: constants.EventStartCount5, Direction: ServerToClient, Sockets: allSockets, Description: "countdown before the first question", Payloads: []any{Counter{}}},
+	{Name: constants.EventSendQuestion, Direction: ServerToClient, Sockets: allSockets, Description: "the running question", Payloads: []any{Question{}}},
+	{Name: constants.EventSubmitAnswer, Direction: ServerToClient, Sockets: []string{SocketJoin}, Description: "acknowledges an answer submitted over the socket", Payloads: []any{AnswerAck{}}},
+	{Name: constants.EventAnswerSubmittedByUser, Direction: ServerToClient, Sockets: []string{SocketArrange}, Description: "a player answered the running question", Payloads: []any{AnsweredPlayer{}}},
+	{Name: constants.JoinUserOnRunningQuiz, Direction: ServerToClient, Sockets: []string{SocketArrange}, Description: "number of players once somebody joins the running quiz", Payloads: []any{int64(0)}},
+	{Name: constants.EventAnswerCount, Direction: ServerToClient, Sockets: []string{SocketSpectate}, Description: "how many players answered the running question", Payloads: []any{AnswerCount{}}},
+	{Name: constants.EventSkipAsked, Direction: ServerToClient, Sockets: []string{SocketArrange}, Description: "some players did not answer yet, the host confirms the skip", Payloads: []any{Status("")}},
+	{Name: constants.EventShowScore, Direction: ServerToClient, Sockets: allSockets, Description: "the scoreboard of the question that just ended", Payloads: []any{Scoreboard{}}},
+	{Name: constants.EventNextQuestionAsked, Direction: ServerToClient, Sockets: []string{SocketArrange}, Description: "the scoreboard waits for the host to move on", Payloads: []any{Scoreboard{}}},
+	{Name: constants.EventPauseQuiz, Direction: ServerToClient, Sockets: []string{SocketJoin, SocketSpectate}, Description: "the host paused the quiz", Payloads: []any{Status("")}},
+	{Name: constants.EventResumeQuiz, Direction: ServerToClient, Sockets: []string{SocketJoin, SocketSpectate}, Description: "the host resumed the quiz", Payloads: []any{Status("")}},
+	{Name: constants.AdminDisconnected, Direction: ServerToClient, Sockets: []string{SocketJoin, SocketSpectate}, Description: "the host left, the quiz keeps running", Payloads: []any{Status("")}},
+	{Name: constants.EventKickPlayer, Direction: ServerToClient, Sockets: []string{SocketJoin, SocketArrange}, Description: "the host removed a player, the socket of the player closes", Payloads: []any{Moderation{}, Status("")}},
+	{Name: constants.EventBanPlayer, Direction: ServerToClient, Sockets: []string{SocketJoin, SocketArrange}, Description: "the host banned a player, the socket of the player closes", Payloads: []any{Moderation{}, Status("")}},
+	{Name: constants.EventRenamePlayer, Direction: ServerToClient, Sockets: []string{SocketJoin, SocketArrange}, Description: "the host renamed a player", Payloads: []any{Renamed{}, Moderation{}}},
+	{Name: constants.EventLockLobby, Direction: ServerToClient, Sockets: []string{SocketArrange}, Description: "the lobby was locked or unlocked, newcomers are turned away while it is locked", Payloads: []any{LobbyLock{}, Status("")}},
+	{Name: constants.EventWordCloud, Direction: ServerToClient, Sockets: []string{SocketArrange}, Description: "the words typed so far to the running word cloud question, sent at most once a second while answers come in", Payloads: []any{WordCloud{}}},
+	{Name: constants.EventTerminateQuiz, Direction: ServerToClient, Sockets: allSockets, Description: "the quiz is over, the socket closes", Payloads: []any{Status("")}},
+
+	// sent by the clients
+	{Name: constants.EventPing, Direction: ClientToServer, Sockets: allSockets, Description: "keeps the socket alive, answered by a pong"},
+	{Name: constants.EventWebsocketClose, Direction: ClientToServer, Sockets: []string{SocketJoin, SocketSpectate}, Description: "the client leaves"},
+	{Name: constants.EventSubmitAnswer, Direction: ClientToServer, Sockets: []string{SocketJoin}, Description: "answer to the running question", Payloads: []any{SubmitAnswer{}}},
+	{Name: constants.EventStartQuiz, Direction: ClientToServer, Sockets: []string{SocketArrange}, Description: "start the quiz"},
+	{Name: constants.EventNextQuestionAsked, Direction: ClientToServer, Sockets: []string{SocketArrange}, Description: "move on from the scoreboard"},
+	{Name: constants.EventSkipAsked, Direction: ClientToServer, Sockets: []string{SocketArrange}, Description: "end the running question early"},
+	{Name: constants.EventForceSkip, Direction: ClientToServer, Sockets: []string{SocketArrange}, Description: "end the running question even though players did not answer"},
+	{Name: constants.EventSkipTimer, Direction: ClientToServer, Sockets: []string{SocketArrange}, Description: "leave the scoreboard before its timer ends"},
+	{Name: constants.EventPauseQuiz, Direction: ClientToServer, Sockets: []string{SocketArrange}, Description: "pause with true, resume with false", Payloads: []any{false}},
+	{Name: constants.EventKickPlayer, Direction: ClientToServer, Sockets: []string{SocketArrange}, Description: "remove a player and erase the answers they gave, they may join again", Payloads: []any{ModeratePlayer{}}},
+	{Name: constants.EventBanPlayer, Direction: ClientToServer, Sockets: []string{SocketArrange}, Description: "remove a player for good", Payloads: []any{ModeratePlayer{}}},
+	{Name: constants.EventRenamePlayer, Direction: ClientToServer, Sockets: []string{SocketArrange}, Description: "change the name a player is shown under", Payloads: []any{ModeratePlayer{}}},
+	{Name: constants.EventLockLobby, Direction: ClientToServer, Sockets: []string{SocketArrange}, Description: "lock the lobby with true, unlock it with false", Payloads: []any{false}},
+}
+
+// EventNames returns the events the server may send on socket in version
+func EventNames(version int, socket string) []string {
+	names := []string{}
+	for _, event := range Events {
+		if event.Direction != ServerToClient || event.Since > version || !slices.Contains(event.Sockets, socket) {
+			continue
+		}
+		names = append(names, EventName(version, event.Name))
+	}
+
+	return names
+}
+
+// Lookup returns the event a frame named name is in version, the name being the one used on the wire
+func Lookup(version int, direction string, name string) (Event, bool) {
+	for _, event := range Events {
+		if event.Direction == direction && event.Since <= version && EventName(version, event.Name) == name {
+			return event, true
+		}
+	}
+
+	return Event{}, false
+}
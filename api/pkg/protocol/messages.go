@@ -0,0 +1,260 @@
+package protocol
+
+import (
+	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/pkg/wordcloud"
+	"github.com/google/uuid"
+)
+
+// Status is a human readable message, sent for the events that only report a state or a failure
+type Status string
+
+// Hello is the first frame of a v2 socket
+type Hello struct {
+	Version  int      `json:"version"`
+	Versions []int    `json:"versions"`
+	Events   []string `json:"events"`
+}
+
+// InvitationCode is the code players join the session with
+type InvitationCode struct {
+	Code    int    `json:"code"`
+	JoinURL string `json:"join_url,omitempty"`
+}
+
+// Player is a player in the lobby roster
+type Player struct {
+	UserId   string
+	UserName string
+	Avatar   string
+	IsAlive  bool
+}
+
+// LobbyChange is a player who joined, left or was renamed in the lobby, with the size of the lobby after the change
+type LobbyChange struct {
+	// Change is joined, left or renamed
+	Change string `json:"change"`
+	Player Player `json:"player"`
+	Count  int64  `json:"count"`
+}
+
+// TeamMember is a player in a team roster
+type TeamMember struct {
+	UserID    string `json:"user_id"`
+	FirstName string `json:"first_name"`
+	ImageKey  string `json:"img_key"`
+}
+
+// Team is a team of the session with its members
+type Team struct {
+	ID      uuid.UUID    `json:"id"`
+	Name    string       `json:"name"`
+	Members []TeamMember `json:"members"`
+}
+
+// RedirectToAdmin sends the host who opened the join page to the session they host
+type RedirectToAdmin struct {
+	SessionID string `json:"sessionId"`
+}
+
+// RejoinToken is presented by the player when their socket reconnects
+type RejoinToken struct {
+	Token string `json:"token"`
+}
+
+// Counter is the countdown before the first question
+type Counter struct {
+	Counter int `json:"counter"`
+	Count   int `json:"count"`
+}
+
+// Question is a running question, the answers are never part of it
+type Question struct {
+	ID             uuid.UUID         `json:"id"`
+	QuizID         *uuid.UUID        `json:"quiz_id,omitempty"`
+	No             int               `json:"no"`
+	Duration       int               `json:"duration"`
+	StartTime      string            `json:"start_time"`
+	ServerTime     string            `json:"server_time"`
+	Question       string            `json:"question"`
+	Type           int               `json:"type"`
+	Options        map[string]string `json:"options"`
+	QuestionMedia  string            `json:"question_media"`
+	OptionsMedia   string            `json:"options_media"`
+	Resource       string            `json:"resource"`
+	TotalQuestions int64             `json:"totalQuestions"`
+	TotalJoinUser  *int64            `json:"totalJoinUser,omitempty"`
+	// Unit is the unit a numeric answer is given in
+	Unit string `json:"unit,omitempty"`
+	// Definitions are what the options of a matching question are paired with
+	Definitions map[string]string `json:"definitions,omitempty"`
+}
+
+// Rank is the standing of a player after a question
+type Rank struct {
+	Rank         int    `json:"rank"`
+	Points       int    `json:"points"`
+	Score        int    `json:"score"`
+	ResponseTime int    `json:"response_time"`
+	UserName     string `json:"username"`
+	FirstName    string `json:"firstname"`
+	ImageKey     string `json:"img_key"`
+	StreakCount  int    `json:"streak_count"`
+	// RankDelta is how many places the player moved up with the question, negative when they fell behind
+	RankDelta int `json:"rank_delta"`
+}
+
+// TeamRank is the standing of a team after a question
+type TeamRank struct {
+	Rank    int       `json:"rank"`
+	TeamID  uuid.UUID `json:"team_id"`
+	Name    string    `json:"name"`
+	Members int       `json:"members"`
+	Score   int       `json:"score"`
+}
+
+// NullString is how an optional answer is encoded, Valid is false when the player did not answer
+type NullString struct {
+	String string `json:"String"`
+	Valid  bool   `json:"Valid"`
+}
+
+// PlayerResponse is the answer of one player, only the host gets them
+type PlayerResponse struct {
+	UserID  string     `json:"id"`
+	Answers NullString `json:"answers"`
+	Text    string     `json:"text,omitempty"`
+	Value   *float64   `json:"value,omitempty"`
+	// Pairs gives the definition key a matching option was paired with
+	Pairs map[string]int `json:"pairs,omitempty"`
+}
+
+// AnswerGroup is how many players typed one answer, near-identical spellings counted together
+type AnswerGroup struct {
+	Answer string `json:"answer"`
+	Count  int    `json:"count"`
+}
+
+// NumericAnswer is the value a numeric question expected, values from Min to Max earned the full points
+type NumericAnswer struct {
+	Target float64 `json:"target"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Unit   string  `json:"unit,omitempty"`
+}
+
+// WordCloud is what the players typed to an open question so far, Responses counts the answers
+type WordCloud struct {
+	QuestionID uuid.UUID        `json:"question_id"`
+	Responses  int              `json:"responses"`
+	Words      []wordcloud.Word `json:"words"`
+}
+
+// Scoreboard is shown once a question is over. QuestionID is only sent to players and
+// UserResponses only to the host.
+type Scoreboard struct {
+	QuestionID     *uuid.UUID        `json:"question_id,omitempty"`
+	QuestionNo     int               `json:"question_no"`
+	QuizID         *uuid.UUID        `json:"quiz_id,omitempty"`
+	RankList       []Rank            `json:"rankList"`
+	TeamRankList   []TeamRank        `json:"teamRankList"`
+	Question       string            `json:"question"`
+	Answers        []int             `json:"answers"`
+	Options        map[string]string `json:"options"`
+	QuestionMedia  string            `json:"question_media"`
+	OptionsMedia   string            `json:"options_media"`
+	Resource       string            `json:"resource"`
+	Duration       int               `json:"duration"`
+	TotalQuestions int64             `json:"totalQuestions"`
+	UserResponses  *[]PlayerResponse `json:"userResponses,omitempty"`
+	// AcceptedAnswers are the answers a short answer question took
+	AcceptedAnswers []string `json:"acceptedAnswers,omitempty"`
+	// WrongAnswers are the wrong typed answers, grouped, only the host gets them
+	WrongAnswers *[]AnswerGroup `json:"wrongAnswers,omitempty"`
+	// NumericAnswer is the expected value of a numeric question
+	NumericAnswer *NumericAnswer `json:"numericAnswer,omitempty"`
+	// Definitions and Pairs are the definitions of a matching question and the right one for each option
+	Definitions map[string]string `json:"definitions,omitempty"`
+	Pairs       map[string]int    `json:"pairs,omitempty"`
+	// WordCloud takes the place of the ranks after a word cloud question
+	WordCloud *WordCloud `json:"wordCloud,omitempty"`
+}
+
+// SubmittedAnswer is the answer a returning player already gave to the running question
+type SubmittedAnswer struct {
+	ID     uuid.UUID      `json:"id"`
+	Keys   []int          `json:"keys"`
+	Text   string         `json:"text,omitempty"`
+	Value  *float64       `json:"value,omitempty"`
+	Pairs  map[string]int `json:"pairs,omitempty"`
+	Points int32          `json:"points"`
+	Score  int32          `json:"score"`
+}
+
+// PlayerState restores the screen of a player who reconnects
+type PlayerState struct {
+	Phase      string           `json:"phase"`
+	IsPaused   bool             `json:"is_paused"`
+	Deadline   *string          `json:"deadline"`
+	ServerTime string           `json:"server_time"`
+	Score      int              `json:"score"`
+	Streak     int              `json:"streak"`
+	Rank       *int             `json:"rank"`
+	Answer     *SubmittedAnswer `json:"answer"`
+	Question   *Question        `json:"question"`
+	Scoreboard *Scoreboard      `json:"scoreboard"`
+}
+
+// SessionState is the state of the session for a host who (re)attaches to it
+type SessionState struct {
+	Phase           string  `json:"phase"`
+	Code            int     `json:"code"`
+	IsPaused        bool    `json:"is_paused"`
+	PausedRemaining int     `json:"paused_remaining"`
+	Deadline        *string `json:"deadline"`
+	ServerTime      string  `json:"server_time"`
+}
+
+// AnswerAck acknowledges an answer submitted over the socket, Message tells why it was refused
+type AnswerAck struct {
+	ID      uuid.UUID `json:"id"`
+	Message string    `json:"message,omitempty"`
+}
+
+// AnsweredPlayer tells the host who answered the running question
+type AnsweredPlayer struct {
+	ID        string `json:"id"`
+	FirstName string `json:"first_name"`
+	Username  string `json:"username"`
+	ImageKey  string `json:"img_key,omitempty"`
+}
+
+// AnswerCount is how many players answered the running question
+type AnswerCount struct {
+	QuestionID uuid.UUID `json:"question_id"`
+	Answered   int       `json:"answered"`
+	Total      int       `json:"total"`
+}
+
+// Moderation reports a kick, ban or rename to the host, Message tells why it failed
+type Moderation struct {
+	UserID  string `json:"user_id"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Renamed tells a player the name the host gave them
+type Renamed struct {
+	Name string `json:"name"`
+}
+
+// LobbyLock tells the host whether players who did not join yet are kept out
+type LobbyLock struct {
+	Locked bool `json:"locked"`
+}
+
+// SubmitAnswer is an answer sent by a player over the socket, the same body the http endpoint takes
+type SubmitAnswer = structs.ReqAnswerSubmit
+
+// ModeratePlayer is a kick, ban or rename sent by the host, Name is only read for a rename
+type ModeratePlayer = structs.ReqModeratePlayer
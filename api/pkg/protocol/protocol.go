@@ -0,0 +1,80 @@
+// Package protocol describes the messages exchanged over the quiz sockets: the join socket of
+// the players, the arrange socket of the host and the read-only spectate socket.
+//
+// Every frame sent by the server is a jsend envelope around a Frame, and every frame sent by a
+// client is a Request. The Data of each event is one of the typed payloads of this package, the
+// events and their payloads are listed in Events and published as a JSON Schema by Schema.
+package protocol
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Improwised/jovvix/api/constants"
+)
+
+// Protocol versions, a client picks one with the VersionParam query parameter when it connects
+const (
+	// V1 is the protocol spoken by clients that do not ask for a version
+	V1 = 1
+	// V2 greets the client with a hello frame and names every event in snake case
+	V2 = 2
+	// V3 sends the host and the spectators the changes of the lobby instead of the whole roster
+	V3 = 3
+
+	Latest       = V3
+	VersionParam = "v"
+)
+
+// renamedInV2 are the events whose v1 name was not snake case
+var renamedInV2 = map[string]string{
+	constants.EventAnswerSubmittedByUser: "answer_submitted",
+}
+
+// ErrUnsupportedVersion is returned by Negotiate for a version this server does not speak
+type ErrUnsupportedVersion struct {
+	Requested string
+}
+
+func (e ErrUnsupportedVersion) Error() string {
+	return fmt.Sprintf("unsupported protocol version %q, supported versions are %d to %d", e.Requested, V1, Latest)
+}
+
+// Negotiate returns the version asked for in the query parameter, V1 when none is asked for
+func Negotiate(requested string) (int, error) {
+	if requested == "" {
+		return V1, nil
+	}
+
+	version, err := strconv.Atoi(requested)
+	if err != nil || version < V1 || version > Latest {
+		return 0, ErrUnsupportedVersion{Requested: requested}
+	}
+
+	return version, nil
+}
+
+// EventName returns how an event is named on the wire in version
+func EventName(version int, event string) string {
+	if version >= V2 {
+		if renamed, ok := renamedInV2[event]; ok {
+			return renamed
+		}
+	}
+
+	return event
+}
+
+// Frame is the data of every server frame: the screen the client shows, what happened and its payload
+type Frame struct {
+	Component string `json:"component"`
+	Action    string `json:"action"`
+	Data      any    `json:"data"`
+}
+
+// Request is a frame sent by a client
+type Request struct {
+	Component string `json:"component"`
+	Event     string `json:"event"`
+	Data      any    `json:"data"`
+}
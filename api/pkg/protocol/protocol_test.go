@@ -0,0 +1,86 @@
+package protocol
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtocol(t *testing.T) {
+	t.Run("negotiate version", func(t *testing.T) {
+		version, err := Negotiate("")
+		assert.Nil(t, err)
+		assert.Equal(t, V1, version)
+
+		version, err = Negotiate("2")
+		assert.Nil(t, err)
+		assert.Equal(t, V2, version)
+
+		version, err = Negotiate("3")
+		assert.Nil(t, err)
+		assert.Equal(t, V3, version)
+
+		_, err = Negotiate("4")
+		assert.NotNil(t, err)
+
+		_, err = Negotiate("latest")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("event names by version", func(t *testing.T) {
+		assert.Equal(t, constants.EventAnswerSubmittedByUser, EventName(V1, constants.EventAnswerSubmittedByUser))
+		assert.Equal(t, "answer_submitted", EventName(V2, constants.EventAnswerSubmittedByUser))
+		assert.Equal(t, constants.EventSendQuestion, EventName(V2, constants.EventSendQuestion))
+	})
+
+	t.Run("hello is only sent from v2", func(t *testing.T) {
+		assert.NotContains(t, EventNames(V1, SocketJoin), constants.EventHello)
+		assert.Contains(t, EventNames(V2, SocketJoin), constants.EventHello)
+		assert.Contains(t, EventNames(V2, SocketSpectate), constants.EventAnswerCount)
+		assert.NotContains(t, EventNames(V2, SocketJoin), constants.EventAnswerCount)
+	})
+
+	t.Run("lobby changes are only sent from v3", func(t *testing.T) {
+		assert.NotContains(t, EventNames(V2, SocketArrange), constants.EventLobbyChanged)
+		assert.Contains(t, EventNames(V3, SocketArrange), constants.EventLobbyChanged)
+		assert.NotContains(t, EventNames(V3, SocketJoin), constants.EventLobbyChanged)
+	})
+
+	t.Run("lookup by wire name", func(t *testing.T) {
+		event, ok := Lookup(V2, ServerToClient, "answer_submitted")
+		assert.True(t, ok)
+		assert.Equal(t, constants.EventAnswerSubmittedByUser, event.Name)
+
+		_, ok = Lookup(V1, ServerToClient, "answer_submitted")
+		assert.False(t, ok)
+
+		_, ok = Lookup(V1, ServerToClient, constants.EventHello)
+		assert.False(t, ok)
+
+		event, ok = Lookup(V2, ClientToServer, constants.EventSubmitAnswer)
+		assert.True(t, ok)
+		assert.Equal(t, ClientToServer, event.Direction)
+	})
+
+	t.Run("every server event has a payload", func(t *testing.T) {
+		for _, event := range Events {
+			if event.Direction == ServerToClient {
+				assert.NotEmpty(t, event.Payloads, event.Name)
+			}
+			assert.NotEmpty(t, event.Sockets, event.Name)
+		}
+	})
+
+	t.Run("committed schema is up to date", func(t *testing.T) {
+		committed, err := os.ReadFile("../../assets/socket-protocol.json")
+		assert.Nil(t, err)
+
+		generated, err := MarshalSchema(Latest)
+		assert.Nil(t, err)
+
+		// run make protocol-gen after changing a message type
+		assert.Equal(t, string(generated), string(committed))
+	})
+}
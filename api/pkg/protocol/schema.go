@@ -0,0 +1,219 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var uuidType = reflect.TypeOf(uuid.UUID{})
+
+// Schema describes the frames of version as a JSON Schema. The payload types are in $defs and
+// the events, with the sockets they are exchanged on, in x-events.
+func Schema(version int) map[string]any {
+	builder := &schemaBuilder{defs: map[string]any{}}
+
+	serverFrames := []any{}
+	clientFrames := []any{}
+	events := []any{}
+
+	for _, event := range Events {
+		if event.Since > version {
+			continue
+		}
+
+		name := EventName(version, event.Name)
+		payload := builder.payload(event.Payloads)
+
+		described := map[string]any{
+			"name":        name,
+			"direction":   event.Direction,
+			"sockets":     event.Sockets,
+			"description": event.Description,
+			"payload":     payload,
+		}
+		if name != event.Name {
+			described["v1_name"] = event.Name
+		}
+		events = append(events, described)
+
+		if event.Direction == ClientToServer {
+			clientFrames = append(clientFrames, map[string]any{
+				"title":    name,
+				"type":     "object",
+				"required": []string{"event"},
+				"properties": map[string]any{
+					"component": map[string]any{"type": "string"},
+					"event":     map[string]any{"const": name},
+					"data":      payload,
+				},
+			})
+			continue
+		}
+
+		data := payload
+		if !event.Bare {
+			data = map[string]any{
+				"type":     "object",
+				"required": []string{"component", "action", "data"},
+				"properties": map[string]any{
+					"component": map[string]any{"type": "string"},
+					"action":    map[string]any{"type": "string"},
+					"data":      payload,
+				},
+			}
+		}
+
+		// the jsend envelope, fail and error frames carry the same data
+		serverFrames = append(serverFrames, map[string]any{
+			"title":    name,
+			"type":     "object",
+			"required": []string{"status", "data"},
+			"properties": map[string]any{
+				"status": map[string]any{"enum": []string{"success", "fail", "error"}},
+				"data": map[string]any{
+					"type":     "object",
+					"required": []string{"event", "data"},
+					"properties": map[string]any{
+						"event": map[string]any{"const": name},
+						"data":  data,
+					},
+				},
+			},
+		})
+	}
+
+	builder.defs["ServerFrame"] = map[string]any{"oneOf": serverFrames}
+	builder.defs["ClientFrame"] = map[string]any{"oneOf": clientFrames}
+
+	versions := []int{}
+	for v := V1; v <= version; v++ {
+		versions = append(versions, v)
+	}
+
+	return map[string]any{
+		"$schema":     jsonSchemaDraft,
+		"title":       "jovvix socket protocol",
+		"description": "Frames of the join, arrange and spectate sockets. A client asks for a version with the v query parameter.",
+		"x-version":   version,
+		"x-versions":  versions,
+		"x-events":    events,
+		"$defs":       builder.defs,
+		"oneOf": []any{
+			map[string]any{"$ref": "#/$defs/ServerFrame"},
+			map[string]any{"$ref": "#/$defs/ClientFrame"},
+		},
+	}
+}
+
+// MarshalSchema is Schema as indented JSON, the way it is committed to the repository
+func MarshalSchema(version int) ([]byte, error) {
+	data, err := json.MarshalIndent(Schema(version), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+type schemaBuilder struct {
+	defs map[string]any
+}
+
+func (b *schemaBuilder) payload(payloads []any) any {
+	switch len(payloads) {
+	case 0:
+		return map[string]any{}
+	case 1:
+		return b.of(reflect.TypeOf(payloads[0]))
+	}
+
+	oneOf := make([]any, 0, len(payloads))
+	for _, payload := range payloads {
+		oneOf = append(oneOf, b.of(reflect.TypeOf(payload)))
+	}
+
+	return map[string]any{"oneOf": oneOf}
+}
+
+func (b *schemaBuilder) of(t reflect.Type) map[string]any {
+	if t == uuidType {
+		return map[string]any{"type": "string", "format": "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return map[string]any{"anyOf": []any{b.of(t.Elem()), map[string]any{"type": "null"}}}
+	case reflect.Struct:
+		if _, ok := b.defs[t.Name()]; !ok {
+			// reserve the name first, a type may refer to itself
+			b.defs[t.Name()] = nil
+			b.defs[t.Name()] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.of(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.of(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+
+	// any: the frame does not constrain it
+	return map[string]any{}
+}
+
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		properties[name] = b.of(field.Type)
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// jsonName reads the json tag of a field the way encoding/json does
+func jsonName(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, strings.Contains(options, "omitempty"), false
+}
@@ -0,0 +1,504 @@
+package pubsub
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired keys are dropped from a Memory, they are never read in between
+const sweepInterval = time.Minute
+
+// subscriptionBuffer is how many messages a subscriber may be behind, further messages are dropped for it
+// like redis drops them for a slow subscriber
+const subscriptionBuffer = 100
+
+type memoryEntry struct {
+	value     string
+	list      []string
+	hash      map[string]string
+	board     map[string]*BoardEntry
+	expiresAt time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Memory is a PubSub held by the process. It only fits a single replica, the other replicas would
+// neither see its keys nor its messages.
+type Memory struct {
+	nodeID string
+
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+
+	subMu       sync.RWMutex
+	subscribers map[string]map[*memorySubscription]struct{}
+}
+
+// NewMemory returns an empty in-memory PubSub
+func NewMemory(nodeID string) *Memory {
+	return &Memory{
+		nodeID:      nodeID,
+		entries:     map[string]*memoryEntry{},
+		lastSweep:   time.Now(),
+		subscribers: map[string]map[*memorySubscription]struct{}{},
+	}
+}
+
+func (m *Memory) NodeID() string {
+	return m.nodeID
+}
+
+func (m *Memory) Publish(channel string, message any) error {
+	m.publish(channel, toString(message))
+	return nil
+}
+
+func (m *Memory) publish(channel string, payload string) {
+	m.subMu.RLock()
+	subscriptions := make([]*memorySubscription, 0, len(m.subscribers[channel]))
+	for subscription := range m.subscribers[channel] {
+		subscriptions = append(subscriptions, subscription)
+	}
+	m.subMu.RUnlock()
+
+	for _, subscription := range subscriptions {
+		subscription.deliver(&Message{Channel: channel, Payload: payload})
+	}
+}
+
+func (m *Memory) Subscribe(channels ...string) (Subscription, error) {
+	subscription := &memorySubscription{
+		memory:   m,
+		channels: channels,
+		ch:       make(chan *Message, subscriptionBuffer),
+	}
+
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+	for _, channel := range channels {
+		if m.subscribers[channel] == nil {
+			m.subscribers[channel] = map[*memorySubscription]struct{}{}
+		}
+		m.subscribers[channel][subscription] = struct{}{}
+	}
+
+	return subscription, nil
+}
+
+func (m *Memory) unsubscribe(subscription *memorySubscription) {
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+	for _, channel := range subscription.channels {
+		delete(m.subscribers[channel], subscription)
+		if len(m.subscribers[channel]) == 0 {
+			delete(m.subscribers, channel)
+		}
+	}
+}
+
+// entry returns the live entry at key, the caller holds mu
+func (m *Memory) entry(key string) *memoryEntry {
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil
+	}
+	if entry.expired(time.Now()) {
+		delete(m.entries, key)
+		return nil
+	}
+	return entry
+}
+
+// put stores entry at key, the caller holds mu
+func (m *Memory) put(key string, entry *memoryEntry) {
+	now := time.Now()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		for k, e := range m.entries {
+			if e.expired(now) {
+				delete(m.entries, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	m.entries[key] = entry
+}
+
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+func (m *Memory) Get(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(key)
+	if entry == nil {
+		return "", ErrNil
+	}
+	return entry.value, nil
+}
+
+func (m *Memory) Set(key string, value any, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(key, &memoryEntry{value: toString(value), expiresAt: expiry(ttl)})
+	return nil
+}
+
+func (m *Memory) Exists(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.entry(key) != nil, nil
+}
+
+func (m *Memory) Del(keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+func (m *Memory) Incr(key string, ttl time.Duration) (int64, error) {
+	return m.add(key, 1, expiry(ttl), true)
+}
+
+func (m *Memory) Decr(key string) (int64, error) {
+	return m.add(key, -1, time.Time{}, false)
+}
+
+func (m *Memory) add(key string, delta int64, expiresAt time.Time, setExpiry bool) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(key)
+	if entry == nil {
+		entry = &memoryEntry{value: "0"}
+		m.put(key, entry)
+	}
+
+	count, err := strconv.ParseInt(entry.value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	count += delta
+
+	entry.value = strconv.FormatInt(count, 10)
+	if setExpiry {
+		entry.expiresAt = expiresAt
+	}
+	return count, nil
+}
+
+func (m *Memory) PushPublish(key string, channel string, message any, ttl time.Duration) error {
+	payload := toString(message)
+
+	m.mu.Lock()
+	entry := m.entry(key)
+	if entry == nil {
+		entry = &memoryEntry{}
+		m.put(key, entry)
+	}
+	entry.list = append(entry.list, payload)
+	entry.expiresAt = expiry(ttl)
+	m.mu.Unlock()
+
+	m.publish(channel, payload)
+	return nil
+}
+
+func (m *Memory) Range(key string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(key)
+	if entry == nil {
+		return []string{}, nil
+	}
+	return append([]string{}, entry.list...), nil
+}
+
+func (m *Memory) HSetNX(key string, field string, value any, ttl time.Duration) (bool, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(key)
+	if entry == nil {
+		entry = &memoryEntry{hash: map[string]string{}}
+		m.put(key, entry)
+	}
+	entry.expiresAt = expiry(ttl)
+
+	if _, ok := entry.hash[field]; ok {
+		return false, int64(len(entry.hash)), nil
+	}
+	entry.hash[field] = toString(value)
+	return true, int64(len(entry.hash)), nil
+}
+
+func (m *Memory) HSetXX(key string, field string, value any) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(key)
+	if entry == nil {
+		return false, nil
+	}
+	if _, ok := entry.hash[field]; !ok {
+		return false, nil
+	}
+	entry.hash[field] = toString(value)
+	return true, nil
+}
+
+func (m *Memory) HGet(key string, field string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry := m.entry(key); entry != nil {
+		if value, ok := entry.hash[field]; ok {
+			return value, nil
+		}
+	}
+	return "", ErrNil
+}
+
+func (m *Memory) HGetAll(key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash := map[string]string{}
+	if entry := m.entry(key); entry != nil {
+		for field, value := range entry.hash {
+			hash[field] = value
+		}
+	}
+	return hash, nil
+}
+
+func (m *Memory) HDel(key string, fields ...string) (int64, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(key)
+	if entry == nil {
+		return 0, 0, nil
+	}
+
+	var deleted int64
+	for _, field := range fields {
+		if _, ok := entry.hash[field]; ok {
+			delete(entry.hash, field)
+			deleted++
+		}
+	}
+	// like redis, an empty hash does not exist
+	if len(entry.hash) == 0 {
+		delete(m.entries, key)
+	}
+	return deleted, int64(len(entry.hash)), nil
+}
+
+func (m *Memory) HLen(key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry := m.entry(key); entry != nil {
+		return int64(len(entry.hash)), nil
+	}
+	return 0, nil
+}
+
+func (m *Memory) ScoreOnBoard(key string, member string, score int64, points int64, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(key)
+	if entry == nil {
+		entry = &memoryEntry{board: map[string]*BoardEntry{}}
+		m.put(key, entry)
+	}
+	entry.expiresAt = expiry(ttl)
+
+	totals, ok := entry.board[member]
+	if !ok {
+		totals = &BoardEntry{Member: member}
+		entry.board[member] = totals
+	}
+	totals.Score += score
+	totals.Points += points
+	return nil
+}
+
+func (m *Memory) JoinBoard(key string, member string, score int64, points int64, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(key)
+	if entry == nil {
+		entry = &memoryEntry{board: map[string]*BoardEntry{}}
+		m.put(key, entry)
+	}
+	entry.expiresAt = expiry(ttl)
+
+	if _, ok := entry.board[member]; !ok {
+		entry.board[member] = &BoardEntry{Member: member, Score: score, Points: points}
+	}
+	return nil
+}
+
+func (m *Memory) LeaveBoard(key string, member string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(key)
+	if entry == nil {
+		return nil
+	}
+
+	delete(entry.board, member)
+	// like a sorted set, an empty board does not exist
+	if len(entry.board) == 0 {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+func (m *Memory) ReplaceBoard(key string, entries []BoardEntry, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(entries) == 0 {
+		delete(m.entries, key)
+		return nil
+	}
+
+	board := make(map[string]*BoardEntry, len(entries))
+	for _, entry := range entries {
+		board[entry.Member] = &BoardEntry{Member: entry.Member, Score: entry.Score, Points: entry.Points}
+	}
+	m.put(key, &memoryEntry{board: board, expiresAt: expiry(ttl)})
+	return nil
+}
+
+func (m *Memory) Board(key string) ([]BoardEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := []BoardEntry{}
+	if entry := m.entry(key); entry != nil {
+		for _, totals := range entry.board {
+			entries = append(entries, *totals)
+		}
+	}
+	// like a sorted set, members with the same score are ordered by member
+	slices.SortFunc(entries, func(a, b BoardEntry) int {
+		if a.Score != b.Score {
+			return cmp.Compare(b.Score, a.Score)
+		}
+		return strings.Compare(b.Member, a.Member)
+	})
+	return entries, nil
+}
+
+func (m *Memory) DeleteBoard(key string) error {
+	return m.Del(key)
+}
+
+func (m *Memory) AcquireLease(key string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entry(key) != nil {
+		return false, nil
+	}
+	m.put(key, &memoryEntry{value: m.nodeID, expiresAt: expiry(ttl)})
+	return true, nil
+}
+
+func (m *Memory) RenewLease(key string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(key)
+	if entry == nil || entry.value != m.nodeID {
+		return false, nil
+	}
+	entry.expiresAt = expiry(ttl)
+	return true, nil
+}
+
+func (m *Memory) ReleaseLease(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry := m.entry(key); entry != nil && entry.value == m.nodeID {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+func (m *Memory) LeaseOwner(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry := m.entry(key); entry != nil {
+		return entry.value, nil
+	}
+	return "", nil
+}
+
+// memorySubscription is a subscription to a Memory. Publishing never waits for it, a message is dropped
+// for a subscriber whose buffer is full so one stalled subscriber cannot hold up the others.
+type memorySubscription struct {
+	memory   *Memory
+	channels []string
+
+	mu        sync.RWMutex
+	closed    bool
+	ch        chan *Message
+	closeOnce sync.Once
+}
+
+func (s *memorySubscription) deliver(message *Message) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- message:
+	default:
+	}
+}
+
+func (s *memorySubscription) Channel() <-chan *Message {
+	return s.ch
+}
+
+func (s *memorySubscription) Close() error {
+	s.closeOnce.Do(func() {
+		s.memory.unsubscribe(s)
+
+		s.mu.Lock()
+		s.closed = true
+		close(s.ch)
+		s.mu.Unlock()
+	})
+	return nil
+}
@@ -0,0 +1,327 @@
+package pubsub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(t *testing.T, subscription Subscription) *Message {
+	select {
+	case msg := <-subscription.Channel():
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("message was not received")
+		return nil
+	}
+}
+
+func TestMemory(t *testing.T) {
+	memory := NewMemory("node-a")
+
+	t.Run("keys", func(t *testing.T) {
+		_, err := memory.Get("roster")
+		assert.Equal(t, ErrNil, err)
+
+		assert.Nil(t, memory.Set("roster", []byte(`[]`), time.Minute))
+		value, err := memory.Get("roster")
+		assert.Nil(t, err)
+		assert.Equal(t, "[]", value)
+
+		exists, err := memory.Exists("roster")
+		assert.Nil(t, err)
+		assert.True(t, exists)
+
+		assert.Nil(t, memory.Del("roster"))
+		exists, err = memory.Exists("roster")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("keys expire", func(t *testing.T) {
+		assert.Nil(t, memory.Set("token", "1", time.Millisecond))
+		time.Sleep(5 * time.Millisecond)
+
+		_, err := memory.Get("token")
+		assert.Equal(t, ErrNil, err)
+	})
+
+	t.Run("counters", func(t *testing.T) {
+		count, err := memory.Incr("count", time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+
+		count, err = memory.Incr("count", time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = memory.Decr("count")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("publish reaches the subscribers of the channel", func(t *testing.T) {
+		first, err := memory.Subscribe("a", "b")
+		assert.Nil(t, err)
+		defer first.Close()
+		second, err := memory.Subscribe("b")
+		assert.Nil(t, err)
+		defer second.Close()
+
+		assert.Nil(t, memory.Publish("a", "one"))
+		assert.Nil(t, memory.Publish("b", []byte("two")))
+
+		assert.Equal(t, &Message{Channel: "a", Payload: "one"}, receive(t, first))
+		assert.Equal(t, &Message{Channel: "b", Payload: "two"}, receive(t, first))
+		assert.Equal(t, &Message{Channel: "b", Payload: "two"}, receive(t, second))
+	})
+
+	t.Run("closed subscription", func(t *testing.T) {
+		subscription, err := memory.Subscribe("c")
+		assert.Nil(t, err)
+
+		// the subscriber is behind, publishing must not block once it is closed
+		for i := 0; i < subscriptionBuffer; i++ {
+			assert.Nil(t, memory.Publish("c", i))
+		}
+		published := make(chan struct{})
+		go func() {
+			_ = memory.Publish("c", "late")
+			close(published)
+		}()
+
+		assert.Nil(t, subscription.Close())
+		select {
+		case <-published:
+		case <-time.After(time.Second):
+			t.Fatal("publish is blocked by a closed subscription")
+		}
+
+		count := 0
+		for range subscription.Channel() {
+			count++
+		}
+		assert.LessOrEqual(t, count, subscriptionBuffer)
+		assert.Nil(t, memory.Publish("c", "after"))
+	})
+
+	t.Run("stalled subscriber", func(t *testing.T) {
+		stalled, err := memory.Subscribe("s")
+		assert.Nil(t, err)
+		defer stalled.Close()
+		reader, err := memory.Subscribe("s")
+		assert.Nil(t, err)
+		defer reader.Close()
+
+		// nobody reads the stalled subscription, publishing past its buffer must not block
+		published := make(chan struct{})
+		go func() {
+			for i := 0; i < subscriptionBuffer+10; i++ {
+				_ = memory.Publish("s", i)
+			}
+			close(published)
+		}()
+		select {
+		case <-published:
+		case <-time.After(time.Second):
+			t.Fatal("publish is blocked by a stalled subscriber")
+		}
+
+		assert.Equal(t, "0", receive(t, stalled).Payload)
+		assert.Len(t, stalled.Channel(), subscriptionBuffer-1)
+		assert.Equal(t, "0", receive(t, reader).Payload)
+	})
+
+	t.Run("push publish keeps the list", func(t *testing.T) {
+		subscription, err := memory.Subscribe("events")
+		assert.Nil(t, err)
+		defer subscription.Close()
+
+		assert.Nil(t, memory.PushPublish("replay", "events", "one", time.Minute))
+		assert.Nil(t, memory.PushPublish("replay", "events", "two", time.Minute))
+
+		items, err := memory.Range("replay")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"one", "two"}, items)
+		assert.Equal(t, "one", receive(t, subscription).Payload)
+		assert.Equal(t, "two", receive(t, subscription).Payload)
+
+		assert.Nil(t, memory.Del("replay"))
+		items, err = memory.Range("replay")
+		assert.Nil(t, err)
+		assert.Empty(t, items)
+	})
+
+	t.Run("hashes", func(t *testing.T) {
+		set, length, err := memory.HSetNX("roster", "u1", "alice", time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, set)
+		assert.Equal(t, int64(1), length)
+
+		set, length, err = memory.HSetNX("roster", "u1", "bob", time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, set)
+		assert.Equal(t, int64(1), length)
+
+		set, err = memory.HSetXX("roster", "u2", "carol")
+		assert.Nil(t, err)
+		assert.False(t, set)
+
+		set, err = memory.HSetXX("roster", "u1", "alicia")
+		assert.Nil(t, err)
+		assert.True(t, set)
+
+		value, err := memory.HGet("roster", "u1")
+		assert.Nil(t, err)
+		assert.Equal(t, "alicia", value)
+		_, err = memory.HGet("roster", "u2")
+		assert.Equal(t, ErrNil, err)
+
+		_, length, err = memory.HSetNX("roster", "u2", "carol", time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), length)
+		hash, err := memory.HGetAll("roster")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"u1": "alicia", "u2": "carol"}, hash)
+
+		size, err := memory.HLen("roster")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), size)
+
+		deleted, length, err := memory.HDel("roster", "u1", "u3")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), deleted)
+		assert.Equal(t, int64(1), length)
+
+		deleted, length, err = memory.HDel("roster", "u2")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), deleted)
+		assert.Equal(t, int64(0), length)
+		exists, err := memory.Exists("roster")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("leaderboard", func(t *testing.T) {
+		assert.Nil(t, memory.ScoreOnBoard("board", "u1", 100, 1, time.Minute))
+		assert.Nil(t, memory.ScoreOnBoard("board", "u2", 250, 1, time.Minute))
+		assert.Nil(t, memory.ScoreOnBoard("board", "u3", 0, 0, time.Minute))
+		assert.Nil(t, memory.ScoreOnBoard("board", "u1", 200, 1, time.Minute))
+
+		board, err := memory.Board("board")
+		assert.Nil(t, err)
+		assert.Equal(t, []BoardEntry{
+			{Member: "u1", Score: 300, Points: 2},
+			{Member: "u2", Score: 250, Points: 1},
+			{Member: "u3", Score: 0, Points: 0},
+		}, board)
+
+		assert.Nil(t, memory.DeleteBoard("board"))
+		board, err = memory.Board("board")
+		assert.Nil(t, err)
+		assert.Empty(t, board)
+	})
+
+	t.Run("leaderboard members", func(t *testing.T) {
+		assert.Nil(t, memory.JoinBoard("board", "u1", 0, 0, time.Minute))
+		assert.Nil(t, memory.ScoreOnBoard("board", "u1", 100, 1, time.Minute))
+		assert.Nil(t, memory.JoinBoard("board", "u1", 0, 0, time.Minute))
+		assert.Nil(t, memory.JoinBoard("board", "u2", 0, 0, time.Minute))
+
+		board, err := memory.Board("board")
+		assert.Nil(t, err)
+		assert.Equal(t, []BoardEntry{
+			{Member: "u1", Score: 100, Points: 1},
+			{Member: "u2", Score: 0, Points: 0},
+		}, board)
+
+		assert.Nil(t, memory.LeaveBoard("board", "u1"))
+		assert.Nil(t, memory.LeaveBoard("missing", "u1"))
+		board, err = memory.Board("board")
+		assert.Nil(t, err)
+		assert.Equal(t, []BoardEntry{{Member: "u2", Score: 0, Points: 0}}, board)
+
+		assert.Nil(t, memory.LeaveBoard("board", "u2"))
+		exists, err := memory.Exists("board")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("credited late join", func(t *testing.T) {
+		assert.Nil(t, memory.ScoreOnBoard("board", "u1", 900, 2, time.Minute))
+		// a late joiner starts from the score credited for the questions they missed
+		assert.Nil(t, memory.JoinBoard("board", "u2", 450, 0, time.Minute))
+		assert.Nil(t, memory.ScoreOnBoard("board", "u2", 600, 1, time.Minute))
+		// joining again, on a rejoin, credits nothing more
+		assert.Nil(t, memory.JoinBoard("board", "u2", 450, 0, time.Minute))
+
+		board, err := memory.Board("board")
+		assert.Nil(t, err)
+		assert.Equal(t, []BoardEntry{
+			{Member: "u2", Score: 1050, Points: 1},
+			{Member: "u1", Score: 900, Points: 2},
+		}, board)
+		assert.Nil(t, memory.DeleteBoard("board"))
+	})
+
+	t.Run("replace leaderboard", func(t *testing.T) {
+		assert.Nil(t, memory.ScoreOnBoard("board", "u1", 100, 1, time.Minute))
+		entries := []BoardEntry{{Member: "u2", Score: 300, Points: 2}, {Member: "u3", Score: 50, Points: 1}}
+		assert.Nil(t, memory.ReplaceBoard("board", entries, time.Minute))
+
+		// the board keeps its own copy of the entries
+		entries[0].Score = 0
+		board, err := memory.Board("board")
+		assert.Nil(t, err)
+		assert.Equal(t, []BoardEntry{
+			{Member: "u2", Score: 300, Points: 2},
+			{Member: "u3", Score: 50, Points: 1},
+		}, board)
+
+		assert.Nil(t, memory.ReplaceBoard("board", nil, time.Minute))
+		board, err = memory.Board("board")
+		assert.Nil(t, err)
+		assert.Empty(t, board)
+	})
+
+	t.Run("leases", func(t *testing.T) {
+		acquired, err := memory.AcquireLease("lease", time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, acquired)
+
+		owner, err := memory.LeaseOwner("lease")
+		assert.Nil(t, err)
+		assert.Equal(t, "node-a", owner)
+
+		renewed, err := memory.RenewLease("lease", time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, renewed)
+
+		held, err := HoldLease(memory, "lease", time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, held)
+
+		assert.Nil(t, memory.ReleaseLease("lease"))
+		owner, err = memory.LeaseOwner("lease")
+		assert.Nil(t, err)
+		assert.Empty(t, owner)
+	})
+
+	t.Run("lease held by another node", func(t *testing.T) {
+		assert.Nil(t, memory.Set("taken", "node-b", time.Minute))
+
+		acquired, err := memory.AcquireLease("taken", time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, acquired)
+
+		held, err := HoldLease(memory, "taken", time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, held)
+
+		assert.Nil(t, memory.ReleaseLease("taken"))
+		owner, err := memory.LeaseOwner("taken")
+		assert.Nil(t, err)
+		assert.Equal(t, "node-b", owner)
+	})
+}
@@ -0,0 +1,159 @@
+// Package pubsub is the shared state of the quiz engine: the channels the replicas talk over, the
+// session rosters and the leases deciding which replica drives a session. It is backed by redis, or
+// held in memory for a single node deployment and for tests.
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Improwised/jovvix/api/config"
+	"github.com/rs/xid"
+	"go.uber.org/zap"
+)
+
+const (
+	REDIS  = "redis"
+	MEMORY = "memory"
+)
+
+// ErrNil is returned by Get for a key that does not exist
+var ErrNil = errors.New("pubsub: key does not exist")
+
+// Message is a payload published on a channel
+type Message struct {
+	Channel string
+	Payload string
+}
+
+// BoardEntry is the totals of a member of a leaderboard
+type BoardEntry struct {
+	Member string
+	Score  int64
+	Points int64
+}
+
+// Subscription receives the messages of the channels it was opened for
+type Subscription interface {
+	// Channel returns the messages, it is closed with the subscription
+	Channel() <-chan *Message
+	// Close unsubscribes from every channel
+	Close() error
+}
+
+// PubSub is the set of operations the quiz engine runs against its shared state. Values are
+// strings, []byte or numbers, they are read back as strings.
+type PubSub interface {
+	Publish(channel string, message any) error
+	// Subscribe returns once the subscription is active, nothing published afterwards is missed unless
+	// the subscriber falls too far behind, then messages are dropped for it rather than holding up publishing
+	Subscribe(channels ...string) (Subscription, error)
+
+	// Get returns ErrNil when the key does not exist
+	Get(key string) (string, error)
+	Set(key string, value any, ttl time.Duration) error
+	Exists(key string) (bool, error)
+	Del(keys ...string) error
+
+	// Incr increments the counter at key and makes it live for ttl
+	Incr(key string, ttl time.Duration) (int64, error)
+	Decr(key string) (int64, error)
+
+	// PushPublish appends message to the list at key and publishes it on channel at once,
+	// the list lives for ttl
+	PushPublish(key string, channel string, message any, ttl time.Duration) error
+	// Range returns the items of the list at key
+	Range(key string) ([]string, error)
+
+	// HSetNX sets field of the hash at key unless it is set already, the hash lives for ttl.
+	// It returns whether the field was set and the length of the hash afterwards.
+	HSetNX(key string, field string, value any, ttl time.Duration) (bool, int64, error)
+	// HSetXX sets field of the hash at key only if it is set already
+	HSetXX(key string, field string, value any) (bool, error)
+	// HGet returns ErrNil when the field is not set
+	HGet(key string, field string) (string, error)
+	HGetAll(key string) (map[string]string, error)
+	// HDel returns how many of fields were set and the length of the hash afterwards
+	HDel(key string, fields ...string) (int64, int64, error)
+	HLen(key string) (int64, error)
+
+	// ScoreOnBoard adds score and points to the totals of member in the leaderboard at key, both at
+	// once. The leaderboard lives for ttl.
+	ScoreOnBoard(key string, member string, score int64, points int64, ttl time.Duration) error
+	// JoinBoard puts member on the leaderboard at key starting from score and points, a member already on
+	// it keeps their totals
+	JoinBoard(key string, member string, score int64, points int64, ttl time.Duration) error
+	// LeaveBoard takes member and their totals off the leaderboard at key
+	LeaveBoard(key string, member string) error
+	// ReplaceBoard swaps the leaderboard at key for entries at once, a reader never sees it half built
+	ReplaceBoard(key string, entries []BoardEntry, ttl time.Duration) error
+	// Board returns the totals of the leaderboard at key, the highest score first
+	Board(key string) ([]BoardEntry, error)
+	DeleteBoard(key string) error
+
+	// AcquireLease makes this node the owner of key for ttl if nobody else holds it
+	AcquireLease(key string, ttl time.Duration) (bool, error)
+	// RenewLease extends a lease held by this node, it reports false once the lease was lost
+	RenewLease(key string, ttl time.Duration) (bool, error)
+	// ReleaseLease gives up a lease held by this node
+	ReleaseLease(key string) error
+	// LeaseOwner returns the node holding key, or an empty string if the lease is free
+	LeaseOwner(key string) (string, error)
+
+	// NodeID identifies this replica as the owner of leases
+	NodeID() string
+}
+
+// New connects to the backend set by PUBSUB_BACKEND, redis when it is not set
+func New(cfg config.AppConfig, logger *zap.Logger) (PubSub, error) {
+	switch cfg.PubSubBackend {
+	case REDIS, "":
+		return NewRedis(cfg.RedisClient, nodeID(logger))
+	case MEMORY:
+		return NewMemory(nodeID(logger)), nil
+	default:
+		return nil, fmt.Errorf("unknown pubsub backend %q", cfg.PubSubBackend)
+	}
+}
+
+// HoldLease keeps or takes the lease on key, it reports whether this node is the owner
+func HoldLease(p PubSub, key string, ttl time.Duration) (bool, error) {
+	renewed, err := p.RenewLease(key, ttl)
+	if err != nil || renewed {
+		return renewed, err
+	}
+	return p.AcquireLease(key, ttl)
+}
+
+func nodeID(logger *zap.Logger) string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		logger.Warn("unable to read hostname for pubsub node id", zap.Error(err))
+		hostname = "node"
+	}
+	return fmt.Sprintf("%s-%s", hostname, xid.New().String())
+}
+
+// toString formats a value the way redis stores it
+func toString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,313 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Improwised/jovvix/api/config"
+	redis "github.com/redis/go-redis/v9"
+)
+
+// renew/release only touch a lease that is still held by the caller
+var renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+var hsetxxScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+	redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// Redis is the PubSub shared by every replica of the api
+type Redis struct {
+	ctx    context.Context
+	client *redis.Client
+	nodeID string
+}
+
+// NewRedis connects to the redis server of cfg
+func NewRedis(cfg config.RedisClientConfig, nodeID string) (*Redis, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:             cfg.RedisAddr + ":" + cfg.RedisPort,
+		Password:         cfg.RedisPass,
+		DB:               cfg.RedisDb,
+		DisableIndentity: true,
+	})
+	ctx := context.Background()
+
+	_, err := client.Ping(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Redis{
+		ctx:    ctx,
+		client: client,
+		nodeID: nodeID,
+	}, nil
+}
+
+func (r *Redis) NodeID() string {
+	return r.nodeID
+}
+
+func (r *Redis) Publish(channel string, message any) error {
+	return r.client.Publish(r.ctx, channel, message).Err()
+}
+
+func (r *Redis) Subscribe(channels ...string) (Subscription, error) {
+	pubsub := r.client.Subscribe(r.ctx, channels...)
+	if _, err := pubsub.Receive(r.ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	subscription := &redisSubscription{
+		pubsub: pubsub,
+		ch:     make(chan *Message),
+		done:   make(chan struct{}),
+	}
+	go subscription.forward()
+
+	return subscription, nil
+}
+
+func (r *Redis) Get(key string) (string, error) {
+	value, err := r.client.Get(r.ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNil
+	}
+	return value, err
+}
+
+func (r *Redis) Set(key string, value any, ttl time.Duration) error {
+	return r.client.Set(r.ctx, key, value, ttl).Err()
+}
+
+func (r *Redis) Exists(key string) (bool, error) {
+	count, err := r.client.Exists(r.ctx, key).Result()
+	return count > 0, err
+}
+
+func (r *Redis) Del(keys ...string) error {
+	return r.client.Del(r.ctx, keys...).Err()
+}
+
+func (r *Redis) Incr(key string, ttl time.Duration) (int64, error) {
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(r.ctx, key)
+	pipe.Expire(r.ctx, key, ttl)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (r *Redis) Decr(key string) (int64, error) {
+	return r.client.Decr(r.ctx, key).Result()
+}
+
+func (r *Redis) PushPublish(key string, channel string, message any, ttl time.Duration) error {
+	pipe := r.client.TxPipeline()
+	pipe.RPush(r.ctx, key, message)
+	pipe.Expire(r.ctx, key, ttl)
+	pipe.Publish(r.ctx, channel, message)
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+func (r *Redis) Range(key string) ([]string, error) {
+	return r.client.LRange(r.ctx, key, 0, -1).Result()
+}
+
+func (r *Redis) HSetNX(key string, field string, value any, ttl time.Duration) (bool, int64, error) {
+	pipe := r.client.TxPipeline()
+	set := pipe.HSetNX(r.ctx, key, field, value)
+	pipe.Expire(r.ctx, key, ttl)
+	length := pipe.HLen(r.ctx, key)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return false, 0, err
+	}
+	return set.Val(), length.Val(), nil
+}
+
+func (r *Redis) HSetXX(key string, field string, value any) (bool, error) {
+	set, err := hsetxxScript.Run(r.ctx, r.client, []string{key}, field, value).Int()
+	if err != nil {
+		return false, err
+	}
+	return set == 1, nil
+}
+
+func (r *Redis) HGet(key string, field string) (string, error) {
+	value, err := r.client.HGet(r.ctx, key, field).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNil
+	}
+	return value, err
+}
+
+func (r *Redis) HGetAll(key string) (map[string]string, error) {
+	return r.client.HGetAll(r.ctx, key).Result()
+}
+
+func (r *Redis) HDel(key string, fields ...string) (int64, int64, error) {
+	pipe := r.client.TxPipeline()
+	deleted := pipe.HDel(r.ctx, key, fields...)
+	length := pipe.HLen(r.ctx, key)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return 0, 0, err
+	}
+	return deleted.Val(), length.Val(), nil
+}
+
+func (r *Redis) HLen(key string) (int64, error) {
+	return r.client.HLen(r.ctx, key).Result()
+}
+
+// boardPointsKey is the hash of the points of a leaderboard, the scores are in the sorted set at key
+func boardPointsKey(key string) string {
+	return key + ":points"
+}
+
+func (r *Redis) ScoreOnBoard(key string, member string, score int64, points int64, ttl time.Duration) error {
+	pipe := r.client.TxPipeline()
+	pipe.ZIncrBy(r.ctx, key, float64(score), member)
+	pipe.HIncrBy(r.ctx, boardPointsKey(key), member, points)
+	pipe.Expire(r.ctx, key, ttl)
+	pipe.Expire(r.ctx, boardPointsKey(key), ttl)
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+func (r *Redis) JoinBoard(key string, member string, score int64, points int64, ttl time.Duration) error {
+	pipe := r.client.TxPipeline()
+	pipe.ZAddNX(r.ctx, key, redis.Z{Score: float64(score), Member: member})
+	pipe.HSetNX(r.ctx, boardPointsKey(key), member, points)
+	pipe.Expire(r.ctx, key, ttl)
+	pipe.Expire(r.ctx, boardPointsKey(key), ttl)
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+func (r *Redis) LeaveBoard(key string, member string) error {
+	pipe := r.client.TxPipeline()
+	pipe.ZRem(r.ctx, key, member)
+	pipe.HDel(r.ctx, boardPointsKey(key), member)
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// ReplaceBoard runs in a single MULTI, the answers scored meanwhile wait for it and land on the new board
+func (r *Redis) ReplaceBoard(key string, entries []BoardEntry, ttl time.Duration) error {
+	pipe := r.client.TxPipeline()
+	pipe.Del(r.ctx, key, boardPointsKey(key))
+	if len(entries) > 0 {
+		scores := make([]redis.Z, 0, len(entries))
+		points := make(map[string]any, len(entries))
+		for _, entry := range entries {
+			scores = append(scores, redis.Z{Score: float64(entry.Score), Member: entry.Member})
+			points[entry.Member] = entry.Points
+		}
+		pipe.ZAdd(r.ctx, key, scores...)
+		pipe.HSet(r.ctx, boardPointsKey(key), points)
+		pipe.Expire(r.ctx, key, ttl)
+		pipe.Expire(r.ctx, boardPointsKey(key), ttl)
+	}
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+func (r *Redis) Board(key string) ([]BoardEntry, error) {
+	pipe := r.client.TxPipeline()
+	scores := pipe.ZRevRangeWithScores(r.ctx, key, 0, -1)
+	points := pipe.HGetAll(r.ctx, boardPointsKey(key))
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return nil, err
+	}
+
+	entries := make([]BoardEntry, 0, len(scores.Val()))
+	for _, z := range scores.Val() {
+		member, _ := z.Member.(string)
+		memberPoints, _ := strconv.ParseInt(points.Val()[member], 10, 64)
+		entries = append(entries, BoardEntry{Member: member, Score: int64(z.Score), Points: memberPoints})
+	}
+	return entries, nil
+}
+
+func (r *Redis) DeleteBoard(key string) error {
+	return r.client.Del(r.ctx, key, boardPointsKey(key)).Err()
+}
+
+func (r *Redis) AcquireLease(key string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(r.ctx, key, r.nodeID, ttl).Result()
+}
+
+func (r *Redis) RenewLease(key string, ttl time.Duration) (bool, error) {
+	renewed, err := renewLeaseScript.Run(r.ctx, r.client, []string{key}, r.nodeID, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return renewed == 1, nil
+}
+
+func (r *Redis) ReleaseLease(key string) error {
+	return releaseLeaseScript.Run(r.ctx, r.client, []string{key}, r.nodeID).Err()
+}
+
+func (r *Redis) LeaseOwner(key string) (string, error) {
+	owner, err := r.Get(key)
+	if err == ErrNil {
+		return "", nil
+	}
+	return owner, err
+}
+
+// redisSubscription hands the messages of a redis subscription over as Messages
+type redisSubscription struct {
+	pubsub    *redis.PubSub
+	ch        chan *Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *redisSubscription) forward() {
+	defer close(s.ch)
+
+	for msg := range s.pubsub.Channel() {
+		select {
+		case s.ch <- &Message{Channel: msg.Channel, Payload: msg.Payload}:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *redisSubscription) Channel() <-chan *Message {
+	return s.ch
+}
+
+func (s *redisSubscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.pubsub.Close()
+	})
+	return err
+}
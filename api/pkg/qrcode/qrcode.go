@@ -0,0 +1,509 @@
+// Package qrcode encodes text into a QR code (ISO/IEC 18004) and draws it as PNG or SVG. It only knows
+// what a join link needs: byte mode, error correction level M and versions 1 to 10, that is up to 213
+// bytes of text.
+package qrcode
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrTooLong is returned for text that does not fit in the largest version
+var ErrTooLong = errors.New("qrcode: text is too long")
+
+// QuietZone is the light border around the symbol, in modules
+const QuietZone = 4
+
+const (
+	minVersion = 1
+	maxVersion = 10
+
+	// mode indicator of byte mode
+	modeByte = 0x4
+	// level M, as written in the format information
+	levelM = 0x0
+)
+
+// blockLayout is how the codewords of a version are split into blocks at level M
+type blockLayout struct {
+	ecPerBlock int
+	// shortBlocks hold shortData data codewords, longBlocks one more
+	shortBlocks int
+	shortData   int
+	longBlocks  int
+}
+
+var layouts = [maxVersion + 1]blockLayout{
+	1:  {ecPerBlock: 10, shortBlocks: 1, shortData: 16},
+	2:  {ecPerBlock: 16, shortBlocks: 1, shortData: 28},
+	3:  {ecPerBlock: 26, shortBlocks: 1, shortData: 44},
+	4:  {ecPerBlock: 18, shortBlocks: 2, shortData: 32},
+	5:  {ecPerBlock: 24, shortBlocks: 2, shortData: 43},
+	6:  {ecPerBlock: 16, shortBlocks: 4, shortData: 27},
+	7:  {ecPerBlock: 18, shortBlocks: 4, shortData: 31},
+	8:  {ecPerBlock: 22, shortBlocks: 2, shortData: 38, longBlocks: 2},
+	9:  {ecPerBlock: 22, shortBlocks: 3, shortData: 36, longBlocks: 2},
+	10: {ecPerBlock: 26, shortBlocks: 4, shortData: 43, longBlocks: 1},
+}
+
+func (l blockLayout) dataCodewords() int {
+	return l.shortBlocks*l.shortData + l.longBlocks*(l.shortData+1)
+}
+
+// alignmentCenters are the rows and columns of the alignment patterns of each version
+var alignmentCenters = [maxVersion + 1][]int{
+	2:  {6, 18},
+	3:  {6, 22},
+	4:  {6, 26},
+	5:  {6, 30},
+	6:  {6, 34},
+	7:  {6, 22, 38},
+	8:  {6, 24, 42},
+	9:  {6, 26, 46},
+	10: {6, 28, 50},
+}
+
+// Code is an encoded symbol, without its quiet zone
+type Code struct {
+	Version int
+	Mask    int
+	Size    int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Dark reports whether the module at column x and row y is dark
+func (q *Code) Dark(x, y int) bool {
+	return q.modules[y][x]
+}
+
+// Encode returns the smallest symbol holding text, with the mask of lowest penalty
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+
+	version := 0
+	for v := minVersion; v <= maxVersion; v++ {
+		if 4+countBits(v)+len(data)*8 <= layouts[v].dataCodewords()*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	size := version*4 + 17
+	q := &Code{Version: version, Size: size, modules: grid(size), isFunction: grid(size)}
+	q.drawFunctionPatterns()
+	q.drawCodewords(addErrorCorrection(version, dataCodewords(version, data)))
+
+	bestPenalty := math.MaxInt
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); penalty < bestPenalty {
+			bestPenalty = penalty
+			q.Mask = mask
+		}
+		// masking twice restores the modules
+		q.applyMask(mask)
+	}
+	q.applyMask(q.Mask)
+	q.drawFormatBits(q.Mask)
+
+	return q, nil
+}
+
+func grid(size int) [][]bool {
+	rows := make([][]bool, size)
+	for y := range rows {
+		rows[y] = make([]bool, size)
+	}
+	return rows
+}
+
+// countBits is the length of the character count of byte mode in version
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// dataCodewords writes text in byte mode and pads it to the data capacity of version
+func dataCodewords(version int, text []byte) []byte {
+	capacity := layouts[version].dataCodewords()
+	bits := &bitBuffer{}
+
+	bits.append(modeByte, 4)
+	bits.append(len(text), countBits(version))
+	for _, b := range text {
+		bits.append(int(b), 8)
+	}
+
+	// terminator, then zeros up to a whole codeword
+	bits.append(0, min(4, capacity*8-bits.length))
+	bits.append(0, (8-bits.length%8)%8)
+
+	codewords := bits.bytes()
+	for pad := byte(0xEC); len(codewords) < capacity; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+	return codewords
+}
+
+type bitBuffer struct {
+	data   []byte
+	length int
+}
+
+func (b *bitBuffer) append(value int, count int) {
+	for i := count - 1; i >= 0; i-- {
+		if b.length%8 == 0 {
+			b.data = append(b.data, 0)
+		}
+		if (value>>i)&1 == 1 {
+			b.data[b.length/8] |= 0x80 >> (b.length % 8)
+		}
+		b.length++
+	}
+}
+
+func (b *bitBuffer) bytes() []byte {
+	return b.data
+}
+
+// addErrorCorrection splits data into the blocks of version and interleaves them with their error
+// correction codewords
+func addErrorCorrection(version int, data []byte) []byte {
+	layout := layouts[version]
+	divisor := reedSolomonDivisor(layout.ecPerBlock)
+
+	blocks := [][]byte{}
+	ecBlocks := [][]byte{}
+	offset := 0
+	for i := 0; i < layout.shortBlocks+layout.longBlocks; i++ {
+		length := layout.shortData
+		if i >= layout.shortBlocks {
+			length++
+		}
+		block := data[offset : offset+length]
+		offset += length
+
+		blocks = append(blocks, block)
+		ecBlocks = append(ecBlocks, reedSolomonRemainder(block, divisor))
+	}
+
+	result := make([]byte, 0, len(data)+len(blocks)*layout.ecPerBlock)
+	for i := 0; i <= layout.shortData; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < layout.ecPerBlock; i++ {
+		for _, ec := range ecBlocks {
+			result = append(result, ec[i])
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor returns the generator polynomial of degree, highest coefficient first and the
+// leading 1 left out
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords of data
+func reedSolomonRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+func (q *Code) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+func (q *Code) drawFunctionPatterns() {
+	for i := 0; i < q.Size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	q.drawFinder(3, 3)
+	q.drawFinder(q.Size-4, 3)
+	q.drawFinder(3, q.Size-4)
+
+	centers := alignmentCenters[q.Version]
+	last := len(centers) - 1
+	for i, y := range centers {
+		for j, x := range centers {
+			// the corners of the finders have no alignment pattern
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			q.drawAlignment(x, y)
+		}
+	}
+
+	// reserved until the mask is known
+	q.drawFormatBits(0)
+	q.drawVersionBits()
+}
+
+// drawFinder draws a finder pattern centered on x, y with its separator
+func (q *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.Size || yy < 0 || yy >= q.Size {
+				continue
+			}
+			distance := max(abs(dx), abs(dy))
+			q.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+func (q *Code) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// formatBits is the format information of level M with mask, with its BCH code
+func formatBits(mask int) int {
+	data := levelM<<3 | mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	return (data<<10 | remainder) ^ 0x5412
+}
+
+func (q *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	// around the top left finder
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+
+	// split between the other two finders
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.Size-15+i, bit(i))
+	}
+	q.setFunction(8, q.Size-8, true)
+}
+
+// versionBits is the version information of version, with its BCH code
+func versionBits(version int) int {
+	remainder := version
+	for i := 0; i < 12; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+	}
+	return version<<12 | remainder
+}
+
+func (q *Code) drawVersionBits() {
+	if q.Version < 7 {
+		return
+	}
+
+	bits := versionBits(q.Version)
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 == 1
+		a, b := q.Size-11+i%3, i/3
+		q.setFunction(a, b, dark)
+		q.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords fills the modules left by the function patterns in the zigzag order of the standard,
+// the remainder bits stay light
+func (q *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		// the vertical timing pattern is skipped as a whole column
+		if right == 6 {
+			right = 5
+		}
+		for vertical := 0; vertical < q.Size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = q.Size - 1 - vertical
+				}
+				if q.isFunction[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				q.modules[y][x] = (codewords[i>>3]>>(7-i&7))&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// applyMask inverts the data modules picked by mask, applying it twice restores them
+func (q *Code) applyMask(mask int) {
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if !q.isFunction[y][x] && maskBit(mask, x, y) {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// finderLike is the 1:1:3:1:1 pattern with four light modules on one side, penalized by rule 3
+var finderLike = []bool{true, false, true, true, true, false, true, false, false, false, false}
+
+// penalty scores how hard the symbol is to read, the mask with the lowest score is kept
+func (q *Code) penalty() int {
+	result := 0
+	dark := 0
+
+	line := make([]bool, q.Size)
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < q.Size; i++ {
+			for j := 0; j < q.Size; j++ {
+				if vertical {
+					line[j] = q.modules[j][i]
+				} else {
+					line[j] = q.modules[i][j]
+				}
+			}
+			result += linePenalty(line)
+		}
+	}
+
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+			if x+1 < q.Size && y+1 < q.Size {
+				color := q.modules[y][x]
+				if color == q.modules[y][x+1] && color == q.modules[y+1][x] && color == q.modules[y+1][x+1] {
+					result += 3
+				}
+			}
+		}
+	}
+
+	// every 5% away from half dark costs 10
+	total := q.Size * q.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	result += k * 10
+
+	return result
+}
+
+// linePenalty scores the runs of a row or column and the patterns looking like a finder
+func linePenalty(line []bool) int {
+	result := 0
+
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			result += 3 + run - 5
+		}
+		run = 1
+	}
+
+	for i := 0; i+len(finderLike) <= len(line); i++ {
+		forward, backward := true, true
+		for j, dark := range finderLike {
+			forward = forward && line[i+j] == dark
+			backward = backward && line[i+len(finderLike)-1-j] == dark
+		}
+		if forward {
+			result += 40
+		}
+		if backward {
+			result += 40
+		}
+	}
+
+	return result
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
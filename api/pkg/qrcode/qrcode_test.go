@@ -0,0 +1,122 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readCodewords reads the data modules back in placement order, without the mask
+func readCodewords(q *Code) []byte {
+	bits := &bitBuffer{}
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := 0; vertical < q.Size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vertical
+				if (right+1)&2 == 0 {
+					y = q.Size - 1 - vertical
+				}
+				if q.isFunction[y][x] {
+					continue
+				}
+				dark := q.modules[y][x] != maskBit(q.Mask, x, y)
+				if dark {
+					bits.append(1, 1)
+				} else {
+					bits.append(0, 1)
+				}
+			}
+		}
+	}
+	return bits.bytes()
+}
+
+func TestQRCode(t *testing.T) {
+	t.Run("error correction codewords", func(t *testing.T) {
+		// the 1-M symbol of HELLO WORLD in alphanumeric mode
+		data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+		ec := reedSolomonRemainder(data, reedSolomonDivisor(10))
+		assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, ec)
+	})
+
+	t.Run("format and version information", func(t *testing.T) {
+		assert.Equal(t, 0b101010000010010, formatBits(0))
+		assert.Equal(t, 0b100000011001110, formatBits(5))
+		assert.Equal(t, 0b100101010100000, formatBits(7))
+		assert.Equal(t, 0b000111110010010100, versionBits(7))
+		assert.Equal(t, 0b001010010011010011, versionBits(10))
+	})
+
+	t.Run("smallest version holding the text", func(t *testing.T) {
+		for length, version := range map[int]int{1: 1, 14: 1, 15: 2, 62: 4, 100: 6, 213: 10} {
+			q, err := Encode(strings.Repeat("a", length))
+			assert.Nil(t, err)
+			assert.Equal(t, version, q.Version, length)
+			assert.Equal(t, version*4+17, q.Size)
+		}
+
+		_, err := Encode(strings.Repeat("a", 214))
+		assert.Equal(t, ErrTooLong, err)
+	})
+
+	t.Run("function patterns", func(t *testing.T) {
+		q, err := Encode("https://jovvix.example.com/api/v1/j/AbCdEfGh.0123456789a")
+		assert.Nil(t, err)
+
+		for _, corner := range [][2]int{{0, 0}, {q.Size - 7, 0}, {0, q.Size - 7}} {
+			for i := 0; i < 7; i++ {
+				assert.True(t, q.Dark(corner[0]+i, corner[1]))
+				assert.True(t, q.Dark(corner[0], corner[1]+i))
+			}
+			assert.False(t, q.Dark(corner[0]+1, corner[1]+1))
+			assert.True(t, q.Dark(corner[0]+3, corner[1]+3))
+		}
+		for i := 8; i < q.Size-8; i++ {
+			assert.Equal(t, i%2 == 0, q.Dark(i, 6))
+			assert.Equal(t, i%2 == 0, q.Dark(6, i))
+		}
+		assert.True(t, q.Dark(8, q.Size-8))
+	})
+
+	t.Run("codewords and format survive the mask", func(t *testing.T) {
+		text := strings.Repeat("join ", 30)
+		q, err := Encode(text)
+		assert.Nil(t, err)
+		assert.Equal(t, 8, q.Version)
+
+		expected := addErrorCorrection(q.Version, dataCodewords(q.Version, []byte(text)))
+		assert.Equal(t, expected, readCodewords(q)[:len(expected)])
+
+		format := 0
+		for i := 0; i <= 5; i++ {
+			if q.Dark(8, i) {
+				format |= 1 << i
+			}
+		}
+		if q.Dark(8, 7) {
+			format |= 1 << 6
+		}
+		assert.Equal(t, formatBits(q.Mask)&0x7F, format)
+	})
+
+	t.Run("renders", func(t *testing.T) {
+		q, err := Encode("123456")
+		assert.Nil(t, err)
+
+		raw, err := q.PNG(4)
+		assert.Nil(t, err)
+		img, err := png.Decode(bytes.NewReader(raw))
+		assert.Nil(t, err)
+		assert.Equal(t, (21+2*QuietZone)*4, img.Bounds().Dx())
+
+		svg := string(q.SVG())
+		assert.True(t, strings.HasPrefix(svg, "<svg"))
+		assert.Contains(t, svg, `viewBox="0 0 29 29"`)
+	})
+}
@@ -0,0 +1,54 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// PNG draws the symbol with its quiet zone, scale pixels per module
+func (q *Code) PNG(scale int) ([]byte, error) {
+	scale = max(scale, 1)
+	side := (q.Size + 2*QuietZone) * scale
+
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if !q.modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x+QuietZone)*scale+dx, (y+QuietZone)*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG draws the symbol with its quiet zone as a single path, one unit per module
+func (q *Code) SVG() []byte {
+	side := q.Size + 2*QuietZone
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, side, side)
+	fmt.Fprintf(buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, side, side)
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.modules[y][x] {
+				fmt.Fprintf(buf, "M%d %dh1v1h-1z", x+QuietZone, y+QuietZone)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes()
+}
@@ -1,6 +1,9 @@
 package structs
 
 import (
+	"time"
+
+	"github.com/Improwised/jovvix/api/pkg/textmatch"
 	"github.com/google/uuid"
 )
 
@@ -26,9 +29,36 @@ type ReqLoginUser struct {
 }
 
 type ReqAnswerSubmit struct {
-	QuestionId   uuid.UUID `json:"id" validate:"required"`
-	AnswerKeys   []int     `json:"keys" validate:"required"`
-	ResponseTime int       `json:"response_time" validate:"required"`
+	QuestionId   uuid.UUID      `json:"id" validate:"required"`
+	AnswerKeys   []int          `json:"keys" validate:"required_without_all=AnswerText AnswerValue AnswerPairs"`
+	AnswerText   string         `json:"text,omitempty" validate:"max=200"`
+	AnswerValue  *float64       `json:"value,omitempty"`
+	AnswerPairs  map[string]int `json:"pairs,omitempty"`
+	ResponseTime int            `json:"response_time" validate:"required"`
+}
+
+// ShortAnswerRules are the answers a short answer question accepts and how strictly they are matched
+type ShortAnswerRules struct {
+	Accepted []string `json:"accepted"`
+	textmatch.Rules
+}
+
+// NumericRules are the value a numeric question expects and how far from it an answer may be.
+// Answers within the tolerance earn the full points, answers further off but within the partial
+// range earn less the further they are. Both are in the unit or in percent of the target.
+type NumericRules struct {
+	Target        float64 `json:"target"`
+	Tolerance     float64 `json:"tolerance"`
+	ToleranceType string  `json:"tolerance_type"`
+	PartialRange  float64 `json:"partial_range,omitempty"`
+	Unit          string  `json:"unit,omitempty"`
+}
+
+// MatchingRules are the definitions the options of a matching question are paired with, Pairs gives the
+// key of the definition of each option
+type MatchingRules struct {
+	Definitions map[string]string `json:"definitions"`
+	Pairs       map[string]int    `json:"pairs"`
 }
 
 type ReqUpdateQuestion struct {
@@ -41,6 +71,10 @@ type ReqUpdateQuestion struct {
 	QuestionMedia     string            `json:"question_media" validate:"required"`
 	OptionsMedia      string            `json:"options_media" validate:"required"`
 	Resource          string            `json:"resource"`
+	Grading           string            `json:"grading"`
+	ShortAnswer       *ShortAnswerRules `json:"short_answer,omitempty"`
+	Numeric           *NumericRules     `json:"numeric,omitempty"`
+	Matching          *MatchingRules    `json:"matching,omitempty"`
 }
 
 type ReqCreateQuiz struct {
@@ -53,6 +87,53 @@ type ReqCreateQuiz struct {
 	CoverImage        string `json:"cover_image"`
 }
 
+type ReqCreateAssignment struct {
+	Title            string    `json:"title" validate:"omitempty,max=100"`
+	OpensAt          time.Time `json:"opens_at"`
+	ClosesAt         time.Time `json:"closes_at" validate:"required"`
+	ShuffleQuestions bool      `json:"shuffle_questions"`
+	ShuffleOptions   bool      `json:"shuffle_options"`
+}
+
+type ReqModeratePlayer struct {
+	UserId string `json:"user_id" validate:"required"`
+	Name   string `json:"name,omitempty"`
+}
+
+type ReqSessionShuffle struct {
+	ShuffleQuestions bool `json:"shuffle_questions"`
+	ShuffleOptions   bool `json:"shuffle_options"`
+}
+
+type ReqSessionJoinPolicy struct {
+	MaxPlayers     int    `json:"max_players" validate:"omitempty,min=1,max=10000"`
+	AllowLateJoin  *bool  `json:"allow_late_join" validate:"required"`
+	LateJoinCredit string `json:"late_join_credit" validate:"required,oneof=zero average"`
+}
+
+type ReqSessionAccess struct {
+	Mode      string   `json:"mode" validate:"required,oneof=open passcode allowlist"`
+	Passcode  string   `json:"passcode" validate:"omitempty,min=4,max=50"`
+	Allowlist []string `json:"allowlist" validate:"max=1000"`
+}
+
+type ReqPlayedQuizValidation struct {
+	Passcode string `json:"passcode"`
+	// token of a join link made past the passcode, it stands in for the passcode
+	JoinLink string `json:"join_link,omitempty"`
+}
+
+type ReqSessionTeams struct {
+	Mode    string   `json:"mode" validate:"required,oneof=auto choice"`
+	Scoring string   `json:"scoring" validate:"required,oneof=sum average best_n"`
+	BestN   int      `json:"best_n" validate:"omitempty,min=1"`
+	Teams   []string `json:"teams" validate:"required,min=2,max=20,unique,dive,required,max=50"`
+}
+
+type ReqChooseTeam struct {
+	TeamId string `json:"team_id" validate:"required,uuid"`
+}
+
 type ReqQuizCategory struct {
 	Name string `json:"name" validate:"required,max=50"`
 }
@@ -75,6 +156,10 @@ type ReqCreateQuestion struct {
 	QuestionMedia     string            `json:"question_media" validate:"required"`
 	OptionsMedia      string            `json:"options_media" validate:"required"`
 	Resource          string            `json:"resource"`
+	Grading           string            `json:"grading"`
+	ShortAnswer       *ShortAnswerRules `json:"short_answer,omitempty"`
+	Numeric           *NumericRules     `json:"numeric,omitempty"`
+	Matching          *MatchingRules    `json:"matching,omitempty"`
 }
 
 type ReqShareQuiz struct {
@@ -1,6 +1,9 @@
 package structs
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
 
 // All response structs
 // Response struct have Res prefix
@@ -54,9 +57,39 @@ type QuestionAnalytics struct {
 	Points            int               `db:"points,omitempty" json:"points"`
 	QuestionTypeID    int               `db:"type,omitempty" json:"question_type_id"`
 	QuestionType      string            `db:"omitempty" json:"question_type"`
+	Grading           string            `db:"grading" json:"grading"`
+	RawAnswerRules    []byte            `db:"answer_rules" json:"-"`
+	ShortAnswer       *ShortAnswerRules `db:"-" json:"short_answer,omitempty"`
+	Numeric           *NumericRules     `db:"-" json:"numeric,omitempty"`
+	Matching          *MatchingRules    `db:"-" json:"matching,omitempty"`
 	DurationInSeconds int               `db:"duration_in_seconds" json:"duration_in_seconds"`
 }
 
+// OptionSelection is how often an option was picked, Rate is out of the players who answered
+type OptionSelection struct {
+	Key       string  `json:"key"`
+	Count     int     `json:"count"`
+	Rate      float64 `json:"rate"`
+	IsCorrect bool    `json:"is_correct"`
+}
+
+// ValueDistribution summarizes the values sent to a numeric question
+type ValueDistribution struct {
+	Count   int           `json:"count"`
+	Min     float64       `json:"min"`
+	Max     float64       `json:"max"`
+	Mean    float64       `json:"mean"`
+	Median  float64       `json:"median"`
+	Buckets []ValueBucket `json:"buckets"`
+}
+
+// ValueBucket is a bar of the histogram, From is included and To only in the last bucket
+type ValueBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
 type ResQuestionAnalytics struct {
 	Data              []QuestionAnalytics `json:"data"`
 	QuizPlayedCount   int64               `json:"quiz_played_count"`
@@ -80,3 +113,9 @@ type ResUserWithQuizPermission struct {
 	ImageKey   sql.NullString `json:"img_key" db:"img_key"`
 	Permission string         `json:"permission" db:"permission"`
 }
+
+type ResJoinLink struct {
+	URL            string    `json:"url"`
+	InvitationCode int       `json:"invitation_code"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
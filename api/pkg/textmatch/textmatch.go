@@ -0,0 +1,171 @@
+// Package textmatch checks typed answers against the answers an author accepts. By default case,
+// spacing and accents do not count, and a few typos can be let through with a distance threshold.
+package textmatch
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Rules are how lenient the matching is, the zero value folds case and accents and wants no typo
+type Rules struct {
+	// CaseSensitive keeps upper and lower case apart
+	CaseSensitive bool `json:"case_sensitive"`
+	// KeepAccents keeps letters with accents apart from the plain ones
+	KeepAccents bool `json:"keep_accents"`
+	// MaxDistance is how many letters may be added, removed or changed
+	MaxDistance int `json:"max_distance"`
+	// Pattern is a regular expression the whole answer may match instead
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// Group is a set of answers that only differ by what the rules let through
+type Group struct {
+	// Answer is the most common spelling of the group
+	Answer string `json:"answer"`
+	Count  int    `json:"count"`
+}
+
+// Normalize returns text the way it is compared under rules
+func Normalize(text string, rules Rules) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if !rules.CaseSensitive {
+		text = strings.ToLower(text)
+	}
+	if !rules.KeepAccents {
+		text = foldAccents(text)
+	}
+	return text
+}
+
+// foldAccents strips the accents off the letters of text
+func foldAccents(text string) string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), text)
+	if err != nil {
+		return text
+	}
+	return stripped
+}
+
+// Compile returns the pattern of rules, nil when there is none. The pattern loses its accents like the
+// answers it is matched against, its case is ignored with a flag since lowering it would change escapes.
+func Compile(rules Rules) (*regexp.Regexp, error) {
+	if rules.Pattern == "" {
+		return nil, nil
+	}
+
+	pattern := rules.Pattern
+	if !rules.KeepAccents {
+		pattern = foldAccents(pattern)
+	}
+	pattern = "^(?:" + pattern + ")$"
+	if !rules.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// Match tells whether text is one of the accepted answers or matches the pattern of rules
+func Match(text string, accepted []string, rules Rules) (bool, error) {
+	normalized := Normalize(text, rules)
+	if normalized == "" {
+		return false, nil
+	}
+
+	for _, answer := range accepted {
+		if Distance(normalized, Normalize(answer, rules)) <= rules.MaxDistance {
+			return true, nil
+		}
+	}
+
+	pattern, err := Compile(rules)
+	if err != nil || pattern == nil {
+		return false, err
+	}
+	return pattern.MatchString(normalized), nil
+}
+
+// Distance is the Levenshtein distance between a and b, counted in runes
+func Distance(a, b string) int {
+	left, right := []rune(a), []rune(b)
+	if len(left) < len(right) {
+		left, right = right, left
+	}
+
+	previous := make([]int, len(right)+1)
+	current := make([]int, len(right)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(left); i++ {
+		current[0] = i
+		for j := 1; j <= len(right); j++ {
+			cost := 1
+			if left[i-1] == right[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(right)]
+}
+
+// GroupAnswers puts together the answers within the distance of rules of each other, the biggest groups first
+func GroupAnswers(texts []string, rules Rules) []Group {
+	type group struct {
+		normalized string
+		spellings  map[string]int
+		count      int
+	}
+
+	groups := []*group{}
+	for _, text := range texts {
+		normalized := Normalize(text, rules)
+		if normalized == "" {
+			continue
+		}
+
+		var found *group
+		for _, g := range groups {
+			if Distance(normalized, g.normalized) <= rules.MaxDistance {
+				found = g
+				break
+			}
+		}
+		if found == nil {
+			found = &group{normalized: normalized, spellings: map[string]int{}}
+			groups = append(groups, found)
+		}
+
+		found.spellings[strings.Join(strings.Fields(text), " ")]++
+		found.count++
+	}
+
+	result := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		answer, best := "", 0
+		for spelling, count := range g.spellings {
+			if count > best || (count == best && spelling < answer) {
+				answer, best = spelling, count
+			}
+		}
+		result = append(result, Group{Answer: answer, Count: g.count})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Answer < result[j].Answer
+	})
+	return result
+}
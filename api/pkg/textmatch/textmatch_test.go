@@ -0,0 +1,98 @@
+package textmatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextMatch(t *testing.T) {
+	t.Run("normalize", func(t *testing.T) {
+		assert.Equal(t, "creme brulee", Normalize("  Crème   Brûlée ", Rules{}))
+		assert.Equal(t, "Crème Brûlée", Normalize("Crème\tBrûlée", Rules{CaseSensitive: true, KeepAccents: true}))
+	})
+
+	t.Run("distance", func(t *testing.T) {
+		assert.Equal(t, 0, Distance("", ""))
+		assert.Equal(t, 3, Distance("kitten", "sitting"))
+		assert.Equal(t, 3, Distance("sitting", "kitten"))
+		assert.Equal(t, 1, Distance("café", "cafe"))
+		assert.Equal(t, 4, Distance("", "go ä"))
+	})
+
+	t.Run("accepted answers", func(t *testing.T) {
+		accepted := []string{"Mount Everest", "Everest"}
+
+		for text, expected := range map[string]bool{
+			"everest":         true,
+			" MOUNT  EVEREST": true,
+			"Evrest":          false,
+			"":                false,
+		} {
+			matched, err := Match(text, accepted, Rules{})
+			assert.Nil(t, err)
+			assert.Equal(t, expected, matched, text)
+		}
+
+		matched, err := Match("Evrest", accepted, Rules{MaxDistance: 1})
+		assert.Nil(t, err)
+		assert.True(t, matched)
+
+		matched, err = Match("everest", accepted, Rules{CaseSensitive: true})
+		assert.Nil(t, err)
+		assert.False(t, matched)
+
+		matched, err = Match("Zürich", []string{"Zurich"}, Rules{KeepAccents: true})
+		assert.Nil(t, err)
+		assert.False(t, matched)
+	})
+
+	t.Run("pattern", func(t *testing.T) {
+		rules := Rules{Pattern: `(the )?beatles`}
+
+		matched, err := Match("The Beatles", nil, rules)
+		assert.Nil(t, err)
+		assert.True(t, matched)
+
+		// the whole answer has to match
+		matched, err = Match("not the beatles", nil, rules)
+		assert.Nil(t, err)
+		assert.False(t, matched)
+
+		_, err = Match("x", nil, Rules{Pattern: "("})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("accented pattern", func(t *testing.T) {
+		rules := Rules{Pattern: `crème brûlée|Zürich\S*`}
+
+		for _, text := range []string{"Crème Brûlée", "creme brulee", "ZURICH-city", "zürich"} {
+			matched, err := Match(text, nil, rules)
+			assert.Nil(t, err)
+			assert.True(t, matched, text)
+		}
+
+		rules.KeepAccents = true
+		matched, err := Match("Creme Brulee", nil, rules)
+		assert.Nil(t, err)
+		assert.False(t, matched)
+
+		matched, err = Match("Crème Brûlée", nil, rules)
+		assert.Nil(t, err)
+		assert.True(t, matched)
+	})
+
+	t.Run("group answers", func(t *testing.T) {
+		texts := []string{"Paris", "paris ", "Lyon", "Pariss", "  ", "lyon", "PARIS", "Nice"}
+
+		assert.Equal(t, []Group{
+			{Answer: "PARIS", Count: 3},
+			{Answer: "Lyon", Count: 2},
+			{Answer: "Nice", Count: 1},
+			{Answer: "Pariss", Count: 1},
+		}, GroupAnswers(texts, Rules{}))
+
+		groups := GroupAnswers(texts, Rules{MaxDistance: 1})
+		assert.Equal(t, Group{Answer: "PARIS", Count: 4}, groups[0])
+	})
+}
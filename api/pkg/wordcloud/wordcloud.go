@@ -0,0 +1,112 @@
+// Package wordcloud counts the words of typed answers for a word cloud. Words are folded the way
+// textmatch compares answers, common English words are left out and unwanted words can be masked.
+package wordcloud
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Improwised/jovvix/api/pkg/textmatch"
+)
+
+// Word is a word of the cloud and the number of answers it is in
+type Word struct {
+	Text  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Options are what the cloud leaves out or hides, the zero value keeps every word but the stop words
+type Options struct {
+	// MaskedWords are shown with only their first letter
+	MaskedWords []string
+	// Mask replaces each other letter of a masked word, * when empty
+	Mask string
+	// MaxWords is the most words returned, 0 keeps them all
+	MaxWords int
+}
+
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`
+		about above after again against all am an and any are as at be because been before being
+		below between both but by can could did do does doing down during each few for from further
+		had has have having he her here hers herself him himself his how i if in into is it its itself
+		just me more most my myself no nor not now of off on once only or other our ours ourselves out
+		over own same she should so some such than that the their theirs them themselves then there
+		these they this those through to too under until up very was we were what when where which
+		while who whom why will with would you your yours yourself yourselves i'm it's don't`) {
+		stopWords[word] = true
+	}
+}
+
+// Words splits text into the words the cloud counts
+func Words(text string) []string {
+	words := strings.FieldsFunc(textmatch.Normalize(text, textmatch.Rules{}), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.Trim(word, "'")
+		if utf8.RuneCountInString(word) < 2 || stopWords[word] {
+			continue
+		}
+		result = append(result, word)
+	}
+	return result
+}
+
+// Count returns the words of texts, the most used first. A word is counted once per text.
+func Count(texts []string, options Options) []Word {
+	masked := map[string]bool{}
+	for _, word := range options.MaskedWords {
+		if word = textmatch.Normalize(word, textmatch.Rules{}); word != "" {
+			masked[word] = true
+		}
+	}
+
+	// words are masked before they are counted, masked words that look the same are one word of the cloud
+	counts := map[string]int{}
+	for _, text := range texts {
+		seen := map[string]bool{}
+		for _, word := range Words(text) {
+			if masked[word] {
+				word = mask(word, options.Mask)
+			}
+			if seen[word] {
+				continue
+			}
+			seen[word] = true
+			counts[word]++
+		}
+	}
+
+	words := make([]Word, 0, len(counts))
+	for word, count := range counts {
+		words = append(words, Word{Text: word, Count: count})
+	}
+
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+		return words[i].Text < words[j].Text
+	})
+
+	if options.MaxWords > 0 && len(words) > options.MaxWords {
+		words = words[:options.MaxWords]
+	}
+	return words
+}
+
+// mask keeps the first letter of word and replaces the others
+func mask(word string, with string) string {
+	if with == "" {
+		with = "*"
+	}
+	first, size := utf8.DecodeRuneInString(word)
+	return string(first) + strings.Repeat(with, utf8.RuneCountInString(word[size:]))
+}
@@ -0,0 +1,43 @@
+package wordcloud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordCloud(t *testing.T) {
+	t.Run("words", func(t *testing.T) {
+		assert.Equal(t, []string{"creme", "brulee", "stop"}, Words("  The Crème-Brûlée, I DON'T stop!"))
+		assert.Equal(t, []string{}, Words("a of the"))
+		assert.Equal(t, []string{"rock'n'roll", "42"}, Words("'rock'n'roll' 42 x"))
+	})
+
+	t.Run("count", func(t *testing.T) {
+		words := Count([]string{"Pizza pizza PIZZA", "pasta and pizza", "", "Pasta!", "sushi"}, Options{})
+		assert.Equal(t, []Word{
+			{Text: "pasta", Count: 2},
+			{Text: "pizza", Count: 2},
+			{Text: "sushi", Count: 1},
+		}, words)
+
+		assert.Equal(t, []Word{}, Count(nil, Options{}))
+	})
+
+	t.Run("max words", func(t *testing.T) {
+		words := Count([]string{"red green blue", "blue green", "blue"}, Options{MaxWords: 2})
+		assert.Equal(t, []Word{{Text: "blue", Count: 3}, {Text: "green", Count: 2}}, words)
+	})
+
+	t.Run("masked words", func(t *testing.T) {
+		words := Count([]string{"darn it", "DARN", "dárn good"}, Options{MaskedWords: []string{" Darn "}})
+		assert.Equal(t, []Word{{Text: "d***", Count: 3}, {Text: "good", Count: 1}}, words)
+
+		words = Count([]string{"heck"}, Options{MaskedWords: []string{"heck"}, Mask: "#"})
+		assert.Equal(t, []Word{{Text: "h###", Count: 1}}, words)
+
+		// masked words that look the same are counted together, once per answer, before the cloud is cut
+		words = Count([]string{"damn", "dang it", "damn dang", "nice", "nice", "good"}, Options{MaskedWords: []string{"damn", "dang"}, MaxWords: 2})
+		assert.Equal(t, []Word{{Text: "d***", Count: 3}, {Text: "nice", Count: 2}}, words)
+	})
+}
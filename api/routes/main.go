@@ -12,8 +12,9 @@ import (
 	"github.com/Improwised/jovvix/api/constants"
 	controller "github.com/Improwised/jovvix/api/controllers/api/v1"
 	"github.com/Improwised/jovvix/api/middlewares"
+	"github.com/Improwised/jovvix/api/pkg/hub"
 	pMetrics "github.com/Improwised/jovvix/api/pkg/prometheus"
-	"github.com/Improwised/jovvix/api/pkg/redis"
+	"github.com/Improwised/jovvix/api/pkg/pubsub"
 	goqu "github.com/doug-martin/goqu/v9"
 	"github.com/gofiber/contrib/swagger"
 	"github.com/gofiber/contrib/websocket"
@@ -23,7 +24,7 @@ import (
 var mu sync.Mutex
 
 // Setup func
-func Setup(app *fiber.App, goqu *goqu.Database, logger *zap.Logger, config config.AppConfig, pMetrics *pMetrics.PrometheusMetrics) error {
+func Setup(app *fiber.App, goqu *goqu.Database, logger *zap.Logger, config config.AppConfig, pMetrics *pMetrics.PrometheusMetrics, pubsub pubsub.PubSub) error {
 	mu.Lock()
 	defer mu.Unlock()
 
@@ -57,12 +58,6 @@ func Setup(app *fiber.App, goqu *goqu.Database, logger *zap.Logger, config confi
 		return err
 	}
 
-	redis, err := redis.InitRedisPubSub(goqu, config.RedisClient, logger)
-
-	if err != nil {
-		return err
-	}
-
 	// middleware initialization
 	middleware := middlewares.NewMiddleware(config, logger, goqu)
 
@@ -97,7 +92,7 @@ func Setup(app *fiber.App, goqu *goqu.Database, logger *zap.Logger, config confi
 		return err
 	}
 
-	err = setupQuizSocketController(v1, goqu, logger, middleware, config, redis)
+	err = setupQuizSocketController(v1, goqu, logger, middleware, config, pubsub, pMetrics)
 	if err != nil {
 		return err
 	}
@@ -127,6 +122,11 @@ func Setup(app *fiber.App, goqu *goqu.Database, logger *zap.Logger, config confi
 		return err
 	}
 
+	err = setupAssignmentController(v1, goqu, logger, middleware)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -218,21 +218,44 @@ func setupMetricsController(api fiber.Router, db *goqu.Database, logger *zap.Log
 	return nil
 }
 
-func setupQuizSocketController(v1 fiber.Router, db *goqu.Database, logger *zap.Logger, middleware middlewares.Middleware, config config.AppConfig, redis *redis.RedisPubSub) error {
-	quizSocketController, err := controller.InitQuizConfig(db, &config, logger, redis)
+func setupQuizSocketController(v1 fiber.Router, db *goqu.Database, logger *zap.Logger, middleware middlewares.Middleware, config config.AppConfig, pubsub pubsub.PubSub, pMetrics *pMetrics.PrometheusMetrics) error {
+	nodeHub, err := hub.New(pubsub, config.Quiz, logger)
+	if err != nil {
+		return err
+	}
+	pMetrics.RegisterHub(nodeHub)
+
+	quizSocketController, err := controller.InitQuizConfig(db, &config, logger, pubsub, nodeHub)
 	if err != nil {
 		return err
 	}
 
+	// take over the sessions of replicas that died while driving them
+	go quizSocketController.WatchSessions()
+
 	// CustomAuthenticated (not kratos-only) so guests can host public quizzes too.
 	// GetOrActivateSession still enforces admin_id == userId, so a guest can only
 	// arrange a session they themselves created.
-	v1.Get(fmt.Sprintf("/socket/admin/arrange/:%s", constants.SessionIDParam), middleware.CheckSessionId, middleware.CustomAuthenticated, websocket.New(quizSocketController.Arrange))
-	v1.Get(fmt.Sprintf("/socket/join/:%s", constants.QuizSessionInvitationCode), middleware.CheckSessionCode, middleware.CustomAuthenticated, websocket.New(quizSocketController.Join))
+	v1.Get(fmt.Sprintf("/socket/admin/arrange/:%s", constants.SessionIDParam), middleware.CheckSessionId, middleware.NegotiateProtocol, middleware.CustomAuthenticated, websocket.New(quizSocketController.Arrange))
+	v1.Get(fmt.Sprintf("/socket/join/:%s", constants.QuizSessionInvitationCode), middleware.CheckSessionCode, middleware.NegotiateProtocol, middleware.CustomAuthenticated, websocket.New(quizSocketController.Join))
+	// read-only view for a projector, it never becomes a participant. The invitation code is all it needs
+	// for an open session, a passcode or allowlist session can only be spectated by its host.
+	v1.Get(fmt.Sprintf("/socket/spectate/:%s", constants.QuizSessionInvitationCode), middleware.CheckSessionCode, middleware.NegotiateProtocol, middleware.OptionalAuthenticated, websocket.New(quizSocketController.Spectate))
 	v1.Post("/quiz/answer", middleware.Authenticated, middleware.CustomAuthenticated, quizSocketController.SetAnswer)
 	v1.Get("/quiz/terminate", middleware.Authenticated, quizSocketController.Terminate)
 	v1.Get("/quiz/sessions/active", middleware.Authenticated, quizSocketController.ListActiveSessions)
 
+	v1.Get(fmt.Sprintf("/quiz/sessions/:%s/teams", constants.SessionIDParam), middleware.Authenticated, quizSocketController.ListTeams)
+	v1.Put(fmt.Sprintf("/quiz/sessions/:%s/teams", constants.SessionIDParam), middleware.Authenticated, quizSocketController.ConfigureTeams)
+	v1.Delete(fmt.Sprintf("/quiz/sessions/:%s/teams", constants.SessionIDParam), middleware.Authenticated, quizSocketController.DisableTeams)
+	v1.Put(fmt.Sprintf("/quiz/sessions/:%s/team", constants.SessionIDParam), middleware.Authenticated, quizSocketController.ChooseTeam)
+	v1.Put(fmt.Sprintf("/quiz/sessions/:%s/shuffle", constants.SessionIDParam), middleware.Authenticated, quizSocketController.SetShuffle)
+	v1.Put(fmt.Sprintf("/quiz/sessions/:%s/join_policy", constants.SessionIDParam), middleware.Authenticated, quizSocketController.SetJoinPolicy)
+	v1.Put(fmt.Sprintf("/quiz/sessions/:%s/access", constants.SessionIDParam), middleware.Authenticated, quizSocketController.SetAccess)
+	v1.Get(fmt.Sprintf("/quiz/sessions/:%s/join_link", constants.SessionIDParam), middleware.Authenticated, quizSocketController.GetJoinLink)
+	v1.Get(fmt.Sprintf("/quiz/sessions/:%s/join_link/qr", constants.SessionIDParam), middleware.Authenticated, quizSocketController.GetJoinLinkQR)
+	v1.Get(fmt.Sprintf("/j/:%s", constants.JoinLinkTokenParam), quizSocketController.OpenJoinLink)
+
 	return nil
 }
 
@@ -260,6 +283,7 @@ func setupQuizController(v1 fiber.Router, db *goqu.Database, logger *zap.Logger,
 	quizzes.Use(middleware.KratosAuthenticated)
 
 	quizzes.Post(fmt.Sprintf("/:%s/demo_session", constants.QuizId), quizController.GenerateDemoSession)
+	quizzes.Post(fmt.Sprintf("/:%s/assignment_session", constants.QuizId), quizController.GenerateAssignmentSession)
 	quizzes.Post(fmt.Sprintf("/:%s/upload", constants.QuizTitle), middleware.ValidateCsv, middleware.KratosAuthenticated, quizController.CreateQuizByCsv)
 	quizzes.Post("/", quizController.CreateQuiz)
 	quizzes.Get("/", quizController.GetAdminUploadedQuizzes)
@@ -327,7 +351,9 @@ func setUpFinalScoreBoardController(v1 fiber.Router, goqu *goqu.Database, logger
 
 	finalScore := v1.Group("/final_score")
 	finalScore.Get("/user", finalScoreBoardController.GetScore)
+	finalScore.Get("/user/teams", finalScoreBoardController.GetTeamScore)
 	finalScore.Get("/admin", middlewares.KratosAuthenticated, finalScoreBoardControllerAdmin.GetScoreForAdmin)
+	finalScore.Get("/admin/teams", middlewares.KratosAuthenticated, finalScoreBoardControllerAdmin.GetTeamScoreForAdmin)
 
 	return nil
 }
@@ -363,6 +389,19 @@ func setupUserPlayedQuizeController(v1 fiber.Router, goqu *goqu.Database, logger
 	return nil
 }
 
+func setupAssignmentController(v1 fiber.Router, goqu *goqu.Database, logger *zap.Logger, middlewares middlewares.Middleware) error {
+	assignmentController, err := controller.NewAssignmentController(goqu, logger)
+	if err != nil {
+		return err
+	}
+
+	assignmentRouter := v1.Group("/assignments")
+	assignmentRouter.Use(middlewares.Authenticated)
+	assignmentRouter.Get(fmt.Sprintf("/:%s/question", constants.UserPlayedQuizId), assignmentController.GetNextQuestion)
+	assignmentRouter.Post(fmt.Sprintf("/:%s/answer", constants.UserPlayedQuizId), assignmentController.SubmitAnswer)
+	return nil
+}
+
 func setupSharedQuizzesController(v1 fiber.Router, goqu *goqu.Database, logger *zap.Logger, middlewares middlewares.Middleware, config config.AppConfig) error {
 	sharedQuizzesController, err := controller.NewSharedQuizzesController(goqu, logger, &config)
 	if err != nil {
@@ -2,13 +2,188 @@ package utils
 
 import (
 	"database/sql"
+	"encoding/json"
 	"math"
+	"strings"
 
 	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/models"
 	"github.com/Improwised/jovvix/api/pkg/structs"
+	"github.com/Improwised/jovvix/api/pkg/textmatch"
 )
 
-func CalculatePointsAndScore(userAnswer structs.ReqAnswerSubmit, answers []int, answerPoints int16, answerDurationInSeconds, questionType int) (sql.NullInt16, int) {
+// ScoreAnswer scores an answer against the answer key of its question
+func ScoreAnswer(userAnswer structs.ReqAnswerSubmit, answerKey models.AnswerKey) (sql.NullInt16, int) {
+	switch answerKey.Type {
+	case constants.ShortAnswer:
+		return scoreShortAnswer(userAnswer, answerKey)
+	case constants.Numeric:
+		return scoreNumeric(userAnswer, answerKey)
+	case constants.Ordering:
+		return scoreOrdering(userAnswer, answerKey)
+	case constants.Matching:
+		return scoreMatching(userAnswer, answerKey)
+	case constants.WordCloud:
+		return scoreWordCloud(userAnswer)
+	}
+	return CalculatePointsAndScore(userAnswer, answerKey.Answers, answerKey.Points, answerKey.DurationInSeconds, answerKey.Type, answerKey.Grading)
+}
+
+// scoreWordCloud counts a typed phrase as an answer worth nothing, word clouds are not scored
+func scoreWordCloud(userAnswer structs.ReqAnswerSubmit) (sql.NullInt16, int) {
+	return sql.NullInt16{Valid: strings.TrimSpace(userAnswer.AnswerText) != ""}, 0
+}
+
+// scoreShortAnswer gives the full points to a text matching the rules of the question, and none otherwise
+func scoreShortAnswer(userAnswer structs.ReqAnswerSubmit, answerKey models.AnswerKey) (sql.NullInt16, int) {
+	points := sql.NullInt16{}
+	if strings.TrimSpace(userAnswer.AnswerText) == "" {
+		return points, 0
+	}
+	points.Valid = true
+
+	// the rules are checked when the question is saved, rules that still fail to load match nothing
+	rules := structs.ShortAnswerRules{}
+	if err := json.Unmarshal(answerKey.AnswerRules, &rules); err != nil {
+		return points, 0
+	}
+	matched, err := textmatch.Match(userAnswer.AnswerText, rules.Accepted, rules.Rules)
+	if err != nil || !matched {
+		return points, 0
+	}
+
+	var score int
+	points.Int16, score = creditedScore(1, answerKey.Points, answerKey.DurationInSeconds, userAnswer.ResponseTime)
+	return points, score
+}
+
+// scoreNumeric gives the points to a value within the tolerance of the question, and a share of them to
+// a value within the partial range
+func scoreNumeric(userAnswer structs.ReqAnswerSubmit, answerKey models.AnswerKey) (sql.NullInt16, int) {
+	points := sql.NullInt16{}
+	if userAnswer.AnswerValue == nil {
+		return points, 0
+	}
+	points.Valid = true
+
+	rules := structs.NumericRules{}
+	if err := json.Unmarshal(answerKey.AnswerRules, &rules); err != nil {
+		return points, 0
+	}
+
+	var score int
+	points.Int16, score = creditedScore(NumericCredit(*userAnswer.AnswerValue, rules), answerKey.Points, answerKey.DurationInSeconds, userAnswer.ResponseTime)
+	return points, score
+}
+
+// NumericCredit is the share of the points, from 0 to 1, a value earns. It falls linearly from 1 at
+// the edge of the tolerance to 0 at the edge of the partial range.
+func NumericCredit(value float64, rules structs.NumericRules) float64 {
+	tolerance := numericMargin(rules.Tolerance, rules)
+	distance := math.Abs(value - rules.Target)
+
+	// keep 0.1 + 0.2 within a tolerance of 0.3
+	if distance <= tolerance+1e-9*math.Max(1, math.Abs(rules.Target)) {
+		return 1
+	}
+
+	partialRange := numericMargin(rules.PartialRange, rules)
+	if partialRange <= tolerance || distance >= partialRange {
+		return 0
+	}
+	return (partialRange - distance) / (partialRange - tolerance)
+}
+
+// NumericRange is the lowest and the highest value earning the full points
+func NumericRange(rules structs.NumericRules) (float64, float64) {
+	tolerance := numericMargin(rules.Tolerance, rules)
+	return rules.Target - tolerance, rules.Target + tolerance
+}
+
+// numericMargin converts a tolerance or partial range into the unit of the answer
+func numericMargin(margin float64, rules structs.NumericRules) float64 {
+	if rules.ToleranceType == constants.TolerancePercent {
+		return math.Abs(rules.Target) * margin / 100
+	}
+	return margin
+}
+
+// scoreOrdering gives each option put in its right place a share of the points
+func scoreOrdering(userAnswer structs.ReqAnswerSubmit, answerKey models.AnswerKey) (sql.NullInt16, int) {
+	points := sql.NullInt16{}
+	if len(userAnswer.AnswerKeys) == 0 {
+		return points, 0
+	}
+	points.Valid = true
+
+	var score int
+	points.Int16, score = creditedScore(OrderingCredit(userAnswer.AnswerKeys, answerKey.Answers), answerKey.Points, answerKey.DurationInSeconds, userAnswer.ResponseTime)
+	return points, score
+}
+
+// OrderingCredit is the share of the options, from 0 to 1, put in their right place
+func OrderingCredit(order []int, rightOrder []int) float64 {
+	if len(rightOrder) == 0 {
+		return 0
+	}
+
+	correct := 0
+	for index := 0; index < min(len(order), len(rightOrder)); index++ {
+		if order[index] == rightOrder[index] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(rightOrder))
+}
+
+// scoreMatching gives each option paired with its right definition a share of the points
+func scoreMatching(userAnswer structs.ReqAnswerSubmit, answerKey models.AnswerKey) (sql.NullInt16, int) {
+	points := sql.NullInt16{}
+	if len(userAnswer.AnswerPairs) == 0 {
+		return points, 0
+	}
+	points.Valid = true
+
+	rules := structs.MatchingRules{}
+	if err := json.Unmarshal(answerKey.AnswerRules, &rules); err != nil {
+		return points, 0
+	}
+
+	var score int
+	points.Int16, score = creditedScore(MatchingCredit(userAnswer.AnswerPairs, rules.Pairs), answerKey.Points, answerKey.DurationInSeconds, userAnswer.ResponseTime)
+	return points, score
+}
+
+// MatchingCredit is the share of the options, from 0 to 1, paired with their right definition
+func MatchingCredit(pairs map[string]int, rightPairs map[string]int) float64 {
+	if len(rightPairs) == 0 {
+		return 0
+	}
+
+	correct := 0
+	for key, definitionKey := range rightPairs {
+		if picked, ok := pairs[key]; ok && picked == definitionKey {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(rightPairs))
+}
+
+// creditedScore is the points and score earned by an answer worth credit, from 0 to 1, of the question
+func creditedScore(credit float64, answerPoints int16, answerDurationInSeconds, responseTime int) (int16, int) {
+	if credit <= 0 || answerPoints <= 0 {
+		return 0, 0
+	}
+
+	remainingTime := (answerDurationInSeconds * 1000) - responseTime
+	remainingTimeFloat := math.Round(float64(remainingTime) / 1000)
+	timePoints := int(math.Round((remainingTimeFloat * 400) / float64(answerDurationInSeconds)))
+	score := int(math.Round(credit * float64(timePoints+500+int(answerPoints)*100)))
+
+	return int16(math.Round(float64(answerPoints) * credit)), score
+}
+
+func CalculatePointsAndScore(userAnswer structs.ReqAnswerSubmit, answers []int, answerPoints int16, answerDurationInSeconds, questionType int, grading string) (sql.NullInt16, int) {
 
 	var points sql.NullInt16 = sql.NullInt16{}
 	var remainingTime int
@@ -27,6 +202,13 @@ func CalculatePointsAndScore(userAnswer structs.ReqAnswerSubmit, answers []int,
 	}
 
 	points.Valid = true
+	// multiple answer questions may have a single correct answer too, so they are told apart by type
+	if questionType == constants.MultipleAnswer {
+		credit := MultipleAnswerCredit(userAnswer.AnswerKeys, answers, grading)
+		points.Int16, finalScore = creditedScore(credit, answerPoints, answerDurationInSeconds, userAnswer.ResponseTime)
+		return points, finalScore
+	}
+
 	// for mcq type question
 	if actualAnswerLen == 1 && answerPoints > 0 {
 		if answers[0] == userAnswer.AnswerKeys[0] {
@@ -47,25 +229,7 @@ func CalculatePointsAndScore(userAnswer structs.ReqAnswerSubmit, answers []int,
 		return points, finalScore
 	}
 
-	// logic for multiple correct answer to be used in future functionalities
-	// fetch options from db for that (make changes in questions.go )
-	// else if actualAnswerLen != len(options) {
-	// 	// if there are more than 1 correct answers
-	// 	for i := 0; i < actualAnswerLen; i++ {
-	// 		if answers[i] != userAnswer.AnswerKeys[0] {
-	// 			continue
-	// 		} else {
-	// 			// if answer selected by the user matches with any one of the correct answer (Partial evaluation)
-	// 			points.Int16 = answerPoints
-	// 			remainingTime = (answerDurationInSeconds * 1000) - userAnswer.ResponseTime
-	// 			remainingTimeFloat = math.Round(float64(remainingTime) / 1000)
-	// 			timePoints = int(math.Round((remainingTimeFloat * 400) / float64(answerDurationInSeconds)))
-	// 			finalScore = timePoints + basePoint + int(points.Int16*100)
-	// 			return points, finalScore, nil
-	// 		}
-	// 	}
-	// }
-
+	// single answer questions saved with several answers before multiple answer questions existed
 	var noOfMatches int = 0
 	for _, actualAnswer := range answers {
 		for _, userAnswer := range userAnswer.AnswerKeys {
@@ -82,6 +246,44 @@ func CalculatePointsAndScore(userAnswer structs.ReqAnswerSubmit, answers []int,
 	return points, finalScore
 }
 
+// MultipleAnswerCredit is the share of the points of a multiple answer question the picked options earn
+func MultipleAnswerCredit(picked []int, answers []int, grading string) float64 {
+	if len(answers) == 0 {
+		return 0
+	}
+
+	correct := map[int]bool{}
+	for _, answer := range answers {
+		correct[answer] = true
+	}
+
+	hits, misses := 0, 0
+	seen := map[int]bool{}
+	for _, key := range picked {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if correct[key] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	switch grading {
+	case constants.GradingProportional:
+		return float64(hits) / float64(len(correct))
+	case constants.GradingPenalty:
+		return math.Max(0, float64(hits-misses)) / float64(len(correct))
+	default:
+		if hits == len(correct) && misses == 0 {
+			return 1
+		}
+		return 0
+	}
+}
+
 func CalculateStreakScore(streakCount, score int) (int, int) {
 
 	// reset streak if score is 0
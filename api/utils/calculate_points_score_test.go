@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/models"
 	"github.com/Improwised/jovvix/api/pkg/structs"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,7 +19,7 @@ func TestCalculatePointsAndScore(t *testing.T) {
 		answerDurationInSeconds := 30
 		questionType := constants.SingleAnswer
 
-		points, score := CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType)
+		points, score := CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType, "")
 		assert.False(t, points.Valid)
 		assert.Equal(t, 0, score)
 	})
@@ -37,7 +38,7 @@ func TestCalculatePointsAndScore(t *testing.T) {
 		basePoint := 500
 		expectedScore := timePoints + basePoint + int(answerPoints*100)
 
-		points, score := CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType)
+		points, score := CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType, "")
 		assert.True(t, points.Valid)
 		assert.Equal(t, answerPoints, points.Int16)
 		assert.Equal(t, expectedScore, score)
@@ -50,7 +51,7 @@ func TestCalculatePointsAndScore(t *testing.T) {
 		answerDurationInSeconds := 30
 		questionType := constants.SingleAnswer
 
-		points, score := CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType)
+		points, score := CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType, "")
 		assert.True(t, points.Valid)
 		assert.Equal(t, 0, score)
 	})
@@ -68,11 +69,177 @@ func TestCalculatePointsAndScore(t *testing.T) {
 		basePoint := 500
 		expectedScore := timePoints + basePoint + int(answerPoints*100)
 
-		points, score := CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType)
+		points, score := CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType, "")
 		assert.True(t, points.Valid)
 		assert.Equal(t, answerPoints, points.Int16)
 		assert.Equal(t, expectedScore, score)
 	})
+
+	t.Run("Multiple answer question", func(t *testing.T) {
+		answers := []int{1, 3}
+		answerPoints := int16(2)
+		answerDurationInSeconds := 30
+		questionType := constants.MultipleAnswer
+
+		fullScore := func(responseTime int) int {
+			remainingTime := (answerDurationInSeconds * 1000) - responseTime
+			remainingTimeFloat := math.Round(float64(remainingTime) / 1000)
+			timePoints := int(math.Round((remainingTimeFloat * 400) / float64(answerDurationInSeconds)))
+			return timePoints + 500 + int(answerPoints)*100
+		}
+
+		// all the correct options, in any order
+		userAnswer := structs.ReqAnswerSubmit{AnswerKeys: []int{3, 1}, ResponseTime: 5000}
+		for _, grading := range []string{constants.GradingAllOrNothing, constants.GradingProportional, constants.GradingPenalty} {
+			points, score := CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType, grading)
+			assert.True(t, points.Valid)
+			assert.Equal(t, answerPoints, points.Int16, grading)
+			assert.Equal(t, fullScore(5000), score, grading)
+		}
+
+		// one correct option and a wrong one
+		userAnswer = structs.ReqAnswerSubmit{AnswerKeys: []int{1, 2}, ResponseTime: 5000}
+
+		points, score := CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType, constants.GradingAllOrNothing)
+		assert.True(t, points.Valid)
+		assert.Equal(t, int16(0), points.Int16)
+		assert.Equal(t, 0, score)
+
+		points, score = CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType, constants.GradingProportional)
+		assert.Equal(t, int16(1), points.Int16)
+		assert.Equal(t, int(math.Round(float64(fullScore(5000))/2)), score)
+
+		points, score = CalculatePointsAndScore(userAnswer, answers, answerPoints, answerDurationInSeconds, questionType, constants.GradingPenalty)
+		assert.Equal(t, int16(0), points.Int16)
+		assert.Equal(t, 0, score)
+
+		// a single correct answer is still scored as a multiple answer question
+		points, score = CalculatePointsAndScore(structs.ReqAnswerSubmit{AnswerKeys: []int{1, 2}, ResponseTime: 5000}, []int{1}, answerPoints, answerDurationInSeconds, questionType, constants.GradingAllOrNothing)
+		assert.True(t, points.Valid)
+		assert.Equal(t, 0, score)
+	})
+}
+
+func TestScoreAnswer(t *testing.T) {
+	answerKey := models.AnswerKey{
+		Points:            1,
+		DurationInSeconds: 30,
+		Type:              constants.ShortAnswer,
+		AnswerRules:       []byte(`{"accepted":["Mount Everest"],"max_distance":1}`),
+	}
+
+	t.Run("Empty text is not attempted", func(t *testing.T) {
+		points, score := ScoreAnswer(structs.ReqAnswerSubmit{AnswerText: "  "}, answerKey)
+		assert.False(t, points.Valid)
+		assert.Equal(t, 0, score)
+	})
+
+	t.Run("Matching text earns the points", func(t *testing.T) {
+		points, score := ScoreAnswer(structs.ReqAnswerSubmit{AnswerText: "mount  everest", ResponseTime: 0}, answerKey)
+		assert.True(t, points.Valid)
+		assert.Equal(t, int16(1), points.Int16)
+		assert.Equal(t, 1000, score)
+	})
+
+	t.Run("Typo within the distance is accepted", func(t *testing.T) {
+		points, _ := ScoreAnswer(structs.ReqAnswerSubmit{AnswerText: "Mount Everst"}, answerKey)
+		assert.Equal(t, int16(1), points.Int16)
+	})
+
+	t.Run("Numeric value within the partial range earns a share", func(t *testing.T) {
+		numericKey := models.AnswerKey{
+			Points:            2,
+			DurationInSeconds: 30,
+			Type:              constants.Numeric,
+			AnswerRules:       []byte(`{"target":100,"tolerance":10,"tolerance_type":"absolute","partial_range":30}`),
+		}
+
+		points, _ := ScoreAnswer(structs.ReqAnswerSubmit{}, numericKey)
+		assert.False(t, points.Valid)
+
+		value := 120.0
+		points, score := ScoreAnswer(structs.ReqAnswerSubmit{AnswerValue: &value}, numericKey)
+		assert.True(t, points.Valid)
+		assert.Equal(t, int16(1), points.Int16)
+		assert.Equal(t, 550, score)
+	})
+
+	t.Run("Matching pairs earn a share each", func(t *testing.T) {
+		matchingKey := models.AnswerKey{
+			Points:            4,
+			DurationInSeconds: 30,
+			Type:              constants.Matching,
+			AnswerRules:       []byte(`{"definitions":{"1":"datagram","2":"reliable"},"pairs":{"1":2,"2":1}}`),
+		}
+
+		points, _ := ScoreAnswer(structs.ReqAnswerSubmit{AnswerKeys: []int{}}, matchingKey)
+		assert.False(t, points.Valid)
+
+		points, score := ScoreAnswer(structs.ReqAnswerSubmit{AnswerPairs: map[string]int{"1": 2, "2": 2}}, matchingKey)
+		assert.True(t, points.Valid)
+		assert.Equal(t, int16(2), points.Int16)
+		assert.Equal(t, 650, score)
+	})
+
+	t.Run("Ordering scores the options in place", func(t *testing.T) {
+		orderingKey := models.AnswerKey{Answers: []int{2, 3, 1}, Points: 3, DurationInSeconds: 30, Type: constants.Ordering}
+
+		points, score := ScoreAnswer(structs.ReqAnswerSubmit{AnswerKeys: []int{2, 3, 1}}, orderingKey)
+		assert.Equal(t, int16(3), points.Int16)
+		assert.Equal(t, 1200, score)
+	})
+
+	t.Run("Word cloud answers are not scored", func(t *testing.T) {
+		cloudKey := models.AnswerKey{Points: 5, DurationInSeconds: 30, Type: constants.WordCloud}
+
+		points, _ := ScoreAnswer(structs.ReqAnswerSubmit{AnswerText: "  "}, cloudKey)
+		assert.False(t, points.Valid)
+
+		points, score := ScoreAnswer(structs.ReqAnswerSubmit{AnswerText: "blue sky"}, cloudKey)
+		assert.True(t, points.Valid)
+		assert.Equal(t, int16(0), points.Int16)
+		assert.Equal(t, 0, score)
+	})
+
+	t.Run("Wrong text is attempted without points", func(t *testing.T) {
+		points, score := ScoreAnswer(structs.ReqAnswerSubmit{AnswerText: "K2"}, answerKey)
+		assert.True(t, points.Valid)
+		assert.Equal(t, int16(0), points.Int16)
+		assert.Equal(t, 0, score)
+	})
+}
+
+func TestNumericCredit(t *testing.T) {
+	absolute := structs.NumericRules{Target: 9.81, Tolerance: 0.1, ToleranceType: constants.ToleranceAbsolute}
+	assert.Equal(t, 1.0, NumericCredit(9.9, absolute))
+	assert.Equal(t, 1.0, NumericCredit(9.71, absolute))
+	assert.Equal(t, 0.0, NumericCredit(10, absolute))
+
+	exact := structs.NumericRules{Target: 0.3}
+	assert.Equal(t, 1.0, NumericCredit(0.1+0.2, exact))
+
+	graded := structs.NumericRules{Target: 1000, Tolerance: 5, ToleranceType: constants.TolerancePercent, PartialRange: 25}
+	assert.Equal(t, 1.0, NumericCredit(950, graded))
+	assert.InDelta(t, 0.5, NumericCredit(1150, graded), 1e-9)
+	assert.Equal(t, 0.0, NumericCredit(750, graded))
+
+	low, high := NumericRange(graded)
+	assert.Equal(t, 950.0, low)
+	assert.Equal(t, 1050.0, high)
+}
+
+func TestOrderingCredit(t *testing.T) {
+	assert.Equal(t, 1.0, OrderingCredit([]int{3, 1, 2, 4}, []int{3, 1, 2, 4}))
+	assert.Equal(t, 0.5, OrderingCredit([]int{3, 1, 4, 2}, []int{3, 1, 2, 4}))
+	assert.Equal(t, 0.25, OrderingCredit([]int{3}, []int{3, 1, 2, 4}))
+	assert.Equal(t, 0.0, OrderingCredit([]int{1, 2}, nil))
+}
+
+func TestMatchingCredit(t *testing.T) {
+	rightPairs := map[string]int{"1": 2, "2": 3, "3": 1}
+	assert.Equal(t, 1.0, MatchingCredit(map[string]int{"1": 2, "2": 3, "3": 1}, rightPairs))
+	assert.InDelta(t, 1.0/3, MatchingCredit(map[string]int{"1": 2, "2": 1, "3": 3}, rightPairs), 1e-9)
+	assert.Equal(t, 0.0, MatchingCredit(map[string]int{"9": 2}, rightPairs))
 }
 
 func TestCalculateStreakScore(t *testing.T) {
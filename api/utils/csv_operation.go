@@ -2,15 +2,19 @@ package utils
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/Improwised/jovvix/api/constants"
 	quizUtilsHelper "github.com/Improwised/jovvix/api/helpers/utils"
 	"github.com/Improwised/jovvix/api/models"
+	"github.com/Improwised/jovvix/api/pkg/structs"
 	"github.com/google/uuid"
 	"github.com/jszwec/csvutil"
 )
@@ -28,6 +32,8 @@ type Question struct {
 	QuestionMedia string `csv:"Question Media"`
 	OptionsMedia  string `csv:"Options Media"`
 	Resource      string `csv:"Resource"`
+	Grading       string `csv:"Grading,omitempty"`
+	Unit          string `csv:"Unit,omitempty"`
 }
 
 func ValidateCSVFileFormat(fileName string) ([]Question, error) {
@@ -93,11 +99,18 @@ func ExtractQuestionsFromCSV(questions []Question, questionTimeLimit string) ([]
 			rowIssues = append(rowIssues, constants.ErrEmptyQuestionText)
 		}
 
-		// Question type must be a known type (single answer / survey).
+		// Question type must be one of the known types, the error lists them all.
 		questionType, typeErr := quizUtilsHelper.CheckQuestionType(strings.TrimSpace(u.Type))
 		if typeErr != nil {
-			rowIssues = append(rowIssues, fmt.Sprintf("%s (got %q, allowed: %s, %s)", constants.ErrQuestionType, u.Type, constants.SingleAnswerString, constants.SurveyString))
+			rowIssues = append(rowIssues, fmt.Sprintf("%s (got %q, allowed: %s)", constants.ErrQuestionType, u.Type, strings.Join([]string{constants.SingleAnswerString, constants.SurveyString, constants.MultipleAnswerString, constants.ShortAnswerString, constants.NumericString, constants.OrderingString, constants.MatchingString, constants.WordCloudString}, ", ")))
 		}
+		// Short answer and numeric questions have no options, the correct answer column holds what they accept.
+		isShortAnswer := typeErr == nil && questionType == constants.ShortAnswer
+		isNumeric := typeErr == nil && questionType == constants.Numeric
+		// Matching questions pair each option with the definition at its place in the correct answer column.
+		isMatching := typeErr == nil && questionType == constants.Matching
+		// Word clouds take whatever players type, they have neither options nor a correct answer.
+		isWordCloud := typeErr == nil && questionType == constants.WordCloud
 
 		// Collect non-empty options, preserving their option number.
 		options := make(map[string]string)
@@ -106,14 +119,38 @@ func ExtractQuestionsFromCSV(questions []Question, questionTimeLimit string) ([]
 				options[strconv.Itoa(idx+1)] = opt
 			}
 		}
-		if len(options) < 2 {
+		if isShortAnswer || isNumeric || isWordCloud {
+			options = map[string]string{}
+		} else if len(options) < 2 {
 			rowIssues = append(rowIssues, constants.ErrInsufficientOptions)
 		}
 
 		// Correct answer(s): must be present, numeric, and reference an existing option.
 		answers := []int{}
+		var answerRules []byte
 		correctRaw := strings.TrimSpace(u.CorrectAnswer)
-		if correctRaw == "" {
+		if isShortAnswer {
+			rules, rulesErr := quizUtilsHelper.CheckShortAnswer(&structs.ShortAnswerRules{Accepted: strings.Split(correctRaw, "|")})
+			if rulesErr != nil {
+				rowIssues = append(rowIssues, constants.ErrEmptyCorrectAnswer)
+			} else if answerRules, err = json.Marshal(rules); err != nil {
+				return nil, err
+			}
+		} else if isMatching {
+			rules, rulesErr := parseMatchingAnswer(options, correctRaw)
+			if rulesErr != nil {
+				rowIssues = append(rowIssues, rulesErr.Error())
+			} else if answerRules, err = json.Marshal(quizUtilsHelper.ScrambleDefinitions(rules)); err != nil {
+				return nil, err
+			}
+		} else if isNumeric {
+			rules, rulesErr := parseNumericAnswer(correctRaw, u.Unit)
+			if rulesErr != nil {
+				rowIssues = append(rowIssues, fmt.Sprintf("%s (got %q)", rulesErr.Error(), u.CorrectAnswer))
+			} else if answerRules, err = json.Marshal(rules); err != nil {
+				return nil, err
+			}
+		} else if correctRaw == "" && !isWordCloud {
 			rowIssues = append(rowIssues, constants.ErrEmptyCorrectAnswer)
 		} else {
 			for _, a := range strings.Split(correctRaw, "|") {
@@ -136,18 +173,17 @@ func ExtractQuestionsFromCSV(questions []Question, questionTimeLimit string) ([]
 
 		// Type-specific answer count rules (only meaningful when the type is valid).
 		if typeErr == nil {
-			switch questionType {
-			case constants.SingleAnswer:
-				if len(answers) != 1 {
-					rowIssues = append(rowIssues, constants.ErrSingleAnswerLength)
-				}
-			case constants.Survey:
-				if len(answers) < 1 {
-					rowIssues = append(rowIssues, constants.ErrSurveyAnswerLength)
-				}
+			if err := quizUtilsHelper.CheckAnswerCount(questionType, len(options), answers); err != nil {
+				rowIssues = append(rowIssues, err.Error())
 			}
 		}
 
+		// Grading: optional (default all or nothing), only multiple answer questions use it.
+		grading, gradingErr := quizUtilsHelper.CheckGrading(strings.ToLower(strings.TrimSpace(u.Grading)))
+		if gradingErr != nil {
+			rowIssues = append(rowIssues, fmt.Sprintf("%s (got %q)", constants.ErrGrading, u.Grading))
+		}
+
 		// Media types: optional (default text), but must be text, image, or code.
 		questionMedia, questionMediaOK := normalizeMedia(u.QuestionMedia)
 		if !questionMediaOK {
@@ -174,6 +210,16 @@ func ExtractQuestionsFromCSV(questions []Question, questionTimeLimit string) ([]
 			continue
 		}
 
+		// Ordering and matching earn a share of the points for each option in place, the options of an
+		// ordering question are stored out of their right order.
+		switch questionType {
+		case constants.Ordering:
+			options, answers = quizUtilsHelper.ScrambleOrder(options, answers)
+			grading = constants.GradingProportional
+		case constants.Matching:
+			grading = constants.GradingProportional
+		}
+
 		id, err := uuid.NewUUID()
 		if err != nil {
 			return validQuestions, err
@@ -191,6 +237,8 @@ func ExtractQuestionsFromCSV(questions []Question, questionTimeLimit string) ([]
 			QuestionMedia:     questionMedia,
 			OptionsMedia:      optionsMedia,
 			Resource:          sql.NullString{String: u.Resource, Valid: true},
+			Grading:           grading,
+			AnswerRules:       answerRules,
 		})
 	}
 
@@ -200,3 +248,70 @@ func ExtractQuestionsFromCSV(questions []Question, questionTimeLimit string) ([]
 
 	return validQuestions, nil
 }
+
+// parseNumericAnswer reads the correct answer of a numeric question written as target|tolerance|partial range.
+// The tolerance and the partial range are optional, ending them with % makes them a percent of the target.
+func parseNumericAnswer(correctAnswer string, unit string) (structs.NumericRules, error) {
+	parts := strings.Split(correctAnswer, "|")
+	if len(parts) > 3 {
+		return structs.NumericRules{}, errors.New(constants.ErrNumericAnswer)
+	}
+
+	values := make([]float64, 3)
+	percents := make([]bool, 3)
+	for index, part := range parts {
+		part = strings.TrimSpace(part)
+		if index > 0 && part == "" {
+			continue
+		}
+
+		percents[index] = strings.HasSuffix(part, "%")
+		value, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(part, "%")), 64)
+		if err != nil || (index == 0 && percents[index]) {
+			return structs.NumericRules{}, errors.New(constants.ErrNumericAnswer)
+		}
+		values[index] = value
+	}
+
+	// the partial range is read like the tolerance
+	percent := percents[1] || (values[1] == 0 && percents[2])
+	if values[2] != 0 && percents[2] != percent {
+		return structs.NumericRules{}, errors.New(constants.ErrNumericAnswer)
+	}
+
+	rules := structs.NumericRules{
+		Target:        values[0],
+		Tolerance:     values[1],
+		ToleranceType: constants.ToleranceAbsolute,
+		PartialRange:  values[2],
+		Unit:          unit,
+	}
+	if percent {
+		rules.ToleranceType = constants.TolerancePercent
+	}
+
+	return quizUtilsHelper.CheckNumeric(&rules)
+}
+
+// parseMatchingAnswer pairs the options of a matching question, in the order of their columns, with the
+// definitions of the correct answer written as definition|definition|...
+func parseMatchingAnswer(options map[string]string, correctAnswer string) (structs.MatchingRules, error) {
+	definitions := strings.Split(correctAnswer, "|")
+	if len(definitions) != len(options) {
+		return structs.MatchingRules{}, errors.New(constants.ErrMatchingRules)
+	}
+
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rules := structs.MatchingRules{Definitions: map[string]string{}, Pairs: map[string]int{}}
+	for index, key := range keys {
+		rules.Definitions[strconv.Itoa(index+1)] = definitions[index]
+		rules.Pairs[key] = index + 1
+	}
+
+	return quizUtilsHelper.CheckMatching(options, &rules)
+}
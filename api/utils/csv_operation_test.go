@@ -1,10 +1,13 @@
 package utils
 
 import (
+	"encoding/json"
 	"os"
+	"strconv"
 	"testing"
 
 	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/structs"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -256,6 +259,178 @@ func TestExtractQuestionsFromCSV(t *testing.T) {
 		assert.Contains(t, err.Error(), constants.ErrSingleAnswerLength)
 	})
 
+	t.Run("Multiple answer with grading", func(t *testing.T) {
+		questions := []Question{
+			{
+				Question:      "Pick the primes",
+				Type:          "multiple answer",
+				Option1:       "2",
+				Option2:       "3",
+				Option3:       "4",
+				CorrectAnswer: "1|2",
+				Grading:       "Proportional_Penalty",
+			},
+			{
+				Question:      "Pick the evens",
+				Type:          "multiple answer",
+				Option1:       "2",
+				Option2:       "3",
+				CorrectAnswer: "1",
+			},
+		}
+
+		validQuestions, err := ExtractQuestionsFromCSV(questions, "30")
+		assert.NoError(t, err)
+		assert.Len(t, validQuestions, 2)
+		assert.Equal(t, constants.MultipleAnswer, validQuestions[0].Type)
+		assert.Equal(t, []int{1, 2}, validQuestions[0].Answers)
+		assert.Equal(t, constants.GradingPenalty, validQuestions[0].Grading)
+		assert.Equal(t, constants.GradingAllOrNothing, validQuestions[1].Grading)
+	})
+
+	t.Run("Multiple answer needs a wrong option and a known grading", func(t *testing.T) {
+		questions := []Question{
+			{
+				Question:      "Pick all",
+				Type:          "multiple answer",
+				Option1:       "A",
+				Option2:       "B",
+				CorrectAnswer: "1|2",
+				Grading:       "half",
+			},
+		}
+
+		validQuestions, err := ExtractQuestionsFromCSV(questions, "30")
+		assert.Error(t, err)
+		assert.Empty(t, validQuestions)
+		assert.Contains(t, err.Error(), constants.ErrMultipleAnswerLength)
+		assert.Contains(t, err.Error(), constants.ErrGrading)
+	})
+
+	t.Run("Short answer takes the accepted answers without options", func(t *testing.T) {
+		questions := []Question{
+			{
+				Question:      "Highest mountain",
+				Type:          "short answer",
+				CorrectAnswer: "Everest | Mount Everest",
+			},
+			{
+				Question:      "Capital of France",
+				Type:          "short answer",
+				CorrectAnswer: " | ",
+			},
+		}
+
+		validQuestions, err := ExtractQuestionsFromCSV(questions, "30")
+		assert.Error(t, err)
+		assert.Empty(t, validQuestions)
+		assert.Contains(t, err.Error(), "row 3: "+constants.ErrEmptyCorrectAnswer)
+		assert.NotContains(t, err.Error(), "row 2")
+
+		validQuestions, err = ExtractQuestionsFromCSV(questions[:1], "30")
+		assert.NoError(t, err)
+		assert.Equal(t, constants.ShortAnswer, validQuestions[0].Type)
+		assert.Empty(t, validQuestions[0].Options)
+		assert.Empty(t, validQuestions[0].Answers)
+		assert.JSONEq(t, `{"accepted":["Everest","Mount Everest"],"case_sensitive":false,"keep_accents":false,"max_distance":0}`, string(validQuestions[0].AnswerRules))
+	})
+
+	t.Run("Numeric reads the target, tolerance and partial range", func(t *testing.T) {
+		questions := []Question{
+			{Question: "Speed of sound", Type: "numeric", CorrectAnswer: "343|5%|20%", Unit: "m/s"},
+			{Question: "Gravity", Type: "numeric", CorrectAnswer: "9.81 | 0.1"},
+			{Question: "Boiling point", Type: "numeric", CorrectAnswer: "100"},
+		}
+
+		validQuestions, err := ExtractQuestionsFromCSV(questions, "30")
+		assert.NoError(t, err)
+		assert.Len(t, validQuestions, 3)
+		assert.Equal(t, constants.Numeric, validQuestions[0].Type)
+		assert.Empty(t, validQuestions[0].Options)
+		assert.Empty(t, validQuestions[0].Answers)
+		assert.JSONEq(t, `{"target":343,"tolerance":5,"tolerance_type":"percent","partial_range":20,"unit":"m/s"}`, string(validQuestions[0].AnswerRules))
+		assert.JSONEq(t, `{"target":9.81,"tolerance":0.1,"tolerance_type":"absolute"}`, string(validQuestions[1].AnswerRules))
+		assert.JSONEq(t, `{"target":100,"tolerance":0,"tolerance_type":"absolute"}`, string(validQuestions[2].AnswerRules))
+	})
+
+	t.Run("Numeric rejects a malformed correct answer", func(t *testing.T) {
+		questions := []Question{
+			{Question: "a", Type: "numeric", CorrectAnswer: "ten"},
+			{Question: "b", Type: "numeric", CorrectAnswer: "10|5|20%"},
+			{Question: "c", Type: "numeric", CorrectAnswer: "10|5|2"},
+			{Question: "d", Type: "numeric", CorrectAnswer: "10|1|2|3"},
+		}
+
+		validQuestions, err := ExtractQuestionsFromCSV(questions, "30")
+		assert.Error(t, err)
+		assert.Nil(t, validQuestions)
+		assert.Contains(t, err.Error(), "row 2: "+constants.ErrNumericAnswer)
+		assert.Contains(t, err.Error(), "row 3: "+constants.ErrNumericAnswer)
+		assert.Contains(t, err.Error(), "row 4: "+constants.ErrNumericRules)
+		assert.Contains(t, err.Error(), "row 5: "+constants.ErrNumericAnswer)
+	})
+
+	t.Run("Ordering keeps the right order in the answers", func(t *testing.T) {
+		questions := []Question{
+			{Question: "Order the steps", Type: "ordering", Option1: "plan", Option2: "build", Option3: "test", CorrectAnswer: "1|2|3"},
+			{Question: "Order the steps", Type: "ordering", Option1: "plan", Option2: "build", Option3: "test", CorrectAnswer: "1|2"},
+		}
+
+		_, err := ExtractQuestionsFromCSV(questions, "30")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "row 3: "+constants.ErrOrderingAnswer)
+		assert.NotContains(t, err.Error(), "row 2")
+
+		validQuestions, err := ExtractQuestionsFromCSV(questions[:1], "30")
+		assert.NoError(t, err)
+		question := validQuestions[0]
+		assert.Equal(t, constants.Ordering, question.Type)
+		assert.Equal(t, constants.GradingProportional, question.Grading)
+		assert.NotEqual(t, []int{1, 2, 3}, question.Answers)
+
+		inOrder := []string{}
+		for _, key := range question.Answers {
+			inOrder = append(inOrder, question.Options[strconv.Itoa(key)])
+		}
+		assert.Equal(t, []string{"plan", "build", "test"}, inOrder)
+	})
+
+	t.Run("Matching pairs the options with the definitions in their place", func(t *testing.T) {
+		questions := []Question{
+			{Question: "Match the protocols", Type: "matching", Option1: "TCP", Option2: "UDP", CorrectAnswer: "reliable|datagram"},
+			{Question: "Match the protocols", Type: "matching", Option1: "TCP", Option2: "UDP", CorrectAnswer: "reliable"},
+		}
+
+		_, err := ExtractQuestionsFromCSV(questions, "30")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "row 3: "+constants.ErrMatchingRules)
+
+		validQuestions, err := ExtractQuestionsFromCSV(questions[:1], "30")
+		assert.NoError(t, err)
+		question := validQuestions[0]
+		assert.Equal(t, constants.Matching, question.Type)
+		assert.Empty(t, question.Answers)
+
+		rules := structs.MatchingRules{}
+		assert.NoError(t, json.Unmarshal(question.AnswerRules, &rules))
+		assert.Equal(t, "reliable", rules.Definitions[strconv.Itoa(rules.Pairs["1"])])
+		assert.Equal(t, "datagram", rules.Definitions[strconv.Itoa(rules.Pairs["2"])])
+	})
+
+	t.Run("Word clouds need neither options nor a correct answer", func(t *testing.T) {
+		questions := []Question{
+			{Question: "One word for this sprint", Type: "word cloud", Option1: "ignored"},
+		}
+
+		validQuestions, err := ExtractQuestionsFromCSV(questions, "30")
+		assert.NoError(t, err)
+		question := validQuestions[0]
+		assert.Equal(t, constants.WordCloud, question.Type)
+		assert.Empty(t, question.Options)
+		assert.Empty(t, question.Answers)
+		assert.Nil(t, question.AnswerRules)
+	})
+
 	t.Run("Empty fields are rejected", func(t *testing.T) {
 		questions := []Question{
 			{
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"clevergo.tech/jsend"
+	"github.com/Improwised/jovvix/api/constants"
+	"github.com/Improwised/jovvix/api/pkg/protocol"
 	"github.com/Improwised/jovvix/api/pkg/structs"
 	"github.com/gofiber/contrib/websocket"
 	fiber "github.com/gofiber/fiber/v2"
@@ -29,7 +31,7 @@ func JSONSuccessWs(c *websocket.Conn, eventName string, data interface{}) error
 	if c == nil {
 		return nil
 	} else {
-		return c.WriteJSON(jsend.New(structs.SocketResponseFormat{EventName: eventName, Data: data}))
+		return c.WriteJSON(jsend.New(structs.SocketResponseFormat{EventName: wsEventName(c, eventName), Data: data}))
 	}
 }
 
@@ -39,7 +41,7 @@ func JSONFailWs(c *websocket.Conn, eventName string, data interface{}) error {
 	if c == nil {
 		return nil
 	} else {
-		return c.WriteJSON(jsend.NewFail(structs.SocketResponseFormat{EventName: eventName, Data: data}))
+		return c.WriteJSON(jsend.NewFail(structs.SocketResponseFormat{EventName: wsEventName(c, eventName), Data: data}))
 	}
 }
 
@@ -49,7 +51,17 @@ func JSONErrorWs(c *websocket.Conn, eventName string, data interface{}) error {
 	if c == nil {
 		return nil
 	} else {
-		return c.WriteJSON(jsend.NewError("Error", -1, structs.SocketResponseFormat{EventName: eventName, Data: data}))
+		return c.WriteJSON(jsend.NewError("Error", -1, structs.SocketResponseFormat{EventName: wsEventName(c, eventName), Data: data}))
 	}
 
 }
+
+// wsEventName is how eventName is called in the protocol version the socket negotiated
+func wsEventName(c *websocket.Conn, eventName string) string {
+	version, ok := c.Locals(constants.ProtocolVersion).(int)
+	if !ok {
+		return eventName
+	}
+
+	return protocol.EventName(version, eventName)
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"cmp"
+	"slices"
+)
+
+// DenseRanks ranks scores from the highest, equal scores share a rank and the next score gets the
+// following one, like DENSE_RANK in postgres. The ranks are in the order of scores.
+func DenseRanks(scores []int64) []int {
+	distinct := slices.Clone(scores)
+	slices.SortFunc(distinct, func(a, b int64) int {
+		return cmp.Compare(b, a)
+	})
+	distinct = slices.Compact(distinct)
+
+	ranks := make([]int, len(scores))
+	for i, score := range scores {
+		index, _ := slices.BinarySearchFunc(distinct, score, func(a, b int64) int {
+			return cmp.Compare(b, a)
+		})
+		ranks[i] = index + 1
+	}
+	return ranks
+}
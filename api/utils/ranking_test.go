@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDenseRanks(t *testing.T) {
+	t.Run("Highest score is ranked first", func(t *testing.T) {
+		assert.Equal(t, []int{2, 1, 3}, DenseRanks([]int64{500, 900, 0}))
+	})
+
+	t.Run("Equal scores share a rank without a gap after them", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 1, 3}, DenseRanks([]int64{800, 300, 800, 100}))
+	})
+
+	t.Run("No scores", func(t *testing.T) {
+		assert.Empty(t, DenseRanks(nil))
+	})
+}
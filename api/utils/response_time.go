@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"time"
+)
+
+// ResponseTiming is the response time an answer is scored with, next to what the player reported
+type ResponseTiming struct {
+	ResponseTime       int
+	ServerResponseTime int
+	ClientResponseTime int
+	IsAnomaly          bool
+}
+
+// ReconcileResponseTime measures the response time of an answer on the server, from the delivery of the
+// question to the receipt of the answer. The server time also holds the network latency, so the time of the
+// player is trusted as long as it is at most tolerance shorter than the server time. A player reporting a
+// time further than threshold away from the server time is flagged. All times are in milliseconds.
+func ReconcileResponseTime(clientResponseTime int, deliveredAt, receivedAt time.Time, durationInSeconds int, tolerance, threshold time.Duration) ResponseTiming {
+	serverResponseTime := int(receivedAt.Sub(deliveredAt).Milliseconds())
+	serverResponseTime = min(max(serverResponseTime, 0), durationInSeconds*1000)
+
+	fastest := max(serverResponseTime-int(tolerance.Milliseconds()), 0)
+	responseTime := min(max(clientResponseTime, fastest), serverResponseTime)
+
+	drift := clientResponseTime - serverResponseTime
+	if drift < 0 {
+		drift = -drift
+	}
+
+	return ResponseTiming{
+		ResponseTime:       responseTime,
+		ServerResponseTime: serverResponseTime,
+		ClientResponseTime: clientResponseTime,
+		IsAnomaly:          drift > int(threshold.Milliseconds()),
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileResponseTime(t *testing.T) {
+	deliveredAt := time.Date(2026, 10, 4, 10, 0, 0, 0, time.UTC)
+	tolerance := 1500 * time.Millisecond
+	threshold := 3000 * time.Millisecond
+
+	t.Run("Client time within tolerance is trusted", func(t *testing.T) {
+		timing := ReconcileResponseTime(4200, deliveredAt, deliveredAt.Add(5*time.Second), 30, tolerance, threshold)
+		assert.Equal(t, 4200, timing.ResponseTime)
+		assert.Equal(t, 5000, timing.ServerResponseTime)
+		assert.False(t, timing.IsAnomaly)
+	})
+
+	t.Run("Client time too short is raised to the tolerance", func(t *testing.T) {
+		timing := ReconcileResponseTime(1, deliveredAt, deliveredAt.Add(10*time.Second), 30, tolerance, threshold)
+		assert.Equal(t, 8500, timing.ResponseTime)
+		assert.Equal(t, 1, timing.ClientResponseTime)
+		assert.True(t, timing.IsAnomaly)
+	})
+
+	t.Run("Client time longer than the server time is capped", func(t *testing.T) {
+		timing := ReconcileResponseTime(6000, deliveredAt, deliveredAt.Add(5*time.Second), 30, tolerance, threshold)
+		assert.Equal(t, 5000, timing.ResponseTime)
+		assert.False(t, timing.IsAnomaly)
+	})
+
+	t.Run("Server time is capped at the question duration", func(t *testing.T) {
+		timing := ReconcileResponseTime(9000, deliveredAt, deliveredAt.Add(12*time.Second), 10, tolerance, threshold)
+		assert.Equal(t, 10000, timing.ServerResponseTime)
+		assert.Equal(t, 9000, timing.ResponseTime)
+		assert.False(t, timing.IsAnomaly)
+	})
+
+	t.Run("Answer received before delivery counts as instant", func(t *testing.T) {
+		timing := ReconcileResponseTime(500, deliveredAt, deliveredAt.Add(-time.Second), 30, tolerance, threshold)
+		assert.Equal(t, 0, timing.ServerResponseTime)
+		assert.Equal(t, 0, timing.ResponseTime)
+		assert.False(t, timing.IsAnomaly)
+	})
+}
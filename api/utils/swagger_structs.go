@@ -84,7 +84,7 @@ type RequestUpadateRegisteredUser struct {
 	}
 }
 
-// swagger:parameters RequestFinalScoreForAdmin
+// swagger:parameters RequestFinalScoreForAdmin RequestFinalTeamScoreForAdmin
 type RequestFinalScoreForAdmin struct {
 	// in:query
 	// required: true
@@ -102,7 +102,7 @@ type ResponseFinalScoreForAdmin struct {
 	} `json:"body"`
 }
 
-// swagger:parameters RequestFinalScoreForUser
+// swagger:parameters RequestFinalScoreForUser RequestFinalTeamScoreForUser
 type RequestFinalScoreForUser struct {
 	// in:query
 	// required: true
@@ -237,6 +237,228 @@ type ResponseGenerateDemoSession struct {
 	} `json:"body"`
 }
 
+// swagger:parameters RequestGenerateAssignmentSession
+type RequestGenerateAssignmentSession struct {
+	// in:path
+	// required: true
+	QuizId string `json:"quiz_id"`
+
+	// in:body
+	// required: true
+	Body struct {
+		structs.ReqCreateAssignment
+	}
+}
+
+// swagger:response ResponseGenerateAssignmentSession
+type ResponseGenerateAssignmentSession struct {
+	// in:body
+	Body struct {
+		Status string `json:"status"`
+		Data   struct {
+			models.ActiveSessionSummary
+		} `json:"data"`
+	} `json:"body"`
+}
+
+// swagger:parameters RequestAssignmentQuestion
+type RequestAssignmentQuestion struct {
+	// in:path
+	// required: true
+	UserPlayedQuizId string `json:"user_played_quiz_id"`
+}
+
+// swagger:response ResponseAssignmentQuestion
+type ResponseAssignmentQuestion struct {
+	// in:body
+	Body struct {
+		Status string `json:"status"`
+		Data   struct {
+			Completed      bool              `json:"completed"`
+			Id             string            `json:"id"`
+			No             int               `json:"no"`
+			Duration       int               `json:"duration"`
+			StartTime      string            `json:"start_time"`
+			ServerTime     string            `json:"server_time"`
+			ClosesAt       string            `json:"closes_at"`
+			Question       string            `json:"question"`
+			Type           int               `json:"type"`
+			Options        map[string]string `json:"options"`
+			TotalQuestions int               `json:"totalQuestions"`
+			QuestionMedia  string            `json:"question_media"`
+			OptionsMedia   string            `json:"options_media"`
+			Resource       string            `json:"resource"`
+		} `json:"data"`
+	} `json:"body"`
+}
+
+// swagger:parameters RequestAssignmentAnswer
+type RequestAssignmentAnswer struct {
+	// in:path
+	// required: true
+	UserPlayedQuizId string `json:"user_played_quiz_id"`
+
+	// in:body
+	// required: true
+	Body struct {
+		structs.ReqAnswerSubmit
+	}
+}
+
+// swagger:response ResponseAssignmentAnswer
+type ResponseAssignmentAnswer struct {
+	// in:body
+	Body struct {
+		Status string `json:"status"`
+		Data   struct {
+			Score        int `json:"score"`
+			Streak       int `json:"streak"`
+			ResponseTime int `json:"response_time"`
+		} `json:"data"`
+	} `json:"body"`
+}
+
+// swagger:parameters RequestSessionShuffle
+type RequestSessionShuffle struct {
+	// in:path
+	// required: true
+	SessionId string `json:"session_id"`
+
+	// in:body
+	// required: true
+	Body struct {
+		structs.ReqSessionShuffle
+	}
+}
+
+// swagger:parameters RequestJoinLink
+type RequestJoinLink struct {
+	// in:path
+	// required: true
+	SessionId string `json:"session_id"`
+
+	// passcode of the session, to let the players who follow the link skip it
+	// in:query
+	Passcode string `json:"passcode"`
+}
+
+// swagger:parameters RequestJoinLinkQR
+type RequestJoinLinkQR struct {
+	// in:path
+	// required: true
+	SessionId string `json:"session_id"`
+
+	// passcode of the session, to let the players who follow the link skip it
+	// in:query
+	Passcode string `json:"passcode"`
+
+	// png or svg, png when not set
+	// in:query
+	Format string `json:"format"`
+
+	// pixels per module of a png, 8 when not set
+	// in:query
+	Scale int `json:"scale"`
+}
+
+// swagger:parameters RequestOpenJoinLink
+type RequestOpenJoinLink struct {
+	// in:path
+	// required: true
+	Token string `json:"token"`
+}
+
+// swagger:response ResponseJoinLink
+type ResponseJoinLink struct {
+	// in:body
+	Body struct {
+		Status string              `json:"status"`
+		Data   structs.ResJoinLink `json:"data"`
+	} `json:"body"`
+}
+
+// swagger:parameters RequestSessionAccess
+type RequestSessionAccess struct {
+	// in:path
+	// required: true
+	SessionId string `json:"session_id"`
+
+	// in:body
+	// required: true
+	Body struct {
+		structs.ReqSessionAccess
+	}
+}
+
+// swagger:parameters RequestSessionJoinPolicy
+type RequestSessionJoinPolicy struct {
+	// in:path
+	// required: true
+	SessionId string `json:"session_id"`
+
+	// in:body
+	// required: true
+	Body struct {
+		structs.ReqSessionJoinPolicy
+	}
+}
+
+// swagger:parameters RequestConfigureTeams
+type RequestConfigureTeams struct {
+	// in:path
+	// required: true
+	SessionId string `json:"session_id"`
+
+	// in:body
+	// required: true
+	Body struct {
+		structs.ReqSessionTeams
+	}
+}
+
+// swagger:parameters RequestDisableTeams RequestListTeams
+type RequestSessionTeams struct {
+	// in:path
+	// required: true
+	SessionId string `json:"session_id"`
+}
+
+// swagger:response ResponseListTeams
+type ResponseListTeams struct {
+	// in:body
+	Body struct {
+		Status string `json:"status"`
+		Data   struct {
+			Mode    string              `json:"mode"`
+			Scoring string              `json:"scoring"`
+			BestN   int                 `json:"best_n"`
+			Teams   []models.TeamRoster `json:"teams"`
+		} `json:"data"`
+	} `json:"body"`
+}
+
+// swagger:parameters RequestChooseTeam
+type RequestChooseTeam struct {
+	// in:path
+	// required: true
+	SessionId string `json:"session_id"`
+
+	// in:body
+	// required: true
+	Body struct {
+		structs.ReqChooseTeam
+	}
+}
+
+// swagger:response ResponseFinalTeamScore
+type ResponseFinalTeamScore struct {
+	// in:body
+	Body struct {
+		Status string            `json:"status"`
+		Data   []models.TeamRank `json:"data"`
+	} `json:"body"`
+}
+
 // swagger:parameters RequestListQuestionByQuizId
 type RequestListQuestionByQuizId struct {
 	// in:path
@@ -297,6 +519,11 @@ type RequestPlayedQuizValidation struct {
 	// in:path
 	// required: true
 	InvitationCode string `json:"invitationCode"`
+
+	// in:body
+	Body struct {
+		structs.ReqPlayedQuizValidation
+	}
 }
 
 // swagger:response ResponsePlayedQuizValidation
@@ -307,6 +534,8 @@ type ResponsePlayedQuizValidation struct {
 		Data   struct {
 			UserPlayedQuizId string `json:"user_played_quiz"`
 			SessionId        string `json:"session_id"`
+			QuizTitle        string `json:"quiz_title"`
+			Mode             string `json:"mode"`
 		} `json:"data"`
 	} `json:"body"`
 }
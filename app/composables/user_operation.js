@@ -13,11 +13,16 @@ export default class UserOperation extends QuizHandler {
     sessionUnavailableHandler
   ) {
     const url = useRuntimeConfig().public;
+    const socketUrl = url.apiSocketUrl + "/join/" + code + "?username=" + username;
+    // a token from an earlier socket of this tab lets the server restore our state
+    const rejoinToken = sessionStorage.getItem(constants.RejoinToken + "_" + code);
     super(
-      url.apiSocketUrl + "/join/" + code + "?username=" + username,
+      rejoinToken ? socketUrl + "&rejoin_token=" + rejoinToken : socketUrl,
       code,
       handler
     );
+    this.baseSocketUrl = socketUrl;
+    this.playerState = null;
     this.errorHandler = errorHandler;
     this.successHandler = successHandler;
     // Fires when the socket opens but is closed by the server before we ever
@@ -130,6 +135,20 @@ export default class UserOperation extends QuizHandler {
   onMessage(event) {
     const message = this.destructureMessage(event);
 
+    if (message.event === constants.RejoinToken) {
+      sessionStorage.setItem(
+        constants.RejoinToken + "_" + this.identifier,
+        message.data.token
+      );
+      this.socket_url =
+        this.baseSocketUrl + "&rejoin_token=" + message.data.token;
+      return;
+    } else if (message.event === constants.PlayerState) {
+      // followed by the regular event of the phase, which renders the screen
+      this.playerState = message.data;
+      return;
+    }
+
     if (message.component === constants.Waiting) {
       this.isWaiting = true;
     } else if (this.isWaiting && message.component === constants.Question) {
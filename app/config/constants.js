@@ -48,6 +48,8 @@ export default {
   ResumeQuiz: "resume_quiz",
   ResumeQuizMessage: "quiz is resumed, you could play now",
   HostEndedQuizMessage: "The host has ended the quiz.",
+  RejoinToken: "rejoin_token",
+  PlayerState: "player_state",
 
   // Actions
   ActionAnserSubmittedByUser: "answer submitted by user",